@@ -14,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/csrf"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/template/html/v2"
 	"github.com/jmoiron/sqlx"
@@ -21,9 +22,12 @@ import (
 
 	"github.com/rizkysr90/aslam-flower/internal/config"
 	"github.com/rizkysr90/aslam-flower/internal/handlers"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
 	"github.com/rizkysr90/aslam-flower/internal/middleware"
+	"github.com/rizkysr90/aslam-flower/internal/models"
 	"github.com/rizkysr90/aslam-flower/internal/repositories"
 	"github.com/rizkysr90/aslam-flower/internal/services"
+	"github.com/rizkysr90/aslam-flower/internal/utils"
 )
 
 func main() {
@@ -64,23 +68,84 @@ func main() {
 	productRepo := repositories.NewProductRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
 	adminRepo := repositories.NewAdminRepository(db)
+	inventoryRepo := repositories.NewInventoryRepository(db)
+	promotionRepo := repositories.NewPromotionRepository(db)
+	colorRepo := repositories.NewColorRepository(db)
+	basketRepo := repositories.NewBasketRepository(db)
+	inquiryRepo := repositories.NewInquiryRepository(db)
+	orderRepo := repositories.NewOrderRepository(db)
+	documentRepo := repositories.NewDocumentRepository(db)
+	customerRepo := repositories.NewCustomerRepository(db)
+	customerGroupRepo := repositories.NewCustomerGroupRepository(db)
+	visitorRepo := repositories.NewVisitorRepository(db)
+	wishlistRepo := repositories.NewWishlistRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db)
+	settingsRepo := repositories.NewSettingsRepository(db)
+
+	// Store settings live in the database; the environment values only seed them on first boot
+	settingsService := services.NewSettingsService(settingsRepo, db, models.StoreSettings{
+		Name:           cfg.StoreName,
+		Address:        cfg.StoreAddress,
+		WhatsAppNumber: cfg.WhatsAppNumber,
+		ShopeeLink:     cfg.ShopeeLink,
+		TiktokLink:     cfg.TiktokLink,
+		InstagramLink:  cfg.InstagramLink,
+	})
+	if err := settingsService.Seed(); err != nil {
+		log.Fatalf("Failed to seed store settings: %v", err)
+	}
 
 	// Initialize services
-	productService := services.NewProductService(productRepo, cloudinaryService, db)
-	categoryService := services.NewCategoryService(categoryRepo)
+	productService := services.NewProductService(productRepo, promotionRepo, colorRepo, customerGroupRepo, cloudinaryService, db)
+	categoryService := services.NewCategoryService(categoryRepo, productService, cloudinaryService, db)
 	authService := services.NewAuthService(adminRepo, cfg.JWTSecret)
+	inventoryService := services.NewInventoryService(inventoryRepo, productRepo, db)
+	promotionService := services.NewPromotionService(promotionRepo, productService, db)
+	colorService := services.NewColorService(colorRepo, db)
+	basketService := services.NewBasketService(basketRepo, productService, db, settingsService)
+	inquiryService := services.NewInquiryService(inquiryRepo, productService, db, settingsService)
+	orderService := services.NewOrderService(orderRepo, productService, db)
+	documentService := services.NewDocumentService(documentRepo, productService, db, settingsService)
+	customerService := services.NewCustomerService(customerRepo, customerGroupRepo, authService, cfg.JWTSecret)
+	customerGroupService := services.NewCustomerGroupService(customerGroupRepo, productService, db)
+	wishlistService := services.NewWishlistService(visitorRepo, wishlistRepo, productService, db, settingsService)
+	reviewService := services.NewReviewService(reviewRepo, cloudinaryService, db)
+	sitemapService := services.NewSitemapService(productRepo, categoryRepo)
+	feedService := services.NewFeedService(productRepo, categoryRepo, productService, settingsService)
 
 	// Initialize handlers
-	publicHandler := handlers.NewPublicHandler(productService, categoryService, cfg.WhatsAppNumber, cfg.StoreName, cfg.StoreAddress, cfg.ShopeeLink, cfg.TiktokLink, cfg.InstagramLink)
-	adminHandler := handlers.NewAdminHandler(productService, categoryService, cloudinaryService)
-	categoryHandler := handlers.NewCategoryHandler(categoryService, categoryRepo)
+	publicHandler := handlers.NewPublicHandler(productService, categoryService, wishlistService, reviewService, cfg.BaseURL)
+	adminHandler := handlers.NewAdminHandler(productService, categoryService, colorService, inquiryService, cloudinaryService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService, categoryRepo, cloudinaryService)
 	authHandler := handlers.NewAuthHandler(authService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService, categoryService)
+	colorHandler := handlers.NewColorHandler(colorService)
+	basketHandler := handlers.NewBasketHandler(basketService, inquiryService, cfg.Env)
+	inquiryHandler := handlers.NewInquiryHandler(inquiryService)
+	orderHandler := handlers.NewOrderHandler(orderService, productService)
+	documentHandler := handlers.NewDocumentHandler(documentService)
+	customerHandler := handlers.NewCustomerHandler(customerService, customerGroupService, wishlistService)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistService, cfg.BaseURL, cfg.Env)
+	customerGroupHandler := handlers.NewCustomerGroupHandler(customerGroupService)
+	reviewHandler := handlers.NewReviewHandler(reviewService, productService, cloudinaryService)
+	seoHandler := handlers.NewSEOHandler(sitemapService, cfg.BaseURL, cfg.RobotsDisallow, cfg.RobotsNoIndex)
+	feedHandler := handlers.NewFeedHandler(feedService, cfg.BaseURL, cfg.FeedToken)
+	settingsHandler := handlers.NewSettingsHandler(settingsService)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: customErrorHandler,
 		Views:        initTemplateEngine(cfg.Env),
 		BodyLimit:    10 * 1024 * 1024, // 10MB for file uploads
+
+		// Behind the reverse proxy every request comes from the proxy; c.IP() (logs,
+		// rate limits, review audit) takes the visitor's address from ProxyHeader
+		// instead, when the request really came through a trusted proxy
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// Register global middleware
@@ -120,6 +185,25 @@ func main() {
 		},
 	})
 
+	// Per-visitor rate limits for review spam, keyed by c.IP() (in memory, so per instance)
+	reviewLimiter := limiter.New(limiter.Config{
+		Max:          5,
+		Expiration:   1 * time.Hour,
+		LimitReached: tooManyRequests,
+	})
+	reviewPhotoLimiter := limiter.New(limiter.Config{
+		Max:          20,
+		Expiration:   1 * time.Hour,
+		LimitReached: tooManyRequests,
+	})
+
+	// Inquiries record a lead each; a visitor rarely needs more than a few chats an hour
+	inquiryLimiter := limiter.New(limiter.Config{
+		Max:          20,
+		Expiration:   1 * time.Hour,
+		LimitReached: tooManyRequests,
+	})
+
 	// Health check endpoint (no middleware)
 	app.Get("/health", func(c *fiber.Ctx) error {
 		// Test database connection
@@ -137,12 +221,72 @@ func main() {
 	// Static files with correct MIME types (fasthttp serves .css/.js as text/plain)
 	app.Get("/static/*", staticFileHandler("./web/static"))
 
+	// Search engines: sitemap index with one sitemap per page of products, and robots.txt
+	app.Get("/robots.txt", seoHandler.Robots)
+	app.Get("/sitemap.xml", seoHandler.Sitemap)
+	app.Get("/sitemap-pages.xml", seoHandler.SitemapPages)
+	app.Get("/sitemap-products-:page.xml", seoHandler.SitemapProducts)
+
+	// Marketplace product feeds, fetched on a schedule by Google Merchant Center and
+	// Meta Commerce Manager (FEED_TOKEN keeps anyone else out)
+	app.Get("/feeds/google.xml", feedHandler.GoogleFeed)
+	app.Get("/feeds/meta.csv", feedHandler.MetaFeed)
+
+	// Signed-in resellers see their customer group prices on every page below
+	app.Use(middleware.CustomerSession(customerService))
+
+	// Storefront language from the /en prefix, the visitor's choice or Accept-Language
+	app.Use(middleware.Locale(cfg.BaseURL, cfg.Env))
+
+	// Store name, address, WhatsApp number and social links for every page, as .Store
+	app.Use(middleware.StoreSettings(settingsService))
+
+	// Storefront pages, in Indonesian at their own path and in English under /en.
+	// Fragments and form posts below stay unprefixed and follow the chosen language.
+	for _, storefront := range []fiber.Router{app, app.Group(i18n.EN.Prefix())} {
+		storefront.Get("/", publicHandler.Landing)
+		storefront.Get("/p/:slug", publicHandler.ProductDetail)
+		storefront.Get("/kategori/:slug", publicHandler.CategoryPage)
+		storefront.Get("/keranjang", basketHandler.ShowBasket)
+		storefront.Get("/wishlist", wishlistHandler.ShowWishlist)
+		storefront.Get("/p/:slug/ulasan", csrfMiddleware, reviewHandler.ReviewForm)
+		storefront.Get("/reseller/daftar", csrfMiddleware, customerHandler.RegisterPage)
+		storefront.Get("/reseller/masuk", csrfMiddleware, customerHandler.LoginPage)
+		storefront.Get("/reseller/akun", customerHandler.Account)
+	}
+
 	// Public routes (no CSRF, no auth)
-	app.Get("/", publicHandler.Landing)
-	app.Get("/products/:id", publicHandler.ProductDetail)
+	app.Get("/products/:id", publicHandler.ProductRedirect)
 	app.Post("/products/search", publicHandler.SearchProducts)
+	app.Get("/search/suggest", publicHandler.SearchSuggest)
 	app.Post("/products/filter", publicHandler.FilterProducts)
 
+	// Inquiry basket (cookie-identified, checked out as one WhatsApp message)
+	app.Get("/keranjang/badge", basketHandler.Badge)
+	app.Post("/keranjang/items", basketHandler.AddItem)
+	app.Post("/keranjang/items/:id", basketHandler.UpdateItem)
+	app.Post("/keranjang/items/:id/delete", basketHandler.RemoveItem)
+	app.Post("/keranjang/checkout", basketHandler.Checkout)
+
+	// Wishlist and recently viewed products (visitor cookie, moved to the reseller account on sign-in)
+	app.Get("/wishlist/bagikan", wishlistHandler.Share)
+	app.Post("/wishlist/:productId", wishlistHandler.Toggle)
+	app.Get("/terakhir-dilihat", wishlistHandler.RecentlyViewed)
+	app.Post("/terakhir-dilihat/:productId", wishlistHandler.RecordView)
+
+	// Product reviews (held for moderation; honeypot field and per-IP limits against spam)
+	app.Post("/p/:slug/ulasan", reviewLimiter, csrfMiddleware, reviewHandler.SubmitReview)
+	app.Post("/ulasan/foto/sign", reviewPhotoLimiter, csrfMiddleware, reviewHandler.SignPhoto)
+
+	// WhatsApp inquiries are recorded as leads on the way to wa.me
+	app.Post("/inquiry/:productId", inquiryLimiter, inquiryHandler.Inquire)
+
+	// Reseller accounts (applications are approved by an admin before sign-in)
+	app.Post("/reseller/daftar", csrfMiddleware, customerHandler.Register)
+	app.Post("/reseller/masuk", csrfMiddleware, customerHandler.Login)
+	app.Post("/reseller/keluar", customerHandler.Logout)
+	app.Get("/reseller/badge", customerHandler.Badge)
+
 	// Admin login routes (CSRF needed on GET to generate token, and on POST to validate)
 	app.Get("/admin/login", csrfMiddleware, authHandler.LoginPage)
 	app.Post("/admin/login", csrfMiddleware, authHandler.Login)
@@ -167,6 +311,70 @@ func main() {
 	adminGroup.Post("/categories/:id", categoryHandler.UpdateCategory)
 	adminGroup.Delete("/categories/:id", categoryHandler.DeleteCategory)
 
+	// Admin inventory routes
+	adminGroup.Get("/inventory", inventoryHandler.ListStock)
+	adminGroup.Get("/inventory/movements", inventoryHandler.ListMovements)
+	adminGroup.Get("/inventory/movements/new", inventoryHandler.NewMovementForm)
+	adminGroup.Post("/inventory/movements", inventoryHandler.CreateMovement)
+
+	// Admin promotion routes
+	adminGroup.Get("/promotions", promotionHandler.ListPromotions)
+	adminGroup.Get("/promotions/new", promotionHandler.NewPromotionForm)
+	adminGroup.Post("/promotions", promotionHandler.CreatePromotion)
+	adminGroup.Get("/promotions/:id/edit", promotionHandler.EditPromotionForm)
+	adminGroup.Post("/promotions/:id", promotionHandler.UpdatePromotion)
+	adminGroup.Post("/promotions/:id/delete", promotionHandler.DeletePromotion)
+
+	// Admin colour palette routes
+	adminGroup.Get("/colors", colorHandler.ListColors)
+	adminGroup.Get("/colors/new", colorHandler.NewColorForm)
+	adminGroup.Post("/colors", colorHandler.CreateColor)
+	adminGroup.Post("/colors/merge", colorHandler.MergeColors)
+	adminGroup.Get("/colors/:id/edit", colorHandler.EditColorForm)
+	adminGroup.Post("/colors/:id", colorHandler.UpdateColor)
+	adminGroup.Post("/colors/:id/delete", colorHandler.DeleteColor)
+
+	// Admin inquiry inbox routes
+	adminGroup.Get("/inquiries", inquiryHandler.ListInquiries)
+	adminGroup.Post("/inquiries/:id", inquiryHandler.UpdateInquiry)
+
+	// Admin order routes
+	adminGroup.Get("/orders", orderHandler.ListOrders)
+	adminGroup.Get("/orders/new", orderHandler.NewOrderForm)
+	adminGroup.Get("/orders/picker", orderHandler.ProductPicker)
+	adminGroup.Post("/orders", orderHandler.CreateOrder)
+	adminGroup.Get("/orders/:id", orderHandler.ShowOrder)
+	adminGroup.Post("/orders/:id/status", orderHandler.UpdateOrderStatus)
+	adminGroup.Get("/orders/:id/packing-slip", orderHandler.PackingSlip)
+
+	// Admin quotation & invoice routes (issued documents are read-only)
+	adminGroup.Get("/documents", documentHandler.ListDocuments)
+	adminGroup.Get("/documents/new", documentHandler.NewDocumentForm)
+	adminGroup.Post("/documents", documentHandler.CreateDocument)
+	adminGroup.Get("/documents/:id", documentHandler.ShowDocument)
+	adminGroup.Get("/documents/:id/print", documentHandler.PrintDocument)
+	adminGroup.Get("/documents/:id/pdf", documentHandler.DownloadPDF)
+
+	// Admin customer account and customer group routes
+	adminGroup.Get("/customers", customerHandler.ListCustomers)
+	adminGroup.Get("/customers/:id", customerHandler.ShowCustomer)
+	adminGroup.Post("/customers/:id", customerHandler.ReviewCustomer)
+	adminGroup.Get("/customer-groups", customerGroupHandler.ListGroups)
+	adminGroup.Get("/customer-groups/new", customerGroupHandler.NewGroupForm)
+	adminGroup.Post("/customer-groups", customerGroupHandler.CreateGroup)
+	adminGroup.Get("/customer-groups/:id/edit", customerGroupHandler.EditGroupForm)
+	adminGroup.Post("/customer-groups/:id", customerGroupHandler.UpdateGroup)
+	adminGroup.Post("/customer-groups/:id/delete", customerGroupHandler.DeleteGroup)
+
+	// Admin review moderation routes
+	adminGroup.Get("/reviews", reviewHandler.ListReviews)
+	adminGroup.Post("/reviews/:id/status", reviewHandler.ModerateReview)
+	adminGroup.Post("/reviews/:id/delete", reviewHandler.DeleteReview)
+
+	// Admin store settings routes
+	adminGroup.Get("/settings", settingsHandler.EditSettings)
+	adminGroup.Post("/settings", settingsHandler.UpdateSettings)
+
 	// Start server with graceful shutdown
 	startServer(app, cfg.Port)
 }
@@ -202,11 +410,7 @@ func initTemplateEngine(env string) *html.Engine {
 	}
 
 	// Add custom template functions
-	engine.AddFunc("formatPrice", func(price float64) string {
-		// Format price with thousand separators
-		priceInt := int64(price)
-		return fmt.Sprintf("Rp %s", formatNumber(priceInt))
-	})
+	engine.AddFunc("formatPrice", formatPrice)
 
 	// Math functions for pagination
 	engine.AddFunc("add", func(a, b int) int {
@@ -238,6 +442,34 @@ func initTemplateEngine(env string) *html.Engine {
 		return *i
 	})
 
+	// t translates template text into the page's language: {{ t .Locale "Katalog Produk" }}
+	engine.AddFunc("t", func(locale interface{}, message string, args ...interface{}) string {
+		l, _ := locale.(i18n.Locale)
+		return i18n.T(l, message, args...)
+	})
+
+	// localePath puts a storefront path under the page's language prefix: {{ localePath .Locale "/wishlist" }}
+	engine.AddFunc("localePath", func(locale interface{}, path string) string {
+		l, _ := locale.(i18n.Locale)
+		return l.Path(path)
+	})
+
+	// dict builds a map from key/value pairs so recursive templates can take several arguments
+	engine.AddFunc("dict", func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, fmt.Errorf("dict expects key/value pairs")
+		}
+		m := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings")
+			}
+			m[key] = pairs[i+1]
+		}
+		return m, nil
+	})
+
 	return engine
 }
 
@@ -265,6 +497,17 @@ func customErrorHandler(c *fiber.Ctx, err error) error {
 	return c.Status(code).SendString(message)
 }
 
+// tooManyRequests answers requests over a rate limit (JSON for fetch calls)
+func tooManyRequests(c *fiber.Ctx) error {
+	message := "Terlalu banyak permintaan, silakan coba lagi nanti"
+	if c.Get("Accept") == "application/json" {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+	return c.Status(fiber.StatusTooManyRequests).SendString(message)
+}
+
 // staticFileHandler serves files from root with correct MIME types (fasthttp serves .css/.js as text/plain).
 func staticFileHandler(root string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -335,21 +578,28 @@ func initLogging() {
 	log.Println("Logging initialized - stdout only")
 }
 
-// formatNumber formats a number with dot as thousands separator (Indonesian Rupiah style: 9.000, 1.500.000)
-func formatNumber(n int64) string {
-	if n < 0 {
-		return "-" + formatNumber(-n)
-	}
-	s := fmt.Sprintf("%d", n)
-	if len(s) <= 3 {
-		return s
-	}
-	var b strings.Builder
-	for i, r := range s {
-		if i > 0 && (len(s)-i)%3 == 0 {
-			b.WriteByte('.')
+// formatPrice formats a price with thousand separators ("Rp 9.000"). Wholesale
+// price tiers render with their minimum quantity ("Rp 8.000 (min. 50 pcs)").
+func formatPrice(v interface{}) string {
+	switch p := v.(type) {
+	case float64:
+		return fmt.Sprintf("Rp %s", utils.FormatNumber(int64(p)))
+	case int:
+		return fmt.Sprintf("Rp %s", utils.FormatNumber(int64(p)))
+	case models.PriceTier:
+		return fmt.Sprintf("Rp %s (min. %d pcs)", utils.FormatNumber(int64(p.UnitPrice)), p.MinQty)
+	case *models.PriceTier:
+		if p == nil {
+			return ""
+		}
+		return formatPrice(*p)
+	case []models.PriceTier:
+		parts := make([]string, len(p))
+		for i, tier := range p {
+			parts[i] = formatPrice(tier)
 		}
-		b.WriteRune(r)
+		return strings.Join(parts, " · ")
+	default:
+		return fmt.Sprint(v)
 	}
-	return b.String()
 }
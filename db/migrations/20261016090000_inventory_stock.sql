@@ -0,0 +1,40 @@
+-- migrate:up
+ALTER TABLE product_variants
+ADD COLUMN IF NOT EXISTS stock_qty INTEGER NOT NULL DEFAULT 0 CHECK (stock_qty >= 0);
+
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('stock_in', 'sale', 'adjustment', 'return', 'damage')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+    reason VARCHAR(255) NOT NULL,
+    admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_variant ON inventory_movements(variant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_created ON inventory_movements(created_at DESC);
+
+-- Opening balance: variants of products that were not flagged sold out start with one unit,
+-- so the catalog does not flip to "Habis" before an admin records a real stock count.
+UPDATE product_variants pv
+SET stock_qty = 1
+FROM products p
+WHERE p.id = pv.product_id AND p.is_sold = FALSE;
+
+INSERT INTO inventory_movements (variant_id, movement_type, quantity, balance_after, reason)
+SELECT id, 'adjustment', stock_qty, stock_qty, 'Saldo awal (migrasi dari flag is_sold)'
+FROM product_variants
+WHERE stock_qty > 0;
+
+-- products.is_sold is now derived from variant stock
+UPDATE products p
+SET is_sold = NOT EXISTS (
+    SELECT 1 FROM product_variants pv
+    WHERE pv.product_id = p.id AND pv.stock_qty > 0
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS inventory_movements;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS stock_qty;
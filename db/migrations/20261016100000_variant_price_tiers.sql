@@ -0,0 +1,15 @@
+-- migrate:up
+-- Wholesale price breaks per variant: from min_qty units, each unit costs unit_price.
+-- Quantities below the smallest min_qty pay the variant's regular price.
+CREATE TABLE IF NOT EXISTS variant_price_tiers (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    min_qty INTEGER NOT NULL CHECK (min_qty >= 2),
+    unit_price DECIMAL(12,2) NOT NULL CHECK (unit_price > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(variant_id, min_qty)
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS variant_price_tiers;
@@ -0,0 +1,44 @@
+-- migrate:up
+-- Scheduled promotions replace the per-variant is_sale flag. A promotion is live while
+-- is_active is set and LOCALTIMESTAMP falls inside [starts_at, ends_at).
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(12,2) NOT NULL CHECK (discount_value > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at),
+    CHECK (discount_type <> 'percent' OR discount_value <= 100)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_window ON promotions(starts_at, ends_at) WHERE is_active;
+
+-- Each target row points at exactly one product, variant or category
+CREATE TABLE IF NOT EXISTS promotion_targets (
+    id SERIAL PRIMARY KEY,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    CHECK (num_nonnulls(product_id, variant_id, category_id) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_targets_promotion ON promotion_targets(promotion_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_targets_product ON promotion_targets(product_id) WHERE product_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_promotion_targets_variant ON promotion_targets(variant_id) WHERE variant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_promotion_targets_category ON promotion_targets(category_id) WHERE category_id IS NOT NULL;
+
+-- is_sale carried no discount amount or schedule, so there is nothing to carry over
+DROP INDEX IF EXISTS idx_variants_sale;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS is_sale;
+
+-- migrate:down
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS is_sale BOOLEAN DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_variants_sale ON product_variants(is_sale);
+
+DROP TABLE IF EXISTS promotion_targets;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,19 @@
+-- migrate:up
+-- Additional product photos (close-ups, texture, in-use shots) shown as a gallery.
+-- main_photo_url stays the cover image; variant_id optionally ties a photo to a colour.
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    image_url VARCHAR(500) NOT NULL,
+    image_id VARCHAR(200) NOT NULL,
+    alt_text VARCHAR(200) NOT NULL DEFAULT '',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product ON product_images(product_id, sort_order);
+
+-- migrate:down
+DROP TABLE IF EXISTS product_images;
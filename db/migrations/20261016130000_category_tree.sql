@@ -0,0 +1,20 @@
+-- migrate:up
+-- Categories form a tree. path is the materialized list of ancestor IDs including
+-- the category itself, e.g. '/1/4/9/', so a subtree is "path LIKE '/1/4/%'".
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS path VARCHAR(500) NOT NULL DEFAULT '';
+
+UPDATE categories SET path = '/' || id || '/' WHERE path = '';
+
+ALTER TABLE categories ADD CONSTRAINT categories_not_own_parent CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_path ON categories(path text_pattern_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_categories_path;
+DROP INDEX IF EXISTS idx_categories_parent;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_not_own_parent;
+ALTER TABLE categories DROP COLUMN IF EXISTS path;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,24 @@
+-- migrate:up
+-- Content for the public /kategori/:slug pages
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS title VARCHAR(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS banner_url VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS banner_id VARCHAR(200) NOT NULL DEFAULT '';
+
+-- Slugs a category used before it was renamed; they 301 to the current slug
+CREATE TABLE IF NOT EXISTS category_slug_history (
+    slug VARCHAR(100) PRIMARY KEY,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_slug_history_category ON category_slug_history(category_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS category_slug_history;
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS banner_id,
+    DROP COLUMN IF EXISTS banner_url,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS title;
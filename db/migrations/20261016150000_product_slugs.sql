@@ -0,0 +1,38 @@
+-- migrate:up
+-- Public product URLs are /p/:slug. Slugs follow utils.GenerateSlug; collisions get -2, -3, ...
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(250);
+
+WITH generated AS (
+    SELECT id, COALESCE(NULLIF(trim(both '-' FROM regexp_replace(lower(title), '[^a-z0-9-]+', '-', 'g')), ''), 'produk') AS base
+    FROM products
+),
+numbered AS (
+    SELECT id, base, ROW_NUMBER() OVER (PARTITION BY base ORDER BY id) AS n
+    FROM generated
+)
+UPDATE products p
+SET slug = CASE WHEN numbered.n = 1 THEN numbered.base ELSE numbered.base || '-' || numbered.n END
+FROM numbered
+WHERE numbered.id = p.id AND p.slug IS NULL;
+
+-- A base ending in a number (e.g. "pita-2") could collide with a numbered duplicate; fall back to the ID
+UPDATE products p
+SET slug = p.slug || '-' || p.id
+WHERE EXISTS (SELECT 1 FROM products o WHERE o.slug = p.slug AND o.id < p.id);
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products(slug);
+
+-- Slugs a product used before it was renamed; they 301 to the current slug
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    slug VARCHAR(250) PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_history_product ON product_slug_history(product_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS product_slug_history;
+DROP INDEX IF EXISTS idx_products_slug;
+ALTER TABLE products DROP COLUMN IF EXISTS slug;
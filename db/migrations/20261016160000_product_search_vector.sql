@@ -0,0 +1,25 @@
+-- migrate:up
+-- Weighted search document per product: title and code (A), category name and
+-- variant colours (B), description (C). Kept in sync by ProductRepository.RefreshSearchVector
+-- and by CategoryRepository.Update when a category is renamed.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+UPDATE products p SET search_vector =
+    setweight(to_tsvector('indonesian', coalesce(p.title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(p.code, '')), 'A') ||
+    setweight(to_tsvector('indonesian', coalesce((SELECT c.name FROM categories c WHERE c.id = p.category_id), '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce((SELECT string_agg(pv.color, ' ') FROM product_variants pv WHERE pv.product_id = p.id), '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce(p.description, '')), 'C');
+
+-- The title-only index was never used by the ILIKE search it was meant for
+DROP INDEX IF EXISTS idx_products_search;
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING gin(search_vector);
+
+-- Exact code lookups are case-insensitive
+CREATE INDEX IF NOT EXISTS idx_products_code_lower ON products(lower(code));
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_products_code_lower;
+DROP INDEX IF EXISTS idx_products_search_vector;
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+CREATE INDEX IF NOT EXISTS idx_products_search ON products USING gin(to_tsvector('indonesian', title));
@@ -0,0 +1,12 @@
+-- migrate:up
+-- Trigram matching backs up full-text search for typos ("ptia satn") and mixed languages
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_products_title_trgm ON products USING gin(title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_code_trgm ON products USING gin(code gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING gin(name gin_trgm_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_categories_name_trgm;
+DROP INDEX IF EXISTS idx_products_code_trgm;
+DROP INDEX IF EXISTS idx_products_title_trgm;
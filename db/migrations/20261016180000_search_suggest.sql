@@ -0,0 +1,10 @@
+-- migrate:up
+-- Search-as-you-type matches titles, codes and category names by prefix
+CREATE INDEX IF NOT EXISTS idx_products_title_prefix ON products(lower(title) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_products_code_prefix ON products(lower(code) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_name_prefix ON categories(lower(name) text_pattern_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_categories_name_prefix;
+DROP INDEX IF EXISTS idx_products_code_prefix;
+DROP INDEX IF EXISTS idx_products_title_prefix;
@@ -0,0 +1,25 @@
+-- migrate:up
+-- Lowest and highest regular unit price across a product's variants (the base price when
+-- a variant has no price of its own, or when there are no variants). Kept in sync by
+-- ProductRepository.RefreshPriceRange; price filters and sorting use it.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS min_price DECIMAL(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_price DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+UPDATE products p SET
+    min_price = COALESCE((
+        SELECT MIN(CASE WHEN pv.price_adjustment > 0 THEN pv.price_adjustment ELSE p.base_price END)
+        FROM product_variants pv WHERE pv.product_id = p.id
+    ), p.base_price),
+    max_price = COALESCE((
+        SELECT MAX(CASE WHEN pv.price_adjustment > 0 THEN pv.price_adjustment ELSE p.base_price END)
+        FROM product_variants pv WHERE pv.product_id = p.id
+    ), p.base_price);
+
+CREATE INDEX IF NOT EXISTS idx_products_min_price ON products(min_price);
+CREATE INDEX IF NOT EXISTS idx_products_max_price ON products(max_price);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_products_max_price;
+DROP INDEX IF EXISTS idx_products_min_price;
+ALTER TABLE products DROP COLUMN IF EXISTS max_price, DROP COLUMN IF EXISTS min_price;
@@ -0,0 +1,68 @@
+-- migrate:up
+-- Managed colour palette. Variants keep their own display label (product_variants.color)
+-- and point at a palette colour for swatches and the colour-family filter.
+CREATE TABLE IF NOT EXISTS colors (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    aliases TEXT[] NOT NULL DEFAULT '{}', -- Lowercase alternative labels ("red", "merah tua")
+    hex VARCHAR(7) NOT NULL CHECK (hex ~ '^#[0-9a-f]{6}$'),
+    family VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_colors_family ON colors(family);
+CREATE INDEX IF NOT EXISTS idx_colors_aliases ON colors USING gin(aliases);
+
+INSERT INTO colors (name, aliases, hex, family) VALUES
+    ('Merah', '{red}', '#dc2626', 'merah'),
+    ('Maroon', '{"merah maroon","merah marun",marun}', '#7f1d1d', 'merah'),
+    ('Pink', '{"merah muda",rose}', '#f472b6', 'pink'),
+    ('Fuchsia', '{magenta,"pink fanta",fanta}', '#c026d3', 'pink'),
+    ('Oranye', '{orange,jingga}', '#f97316', 'oranye'),
+    ('Peach', '{salem,salmon}', '#fdba74', 'oranye'),
+    ('Kuning', '{yellow}', '#facc15', 'kuning'),
+    ('Hijau', '{green}', '#16a34a', 'hijau'),
+    ('Tosca', '{turquoise,"hijau tosca"}', '#14b8a6', 'hijau'),
+    ('Sage', '{"hijau sage",mint}', '#a3b899', 'hijau'),
+    ('Biru', '{blue}', '#2563eb', 'biru'),
+    ('Navy', '{"biru dongker",dongker,"biru navy"}', '#1e3a8a', 'biru'),
+    ('Baby Blue', '{"biru muda","light blue"}', '#93c5fd', 'biru'),
+    ('Ungu', '{purple,violet}', '#7c3aed', 'ungu'),
+    ('Lilac', '{lavender,lilak,"ungu muda"}', '#c4b5fd', 'ungu'),
+    ('Coklat', '{cokelat,brown}', '#92400e', 'coklat'),
+    ('Krem', '{cream,beige,nude}', '#f5e6c8', 'krem'),
+    ('Putih', '{white}', '#ffffff', 'putih'),
+    ('Hitam', '{black}', '#111827', 'hitam'),
+    ('Abu-abu', '{abu,grey,gray}', '#9ca3af', 'abu'),
+    ('Emas', '{gold,keemasan}', '#d4a017', 'emas'),
+    ('Perak', '{silver}', '#c0c0c0', 'perak')
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS color_id INTEGER REFERENCES colors(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_product_variants_color ON product_variants(color_id);
+
+-- Link existing variants whose label matches a palette name or alias
+UPDATE product_variants pv
+SET color_id = c.id
+FROM colors c
+WHERE pv.color_id IS NULL
+  AND (lower(trim(pv.color)) = lower(c.name) OR lower(trim(pv.color)) = ANY(c.aliases));
+
+-- Palette names and aliases join the search document (see productSearchVector)
+UPDATE products p SET search_vector =
+    setweight(to_tsvector('indonesian', coalesce(p.title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(p.code, '')), 'A') ||
+    setweight(to_tsvector('indonesian', coalesce((SELECT c.name FROM categories c WHERE c.id = p.category_id), '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce((
+        SELECT string_agg(concat_ws(' ', pv.color, pc.name, array_to_string(pc.aliases, ' ')), ' ')
+        FROM product_variants pv
+        LEFT JOIN colors pc ON pc.id = pv.color_id
+        WHERE pv.product_id = p.id
+    ), '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce(p.description, '')), 'C');
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_product_variants_color;
+ALTER TABLE product_variants DROP COLUMN IF EXISTS color_id;
+DROP TABLE IF EXISTS colors;
@@ -0,0 +1,28 @@
+-- migrate:up
+-- Inquiry basket of an anonymous visitor, identified by a random token in a cookie.
+-- Baskets are created on the first add and expire after a period without changes.
+CREATE TABLE IF NOT EXISTS baskets (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_baskets_updated_at ON baskets(updated_at);
+
+CREATE TABLE IF NOT EXISTS basket_items (
+    id SERIAL PRIMARY KEY,
+    basket_id INTEGER NOT NULL REFERENCES baskets(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One line per product and variant; adding the same item again raises its quantity
+CREATE UNIQUE INDEX IF NOT EXISTS idx_basket_items_line ON basket_items(basket_id, product_id, COALESCE(variant_id, 0));
+
+-- migrate:down
+DROP TABLE IF EXISTS basket_items;
+DROP TABLE IF EXISTS baskets;
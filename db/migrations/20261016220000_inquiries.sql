@@ -0,0 +1,26 @@
+-- migrate:up
+-- WhatsApp inquiries recorded as leads before the visitor is sent to wa.me.
+-- The product and variant are kept as a snapshot so leads survive catalog changes.
+CREATE TABLE IF NOT EXISTS inquiries (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    product_code VARCHAR(50) NOT NULL,
+    product_title VARCHAR(200) NOT NULL,
+    variant_color VARCHAR(50) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(12,2) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('product', 'basket')),
+    referrer TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'contacted', 'won', 'lost')),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inquiries_created_at ON inquiries(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inquiries_status ON inquiries(status);
+CREATE INDEX IF NOT EXISTS idx_inquiries_product_id ON inquiries(product_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS inquiries;
@@ -0,0 +1,56 @@
+-- migrate:up
+-- Quotations and invoices. Issued documents are never updated or deleted: the
+-- customer, store details, lines and totals are snapshotted when they are issued.
+CREATE TABLE IF NOT EXISTS documents (
+    id SERIAL PRIMARY KEY,
+    doc_type VARCHAR(20) NOT NULL CHECK (doc_type IN ('quotation', 'invoice')),
+    number VARCHAR(30) NOT NULL UNIQUE,
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    customer_name VARCHAR(100) NOT NULL,
+    customer_phone VARCHAR(30) NOT NULL DEFAULT '',
+    customer_address TEXT NOT NULL DEFAULT '',
+    store_name VARCHAR(200) NOT NULL,
+    store_address TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    subtotal DECIMAL(12,2) NOT NULL CHECK (subtotal >= 0),
+    discount_amount DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+    total DECIMAL(12,2) NOT NULL CHECK (total >= 0),
+    due_date DATE, -- Valid-until date for quotations, payment due date for invoices
+    admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (doc_type, year, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_type_issued_at ON documents(doc_type, issued_at DESC);
+
+CREATE TABLE IF NOT EXISTS document_items (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE RESTRICT,
+    product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    product_code VARCHAR(50) NOT NULL,
+    product_title VARCHAR(200) NOT NULL,
+    variant_color VARCHAR(50) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(12,2) NOT NULL CHECK (unit_price >= 0),
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent <= 100),
+    line_total DECIMAL(12,2) NOT NULL CHECK (line_total >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_items_document_id ON document_items(document_id);
+
+-- Last number issued per document type and year. The row is incremented in the
+-- same transaction that inserts the document, so a failed insert rolls the
+-- number back and the sequence stays gap-free.
+CREATE TABLE IF NOT EXISTS document_sequences (
+    doc_type VARCHAR(20) NOT NULL,
+    year INTEGER NOT NULL,
+    last_number INTEGER NOT NULL,
+    PRIMARY KEY (doc_type, year)
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS document_sequences;
+DROP TABLE IF EXISTS document_items;
+DROP TABLE IF EXISTS documents;
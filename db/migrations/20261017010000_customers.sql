@@ -0,0 +1,45 @@
+-- migrate:up
+-- Customer groups price the catalog for signed-in resellers: an explicit price per
+-- variant when one is set, otherwise the group discount off the regular price.
+CREATE TABLE IF NOT EXISTS customer_groups (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent <= 100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS customer_group_prices (
+    group_id INTEGER NOT NULL REFERENCES customer_groups(id) ON DELETE CASCADE,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    unit_price DECIMAL(12,2) NOT NULL CHECK (unit_price >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, variant_id)
+);
+
+-- Reseller accounts. Visitors apply with their WhatsApp number as the login and
+-- can only sign in once an admin approves the application.
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    business_name VARCHAR(100) NOT NULL DEFAULT '',
+    phone VARCHAR(30) NOT NULL UNIQUE,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    password_hash VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'suspended')),
+    group_id INTEGER REFERENCES customer_groups(id) ON DELETE SET NULL,
+    admin_notes TEXT NOT NULL DEFAULT '',
+    approved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_status ON customers(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_customers_group_id ON customers(group_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS customer_group_prices;
+DROP TABLE IF EXISTS customer_groups;
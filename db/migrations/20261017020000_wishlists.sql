@@ -0,0 +1,42 @@
+-- migrate:up
+-- Anonymous shopper identified by a random token in a long-lived cookie. Their
+-- wishlist and recently viewed products move to their reseller account when they
+-- sign in; visitors expire after a period without activity.
+CREATE TABLE IF NOT EXISTS visitors (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_visitors_updated_at ON visitors(updated_at);
+
+-- Rows belong to exactly one owner: a visitor, or a signed-in customer
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    id SERIAL PRIMARY KEY,
+    visitor_id INTEGER REFERENCES visitors(id) ON DELETE CASCADE,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((visitor_id IS NULL) <> (customer_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlist_items_visitor ON wishlist_items(visitor_id, product_id) WHERE visitor_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlist_items_customer ON wishlist_items(customer_id, product_id) WHERE customer_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS recently_viewed (
+    id SERIAL PRIMARY KEY,
+    visitor_id INTEGER REFERENCES visitors(id) ON DELETE CASCADE,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((visitor_id IS NULL) <> (customer_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recently_viewed_visitor ON recently_viewed(visitor_id, product_id) WHERE visitor_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recently_viewed_customer ON recently_viewed(customer_id, product_id) WHERE customer_id IS NOT NULL;
+
+-- migrate:down
+DROP TABLE IF EXISTS recently_viewed;
+DROP TABLE IF EXISTS wishlist_items;
+DROP TABLE IF EXISTS visitors;
@@ -0,0 +1,44 @@
+-- migrate:up
+-- Product reviews submitted on the public site. New reviews wait in the admin
+-- moderation queue; only approved ones are shown and counted in the rating.
+CREATE TABLE IF NOT EXISTS product_reviews (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL, -- Signed-in reseller who wrote it
+    author_name VARCHAR(100) NOT NULL,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    approved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_reviews_product ON product_reviews(product_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_product_reviews_status ON product_reviews(status, created_at DESC);
+
+-- Photos uploaded by the reviewer straight to Cloudinary (reviews folder only)
+CREATE TABLE IF NOT EXISTS product_review_photos (
+    id SERIAL PRIMARY KEY,
+    review_id INTEGER NOT NULL REFERENCES product_reviews(id) ON DELETE CASCADE,
+    image_url TEXT NOT NULL,
+    image_id VARCHAR(255) NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_review_photos_review ON product_review_photos(review_id, sort_order);
+
+-- Average and number of approved reviews, shown on catalog cards. Kept in sync by
+-- ReviewRepository.RefreshProductRating when reviews are moderated.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS rating_average DECIMAL(3,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+-- migrate:down
+ALTER TABLE products DROP COLUMN IF EXISTS rating_count, DROP COLUMN IF EXISTS rating_average;
+DROP TABLE IF EXISTS product_review_photos;
+DROP TABLE IF EXISTS product_reviews;
@@ -0,0 +1,12 @@
+-- migrate:up
+-- Store settings edited from the admin panel, one row per setting. The server
+-- fills in missing keys from its environment on boot, so env values only matter
+-- until an admin saves the settings page.
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(50) PRIMARY KEY,
+    value TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS settings;
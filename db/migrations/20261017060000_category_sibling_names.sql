@@ -0,0 +1,16 @@
+-- migrate:up
+-- Category names only need to be unique among siblings, so "Kertas Korea" can sit
+-- under two parents. Slugs stay unique because /kategori/:slug is flat; a
+-- subcategory whose name slug is taken gets its parent's slug as a prefix, which
+-- needs the longer columns.
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name ON categories(COALESCE(parent_id, 0), name);
+
+ALTER TABLE categories ALTER COLUMN slug TYPE VARCHAR(300);
+ALTER TABLE category_slug_history ALTER COLUMN slug TYPE VARCHAR(300);
+
+-- migrate:down
+ALTER TABLE category_slug_history ALTER COLUMN slug TYPE VARCHAR(100);
+ALTER TABLE categories ALTER COLUMN slug TYPE VARCHAR(100);
+DROP INDEX IF EXISTS idx_categories_parent_name;
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
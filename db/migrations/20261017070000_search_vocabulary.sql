@@ -0,0 +1,18 @@
+-- migrate:up
+-- Words used in product titles and category names, for "did you mean" corrections.
+-- Kept as a materialized view so a weak search looks words up through the trigram
+-- index instead of splitting every title; the server refreshes it periodically.
+CREATE MATERIALIZED VIEW IF NOT EXISTS search_vocabulary AS
+SELECT DISTINCT word FROM (
+    SELECT regexp_split_to_table(lower(title), '[^[:alnum:]]+') AS word FROM products
+    UNION ALL
+    SELECT regexp_split_to_table(lower(name), '[^[:alnum:]]+') FROM categories
+) w
+WHERE length(word) >= 2;
+
+-- The unique index lets the view refresh concurrently without blocking searches
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_vocabulary_word ON search_vocabulary(word);
+CREATE INDEX IF NOT EXISTS idx_search_vocabulary_trgm ON search_vocabulary USING gin(word gin_trgm_ops);
+
+-- migrate:down
+DROP MATERIALIZED VIEW IF EXISTS search_vocabulary;
@@ -0,0 +1,67 @@
+-- migrate:up
+-- Lowest and highest unit price a shopper pays for a product right now: each variant
+-- at its regular price after the best live promotion, or at the customer group's price
+-- when that is lower (group_id NULL for the public). Mirrors ProductService pricing so
+-- price filters and sorting agree with the card prices; products.min_price/max_price
+-- remain the regular prices the cards strike through.
+CREATE OR REPLACE FUNCTION product_price_range(
+    target_product_id INTEGER,
+    price_group_id INTEGER,
+    OUT min_price NUMERIC,
+    OUT max_price NUMERIC
+)
+LANGUAGE sql STABLE AS $$
+    WITH product AS (
+        SELECT p.id, p.base_price, p.category_id FROM products p WHERE p.id = target_product_id
+    ),
+    units AS (
+        SELECT pv.id AS variant_id,
+               CASE WHEN pv.price_adjustment > 0 THEN pv.price_adjustment ELSE product.base_price END AS regular
+        FROM product
+        JOIN product_variants pv ON pv.product_id = product.id
+        UNION ALL
+        SELECT NULL, product.base_price
+        FROM product
+        WHERE NOT EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = product.id)
+    ),
+    live AS (
+        SELECT pt.variant_id, pr.discount_type, pr.discount_value
+        FROM product
+        JOIN promotion_targets pt ON (
+            pt.product_id = product.id
+            OR pt.variant_id IN (SELECT variant_id FROM units)
+            OR pt.category_id IN (
+                SELECT target.id FROM categories target
+                JOIN categories own ON own.path LIKE target.path || '%'
+                WHERE own.id = product.category_id
+            )
+        )
+        JOIN promotions pr ON pr.id = pt.promotion_id
+        WHERE pr.is_active AND pr.starts_at <= LOCALTIMESTAMP AND pr.ends_at > LOCALTIMESTAMP
+    ),
+    prices AS (
+        SELECT LEAST(
+            -- Public price: the best live promotion on the product, its category tree or the variant
+            COALESCE((
+                SELECT MIN(GREATEST(ROUND(CASE live.discount_type
+                    WHEN 'percent' THEN units.regular * (1 - live.discount_value / 100)
+                    ELSE units.regular - live.discount_value
+                END), 0))
+                FROM live
+                WHERE live.variant_id IS NULL OR live.variant_id = units.variant_id
+            ), units.regular),
+            -- Group price: an explicit variant price, otherwise the group discount
+            COALESCE(
+                (SELECT gp.unit_price FROM customer_group_prices gp
+                 WHERE gp.group_id = price_group_id AND gp.variant_id = units.variant_id),
+                (SELECT ROUND(units.regular * (1 - cg.discount_percent / 100)) FROM customer_groups cg
+                 WHERE cg.id = price_group_id AND cg.discount_percent > 0)
+            )
+        ) AS price
+        FROM units
+    )
+    SELECT MIN(price), MAX(price) FROM prices
+$$;
+
+-- migrate:down
+DROP FUNCTION IF EXISTS product_price_range(INTEGER, INTEGER);
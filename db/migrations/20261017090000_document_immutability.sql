@@ -0,0 +1,35 @@
+-- migrate:up
+-- Issued documents are legal records: refuse any change to them or their lines,
+-- whatever code path tries it. The one update allowed is a deleted product,
+-- variant or admin clearing its link (ON DELETE SET NULL); the snapshot stays.
+CREATE OR REPLACE FUNCTION reject_document_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    links CONSTANT text[] := ARRAY['admin_id', 'product_id', 'variant_id'];
+    link text;
+BEGIN
+    IF TG_OP = 'UPDATE' AND to_jsonb(NEW) - links = to_jsonb(OLD) - links THEN
+        FOREACH link IN ARRAY links LOOP
+            IF to_jsonb(NEW) ->> link IS NOT NULL AND to_jsonb(NEW) -> link <> to_jsonb(OLD) -> link THEN
+                RAISE EXCEPTION 'issued documents cannot be changed (% on %)', TG_OP, TG_TABLE_NAME;
+            END IF;
+        END LOOP;
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'issued documents cannot be changed (% on %)', TG_OP, TG_TABLE_NAME;
+END;
+$$;
+
+CREATE TRIGGER documents_immutable
+    BEFORE UPDATE OR DELETE ON documents
+    FOR EACH ROW EXECUTE FUNCTION reject_document_change();
+
+CREATE TRIGGER document_items_immutable
+    BEFORE UPDATE OR DELETE ON document_items
+    FOR EACH ROW EXECUTE FUNCTION reject_document_change();
+
+-- migrate:down
+DROP TRIGGER IF EXISTS document_items_immutable ON document_items;
+DROP TRIGGER IF EXISTS documents_immutable ON documents;
+DROP FUNCTION IF EXISTS reject_document_change();
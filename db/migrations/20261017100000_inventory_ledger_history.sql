@@ -0,0 +1,31 @@
+-- migrate:up
+-- The stock ledger outlives its variants: deleting a variant (or its product) only
+-- clears the link, and each entry keeps a snapshot of what it was recorded against.
+ALTER TABLE inventory_movements
+ADD COLUMN IF NOT EXISTS product_code VARCHAR(50) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS product_title VARCHAR(200) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS variant_color VARCHAR(50) NOT NULL DEFAULT '';
+
+UPDATE inventory_movements m
+SET product_code = p.code, product_title = p.title, variant_color = pv.color
+FROM product_variants pv
+JOIN products p ON p.id = pv.product_id
+WHERE pv.id = m.variant_id;
+
+ALTER TABLE inventory_movements
+ALTER COLUMN variant_id DROP NOT NULL,
+DROP CONSTRAINT IF EXISTS inventory_movements_variant_id_fkey,
+ADD CONSTRAINT inventory_movements_variant_id_fkey
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE SET NULL;
+
+-- migrate:down
+DELETE FROM inventory_movements WHERE variant_id IS NULL;
+
+ALTER TABLE inventory_movements
+DROP CONSTRAINT IF EXISTS inventory_movements_variant_id_fkey,
+ADD CONSTRAINT inventory_movements_variant_id_fkey
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
+ALTER COLUMN variant_id SET NOT NULL,
+DROP COLUMN IF EXISTS variant_color,
+DROP COLUMN IF EXISTS product_title,
+DROP COLUMN IF EXISTS product_code;
@@ -0,0 +1,12 @@
+-- migrate:up
+-- Deferrable so a product save can swap colours between two variants; the
+-- check still runs per statement unless a transaction defers it.
+ALTER TABLE product_variants
+DROP CONSTRAINT IF EXISTS product_variants_product_id_color_key,
+ADD CONSTRAINT product_variants_product_id_color_key
+    UNIQUE (product_id, color) DEFERRABLE INITIALLY IMMEDIATE;
+
+-- migrate:down
+ALTER TABLE product_variants
+DROP CONSTRAINT IF EXISTS product_variants_product_id_color_key,
+ADD CONSTRAINT product_variants_product_id_color_key UNIQUE (product_id, color);
@@ -0,0 +1,30 @@
+-- migrate:up
+-- The first slug backfill kept runs of hyphens ("pita--satin") where utils.GenerateSlug
+-- collapses them. Collapse those slugs now; the old ones keep redirecting via the history.
+INSERT INTO product_slug_history (slug, product_id)
+SELECT slug, id FROM products WHERE slug LIKE '%--%'
+ON CONFLICT (slug) DO NOTHING;
+
+WITH collapsed AS (
+    SELECT id, regexp_replace(slug, '-+', '-', 'g') AS slug
+    FROM products
+    WHERE slug LIKE '%--%'
+)
+UPDATE products p
+SET slug = CASE
+    WHEN EXISTS (SELECT 1 FROM products o WHERE o.slug = c.slug AND o.id <> p.id)
+        OR EXISTS (SELECT 1 FROM product_slug_history h WHERE h.slug = c.slug AND h.product_id <> p.id)
+        OR EXISTS (SELECT 1 FROM collapsed o WHERE o.slug = c.slug AND o.id < p.id)
+    THEN c.slug || '-' || p.id
+    ELSE c.slug
+END
+FROM collapsed c
+WHERE c.id = p.id;
+
+-- A product's current slug is never also one of its redirects
+DELETE FROM product_slug_history h
+USING products p
+WHERE h.slug = p.slug;
+
+-- migrate:down
+-- Collapsed slugs stay; the old ones keep redirecting.
@@ -0,0 +1,20 @@
+-- migrate:up
+-- Price filters and sorting read the price range a shopper pays right now: the regular
+-- range after live promotions. ProductService.RefreshPrices keeps it up to date with the
+-- same pricing the cards use; until its first run it equals the regular range.
+DROP FUNCTION IF EXISTS product_price_range(INTEGER, INTEGER);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS current_min_price DECIMAL(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS current_max_price DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+UPDATE products SET current_min_price = min_price, current_max_price = max_price;
+
+CREATE INDEX IF NOT EXISTS idx_products_current_min_price ON products(current_min_price);
+CREATE INDEX IF NOT EXISTS idx_products_current_max_price ON products(current_max_price);
+
+-- migrate:down
+-- product_price_range is not restored; nothing calls it any more
+DROP INDEX IF EXISTS idx_products_current_max_price;
+DROP INDEX IF EXISTS idx_products_current_min_price;
+ALTER TABLE products DROP COLUMN IF EXISTS current_max_price, DROP COLUMN IF EXISTS current_min_price;
@@ -0,0 +1,17 @@
+-- migrate:up
+-- Current price range of each product a customer group sees at other prices than the
+-- public, for the price filters and sorting of signed-in resellers. Products without
+-- a row use products.current_min_price/current_max_price. ProductService.RefreshPrices
+-- keeps it up to date.
+CREATE TABLE IF NOT EXISTS customer_group_price_ranges (
+    group_id INTEGER NOT NULL REFERENCES customer_groups(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    min_price DECIMAL(12,2) NOT NULL,
+    max_price DECIMAL(12,2) NOT NULL,
+    PRIMARY KEY (group_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_group_price_ranges_product ON customer_group_price_ranges(product_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS customer_group_price_ranges;
@@ -0,0 +1,7 @@
+-- migrate:up
+-- issued_at holds UTC whatever the session's TimeZone, so Document.IssuedLocal can
+-- show it in the store's zone. Existing rows were written by UTC sessions.
+ALTER TABLE documents ALTER COLUMN issued_at SET DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC');
+
+-- migrate:down
+ALTER TABLE documents ALTER COLUMN issued_at SET DEFAULT CURRENT_TIMESTAMP;
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -28,6 +29,20 @@ type Config struct {
 	Port      string
 	Env       string
 	JWTSecret string
+	BaseURL   string // Public origin for canonical URLs, e.g. https://example.com (optional)
+
+	// Reverse proxy (Railway): client addresses are read from ProxyHeader, but only on
+	// requests arriving from TrustedProxies, so visitors cannot spoof them directly.
+	// The header must be one the proxy overwrites (X-Real-IP), not one it appends to
+	ProxyHeader    string
+	TrustedProxies []string // IPs or CIDR ranges
+
+	// Search engines
+	RobotsDisallow []string // Paths crawlers are asked to skip, in every storefront language
+	RobotsNoIndex  bool     // Ask crawlers to skip the whole site (staging)
+
+	// Marketplace product feeds
+	FeedToken string // Required as ?token= on the feeds when set
 
 	// WhatsApp
 	WhatsAppNumber string
@@ -57,6 +72,12 @@ func Load() *Config {
 		Port:           getEnv("PORT", "3000"),
 		Env:            getEnv("ENV", "development"),
 		JWTSecret:      getEnv("JWT_SECRET", "dev-secret"),
+		BaseURL:        strings.TrimRight(getEnv("BASE_URL", ""), "/"),
+		ProxyHeader:    getEnv("PROXY_HEADER", "X-Real-IP"),
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", "10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,100.64.0.0/10,127.0.0.1,::1,fc00::/7"),
+		RobotsDisallow: getEnvList("ROBOTS_DISALLOW", "/admin,/keranjang,/wishlist,/reseller,/inquiry,/feeds,/p/*/ulasan"),
+		RobotsNoIndex:  getEnv("ROBOTS_NOINDEX", "") == "true",
+		FeedToken:      getEnv("FEED_TOKEN", ""),
 		WhatsAppNumber: getEnv("WHATSAPP_NUMBER", ""),
 		StoreName:      getEnv("STORE_NAME", "Ancaka Florist Supplier"),
 		StoreAddress:   getEnv("STORE_ADDRESS", ""),
@@ -73,6 +94,17 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList reads a comma-separated list, skipping blank items
+func getEnvList(key, fallback string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnvInt(key string, fallback int) int {
 	if value := os.Getenv(key); value != "" {
 		if n, err := strconv.Atoi(value); err == nil && n > 0 {
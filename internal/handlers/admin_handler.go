@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
 	"github.com/rizkysr90/aslam-flower/internal/models"
 	"github.com/rizkysr90/aslam-flower/internal/repositories"
 	"github.com/rizkysr90/aslam-flower/internal/services"
@@ -24,10 +26,161 @@ func getCSRFToken(c *fiber.Ctx) string {
 	return c.Cookies("csrf_")
 }
 
+// variantTierField matches variants[N][tiers][M][min_qty|unit_price] form keys
+var variantTierField = regexp.MustCompile(`^variants\[(\d+)\]\[tiers\]\[(\d+)\]\[(min_qty|unit_price)\]$`)
+
+// parseVariantColorID reads the palette colour chosen for a variant row; an empty or
+// invalid value leaves the variant to be matched by its label
+func parseVariantColorID(value string) *int {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil
+	}
+	return &id
+}
+
+// parseVariantPriceTiers collects wholesale price tiers from the product form, keyed by
+// variant row index. Tier rows left completely empty are ignored.
+func parseVariantPriceTiers(c *fiber.Ctx) (map[int][]models.PriceTier, error) {
+	rows := make(map[int]map[int]map[string]string)
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		m := variantTierField.FindStringSubmatch(string(key))
+		if m == nil {
+			return
+		}
+		variantIndex, _ := strconv.Atoi(m[1])
+		tierIndex, _ := strconv.Atoi(m[2])
+		if rows[variantIndex] == nil {
+			rows[variantIndex] = make(map[int]map[string]string)
+		}
+		if rows[variantIndex][tierIndex] == nil {
+			rows[variantIndex][tierIndex] = make(map[string]string)
+		}
+		rows[variantIndex][tierIndex][m[3]] = strings.TrimSpace(string(value))
+	})
+
+	tiers := make(map[int][]models.PriceTier, len(rows))
+	for variantIndex, tierRows := range rows {
+		tierIndices := make([]int, 0, len(tierRows))
+		for idx := range tierRows {
+			tierIndices = append(tierIndices, idx)
+		}
+		sort.Ints(tierIndices)
+
+		for _, tierIndex := range tierIndices {
+			row := tierRows[tierIndex]
+			if row["min_qty"] == "" && row["unit_price"] == "" {
+				continue
+			}
+			minQty, err := strconv.Atoi(row["min_qty"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid tier quantity %q", row["min_qty"])
+			}
+			unitPrice, err := strconv.ParseFloat(row["unit_price"], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tier price %q", row["unit_price"])
+			}
+			tiers[variantIndex] = append(tiers[variantIndex], models.PriceTier{
+				MinQty:    minQty,
+				UnitPrice: unitPrice,
+			})
+		}
+	}
+
+	return tiers, nil
+}
+
+// productImageField matches images[N][field] gallery form keys
+var productImageField = regexp.MustCompile(`^images\[(\d+)\]\[(id|image_url|image_id|alt_text|variant_color|sort_order)\]$`)
+
+// parseProductTranslations reads the title and description entered for each translated locale
+func parseProductTranslations(c *fiber.Ctx) []models.ProductTranslation {
+	translations := make([]models.ProductTranslation, 0, len(i18n.Translated))
+	for _, locale := range i18n.Translated {
+		field := "translations[" + locale.String() + "]"
+		translations = append(translations, models.ProductTranslation{
+			Locale:      locale.String(),
+			Title:       strings.TrimSpace(c.FormValue(field + "[title]")),
+			Description: strings.TrimSpace(c.FormValue(field + "[description]")),
+		})
+	}
+	return translations
+}
+
+// parseProductImages collects the gallery from the product form. Rows with an ID must
+// belong to existing and keep their stored asset; new rows must be fresh gallery uploads.
+func (h *AdminHandler) parseProductImages(c *fiber.Ctx, existing []models.ProductImage) ([]models.ProductImage, error) {
+	rows := make(map[int]map[string]string)
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		m := productImageField.FindStringSubmatch(string(key))
+		if m == nil {
+			return
+		}
+		index, _ := strconv.Atoi(m[1])
+		if rows[index] == nil {
+			rows[index] = make(map[string]string)
+		}
+		rows[index][m[2]] = strings.TrimSpace(string(value))
+	})
+
+	existingByID := make(map[int]models.ProductImage, len(existing))
+	for _, image := range existing {
+		existingByID[image.ID] = image
+	}
+
+	indices := make([]int, 0, len(rows))
+	for idx := range rows {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	images := make([]models.ProductImage, 0, len(indices))
+	for _, index := range indices {
+		row := rows[index]
+		image := models.ProductImage{
+			AltText:      row["alt_text"],
+			VariantColor: row["variant_color"],
+			SortOrder:    index,
+		}
+		if order, err := strconv.Atoi(row["sort_order"]); err == nil {
+			image.SortOrder = order
+		}
+
+		if idStr := row["id"]; idStr != "" {
+			id, err := strconv.Atoi(idStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gallery image ID %q", idStr)
+			}
+			stored, ok := existingByID[id]
+			if !ok {
+				return nil, fmt.Errorf("gallery image %d does not belong to this product", id)
+			}
+			image.ID = id
+			image.ImageURL = stored.ImageURL
+			image.ImageID = stored.ImageID
+		} else {
+			if row["image_url"] == "" || row["image_id"] == "" {
+				continue
+			}
+			if err := h.cloudinaryService.ValidateClientUploadResult("gallery", row["image_url"], row["image_id"]); err != nil {
+				return nil, err
+			}
+			image.ImageURL = row["image_url"]
+			image.ImageID = row["image_id"]
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
 // AdminHandler handles admin CRUD routes
 type AdminHandler struct {
 	productService    *services.ProductService
 	categoryService   *services.CategoryService
+	colorService      *services.ColorService
+	inquiryService    *services.InquiryService
 	cloudinaryService *services.CloudinaryService
 }
 
@@ -35,11 +188,15 @@ type AdminHandler struct {
 func NewAdminHandler(
 	productService *services.ProductService,
 	categoryService *services.CategoryService,
+	colorService *services.ColorService,
+	inquiryService *services.InquiryService,
 	cloudinaryService *services.CloudinaryService,
 ) *AdminHandler {
 	return &AdminHandler{
 		productService:    productService,
 		categoryService:   categoryService,
+		colorService:      colorService,
+		inquiryService:    inquiryService,
 		cloudinaryService: cloudinaryService,
 	}
 }
@@ -62,10 +219,14 @@ func (h *AdminHandler) Dashboard(c *fiber.Ctx) error {
 	}
 	recentResult, _ := h.productService.GetAll(ctx, recentFilters)
 
+	// Most inquired products of the last 30 days with how many were won
+	conversions, _ := h.inquiryService.GetConversions(ctx, 30, 10)
+
 	return c.Render("pages/admin/dashboard", fiber.Map{
 		"Title":          "Admin Dashboard",
 		"Stats":          stats,
 		"RecentProducts": recentResult.Products,
+		"Conversions":    conversions,
 		"CSRFToken":      getCSRFToken(c),
 		"CurrentPage":    "dashboard",
 		"ContentBlock":   "admin-content-dashboard",
@@ -129,14 +290,22 @@ func (h *AdminHandler) NewProductForm(c *fiber.Ctx) error {
 		return c.Status(500).SendString("Failed to load categories")
 	}
 
+	// Palette colours for the variant colour pickers
+	colors, err := h.colorService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load colors")
+	}
+
 	return c.Render("pages/admin/product-form", fiber.Map{
-		"Title":        "Create Product",
-		"Product":      nil,
-		"Categories":   categories,
-		"IsEdit":       false,
-		"CSRFToken":    getCSRFToken(c),
-		"CurrentPage":  "products",
-		"ContentBlock": "admin-content-form",
+		"Title":              "Create Product",
+		"Product":            nil,
+		"Categories":         categories,
+		"Colors":             colors,
+		"IsEdit":             false,
+		"TranslationLocales": i18n.Translated,
+		"CSRFToken":          getCSRFToken(c),
+		"CurrentPage":        "products",
+		"ContentBlock":       "admin-content-form",
 	}, "layouts/admin")
 }
 
@@ -165,6 +334,7 @@ func (h *AdminHandler) CreateProduct(c *fiber.Ctx) error {
 		Title:       strings.TrimSpace(c.FormValue("title")),
 		Description: strings.TrimSpace(c.FormValue("description")),
 	}
+	product.Translations = parseProductTranslations(c)
 
 	// Parse category ID
 	if categoryStr := c.FormValue("category_id"); categoryStr != "" {
@@ -180,9 +350,6 @@ func (h *AdminHandler) CreateProduct(c *fiber.Ctx) error {
 		}
 	}
 
-	// Parse is_sold flag
-	product.IsSold = c.FormValue("is_sold") == "on" || c.FormValue("is_sold") == "true"
-
 	mainURL := strings.TrimSpace(c.FormValue("main_photo_url"))
 	mainPID := strings.TrimSpace(c.FormValue("main_photo_id"))
 	if mainURL != "" || mainPID != "" {
@@ -225,16 +392,12 @@ func (h *AdminHandler) CreateProduct(c *fiber.Ctx) error {
 								}
 							} else if field == "price_adjustment" && values[0] != "" {
 								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
-							} else if field == "is_sale" {
-								if values[0] == "on" || values[0] == "true" {
-									indexedVariantsMap[index][field] = "true"
-								} else {
-									indexedVariantsMap[index][field] = "false"
-								}
 							} else if field == "photo_url" && len(values) > 0 {
 								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
 							} else if field == "photo_id" && len(values) > 0 {
 								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
+							} else if field == "color_id" {
+								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
 							}
 						}
 					}
@@ -243,6 +406,11 @@ func (h *AdminHandler) CreateProduct(c *fiber.Ctx) error {
 		}
 	})
 
+	priceTiers, err := parseVariantPriceTiers(c)
+	if err != nil {
+		return c.Status(400).SendString("Invalid price tier: " + err.Error())
+	}
+
 	// Add indexed variants
 	indexedIndices := make([]int, 0, len(indexedVariantsMap))
 	for idx := range indexedVariantsMap {
@@ -256,16 +424,14 @@ func (h *AdminHandler) CreateProduct(c *fiber.Ctx) error {
 		if color == "" {
 			continue
 		}
-		variant := models.ProductVariant{Color: color}
+		variant := models.ProductVariant{Color: color, PriceTiers: priceTiers[index]}
+		variant.ColorID = parseVariantColorID(variantData["color_id"])
 		// Admin form input is treated as the stored variant final price.
 		if priceStr, ok := variantData["price_adjustment"]; ok && priceStr != "" {
 			if price, err := strconv.ParseFloat(priceStr, 64); err == nil {
 				variant.PriceAdjustment = price
 			}
 		}
-		if isSaleStr, ok := variantData["is_sale"]; ok {
-			variant.IsSale = isSaleStr == "true"
-		}
 		pu := variantData["photo_url"]
 		pid := variantData["photo_id"]
 		if pu != "" || pid != "" {
@@ -283,6 +449,12 @@ func (h *AdminHandler) CreateProduct(c *fiber.Ctx) error {
 
 	product.Variants = variants
 
+	images, err := h.parseProductImages(c, nil)
+	if err != nil {
+		return c.Status(400).SendString("Invalid gallery image: " + err.Error())
+	}
+	product.Images = images
+
 	if err := h.productService.Create(ctx, product, nil, ""); err != nil {
 		log.Printf("ERROR: Failed to create product: %v", err)
 		return c.Status(400).SendString(fmt.Sprintf("Failed to create product: %v", err))
@@ -307,6 +479,9 @@ func (h *AdminHandler) EditProductForm(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(404).SendString("Product not found")
 	}
+	if product.Translations, err = h.productService.GetTranslations(ctx, productID); err != nil {
+		return c.Status(500).SendString("Failed to load product translations")
+	}
 
 	// Get all categories
 	categories, err := h.categoryService.GetAll(ctx)
@@ -314,14 +489,22 @@ func (h *AdminHandler) EditProductForm(c *fiber.Ctx) error {
 		return c.Status(500).SendString("Failed to load categories")
 	}
 
+	// Palette colours for the variant colour pickers
+	colors, err := h.colorService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load colors")
+	}
+
 	return c.Render("pages/admin/product-form", fiber.Map{
-		"Title":        "Edit Product",
-		"Product":      product,
-		"Categories":   categories,
-		"IsEdit":       true,
-		"CSRFToken":    getCSRFToken(c),
-		"CurrentPage":  "products",
-		"ContentBlock": "admin-content-form",
+		"Title":              "Edit Product",
+		"Product":            product,
+		"Categories":         categories,
+		"Colors":             colors,
+		"IsEdit":             true,
+		"TranslationLocales": i18n.Translated,
+		"CSRFToken":          getCSRFToken(c),
+		"CurrentPage":        "products",
+		"ContentBlock":       "admin-content-form",
 	}, "layouts/admin")
 }
 
@@ -342,10 +525,11 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 		return c.Status(404).SendString("Product not found")
 	}
 
-	// Create a map of existing variants by color for photo preservation
-	existingVariantsMap := make(map[string]models.ProductVariant)
+	// Index existing variants by ID; stock and its ledger hang off the variant row,
+	// so rows posted with an ID are updated in place rather than recreated
+	existingVariantsMap := make(map[int]models.ProductVariant)
 	for _, v := range existingProduct.Variants {
-		existingVariantsMap[v.Color] = v
+		existingVariantsMap[v.ID] = v
 	}
 
 	// Parse product data
@@ -355,6 +539,7 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 		Title:       strings.TrimSpace(c.FormValue("title")),
 		Description: strings.TrimSpace(c.FormValue("description")),
 	}
+	product.Translations = parseProductTranslations(c)
 
 	// Parse category ID
 	if categoryStr := c.FormValue("category_id"); categoryStr != "" {
@@ -374,9 +559,6 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 		}
 	}
 
-	// Parse is_sold flag (Sold Out / Habis)
-	product.IsSold = c.FormValue("is_sold") == "on" || c.FormValue("is_sold") == "true"
-
 	mainURL := strings.TrimSpace(c.FormValue("main_photo_url"))
 	mainPID := strings.TrimSpace(c.FormValue("main_photo_id"))
 	if mainURL != "" || mainPID != "" {
@@ -418,16 +600,14 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 								}
 							} else if field == "price_adjustment" && values[0] != "" {
 								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
-							} else if field == "is_sale" {
-								if values[0] == "on" || values[0] == "true" {
-									indexedVariantsMap[index][field] = "true"
-								} else {
-									indexedVariantsMap[index][field] = "false"
-								}
 							} else if field == "photo_url" && len(values) > 0 {
 								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
 							} else if field == "photo_id" && len(values) > 0 {
 								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
+							} else if field == "color_id" {
+								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
+							} else if field == "id" {
+								indexedVariantsMap[index][field] = strings.TrimSpace(values[0])
 							}
 						}
 					}
@@ -436,6 +616,11 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 		}
 	})
 
+	priceTiers, err := parseVariantPriceTiers(c)
+	if err != nil {
+		return c.Status(400).SendString("Invalid price tier: " + err.Error())
+	}
+
 	// Convert indexed map to sorted slice
 	indexedIndices := make([]int, 0, len(indexedVariantsMap))
 	for idx := range indexedVariantsMap {
@@ -452,7 +637,25 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 		}
 
 		variant := models.ProductVariant{
-			Color: color,
+			ProductID:  productID,
+			Color:      color,
+			ColorID:    parseVariantColorID(variantData["color_id"]),
+			PriceTiers: priceTiers[index],
+		}
+
+		// Keep the variant identity for rows that already exist
+		var existingVariant models.ProductVariant
+		var exists bool
+		if idStr := variantData["id"]; idStr != "" {
+			id, err := strconv.Atoi(idStr)
+			if err != nil {
+				return c.Status(400).SendString(fmt.Sprintf("Variant %q has an invalid ID", color))
+			}
+			existingVariant, exists = existingVariantsMap[id]
+			if !exists {
+				return c.Status(400).SendString(fmt.Sprintf("Variant %q does not belong to this product", color))
+			}
+			variant.ID = id
 		}
 
 		// Admin form input is treated as the stored variant final price.
@@ -462,11 +665,6 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 			}
 		}
 
-		// Parse is_sale flag
-		if isSaleStr, ok := variantData["is_sale"]; ok {
-			variant.IsSale = isSaleStr == "true"
-		}
-
 		pu := variantData["photo_url"]
 		pid := variantData["photo_id"]
 		if pu != "" || pid != "" {
@@ -478,7 +676,7 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 			}
 			variant.PhotoURL = pu
 			variant.PhotoID = pid
-		} else if existingVariant, exists := existingVariantsMap[color]; exists {
+		} else if exists {
 			variant.PhotoURL = existingVariant.PhotoURL
 			variant.PhotoID = existingVariant.PhotoID
 		}
@@ -488,6 +686,12 @@ func (h *AdminHandler) UpdateProduct(c *fiber.Ctx) error {
 
 	product.Variants = variants
 
+	images, err := h.parseProductImages(c, existingProduct.Images)
+	if err != nil {
+		return c.Status(400).SendString("Invalid gallery image: " + err.Error())
+	}
+	product.Images = images
+
 	err = h.productService.Update(ctx, productID, product, nil, "")
 	if err != nil {
 		return c.Status(400).SendString(fmt.Sprintf("Failed to update product: %v", err))
@@ -534,8 +738,15 @@ func (h *AdminHandler) getStats(ctx context.Context) (map[string]interface{}, er
 		return nil, err
 	}
 
+	// Get inquiries waiting for a reply
+	inquiryCounts, err := h.inquiryService.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
 		"TotalProducts":   productsResult.Total,
 		"TotalCategories": len(categories),
+		"NewInquiries":    inquiryCounts[models.InquiryNew],
 	}, nil
 }
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// basketCookie holds the visitor's basket token
+const basketCookie = "basket"
+
+// BasketHandler handles the public inquiry basket routes
+type BasketHandler struct {
+	basketService  *services.BasketService
+	inquiryService *services.InquiryService
+	env            string
+}
+
+// NewBasketHandler creates a new basket handler
+func NewBasketHandler(basketService *services.BasketService, inquiryService *services.InquiryService, env string) *BasketHandler {
+	return &BasketHandler{
+		basketService:  basketService,
+		inquiryService: inquiryService,
+		env:            env,
+	}
+}
+
+// ShowBasket renders the basket page with the checkout form
+func (h *BasketHandler) ShowBasket(c *fiber.Ctx) error {
+	basket, err := h.basketService.Get(c.UserContext(), c.Cookies(basketCookie))
+	if err != nil {
+		return c.Status(500).SendString("Failed to load basket")
+	}
+
+	return c.Render("pages/basket", fiber.Map{
+		"Title":        "Keranjang",
+		"ContentBlock": "basket-content",
+		"Basket":       basket,
+	}, "layouts/base")
+}
+
+// Badge renders the header basket link with the number of units (htmx partial)
+func (h *BasketHandler) Badge(c *fiber.Ctx) error {
+	basket, err := h.basketService.Get(c.UserContext(), c.Cookies(basketCookie))
+	if err != nil {
+		return c.Status(500).SendString("Failed to load basket")
+	}
+
+	return c.Render("partials/basket-badge", fiber.Map{
+		"Count": basket.ItemCount(),
+	})
+}
+
+// AddItem adds a product (and variant) to the basket from the grid or the product page.
+// htmx requests get a toast and trigger basket-updated; other requests go to the basket.
+func (h *BasketHandler) AddItem(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	productID, _ := strconv.Atoi(c.FormValue("product_id"))
+	var variantID *int
+	if id, err := strconv.Atoi(c.FormValue("variant_id")); err == nil && id > 0 {
+		variantID = &id
+	}
+	quantity, err := strconv.Atoi(c.FormValue("quantity", "1"))
+	if err != nil {
+		quantity = 0
+	}
+
+	token, err := h.basketService.Add(ctx, c.Cookies(basketCookie), productID, variantID, quantity)
+	if token != "" {
+		h.setCookie(c, token)
+	}
+
+	if c.Get("HX-Request") != "true" {
+		if err != nil {
+			return c.Status(400).SendString(basketErrorMessage(c, err))
+		}
+		return c.Redirect(localePath(c, "/keranjang"))
+	}
+
+	if err != nil {
+		return c.Render("partials/basket-toast", fiber.Map{
+			"Error": basketErrorMessage(c, err),
+		})
+	}
+
+	c.Set("HX-Trigger", "basket-updated")
+	return c.Render("partials/basket-toast", fiber.Map{
+		"Message": "Ditambahkan ke keranjang",
+	})
+}
+
+// UpdateItem changes a line quantity and re-renders the basket lines (htmx partial)
+func (h *BasketHandler) UpdateItem(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	itemID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || itemID <= 0 {
+		return c.Status(400).SendString("Invalid basket item")
+	}
+	quantity, err := strconv.Atoi(c.FormValue("quantity"))
+	if err != nil {
+		return c.Status(400).SendString(basketErrorMessage(c, services.ErrBasketInvalidQuantity))
+	}
+
+	if err := h.basketService.UpdateQuantity(ctx, c.Cookies(basketCookie), itemID, quantity); err != nil {
+		return c.Status(400).SendString(basketErrorMessage(c, err))
+	}
+
+	return h.renderLines(c)
+}
+
+// RemoveItem deletes a line and re-renders the basket lines (htmx partial)
+func (h *BasketHandler) RemoveItem(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	itemID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || itemID <= 0 {
+		return c.Status(400).SendString("Invalid basket item")
+	}
+
+	if err := h.basketService.Remove(ctx, c.Cookies(basketCookie), itemID); err != nil {
+		return c.Status(400).SendString(basketErrorMessage(c, err))
+	}
+
+	return h.renderLines(c)
+}
+
+// Checkout records the basket lines as inquiries, composes one WhatsApp message
+// for the whole basket and opens the chat
+func (h *BasketHandler) Checkout(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	basket, err := h.basketService.Get(ctx, c.Cookies(basketCookie))
+	if err != nil {
+		return c.Status(500).SendString("Failed to load basket")
+	}
+	if len(basket.Lines) == 0 {
+		return c.Redirect(localePath(c, "/keranjang"))
+	}
+
+	// Best effort: a lead that failed to save must not keep the visitor from the chat
+	_ = h.inquiryService.RecordBasket(ctx, basket, c.Get("Referer"))
+
+	return c.Redirect(h.basketService.WhatsAppURL(ctx, basket, c.FormValue("name"), c.FormValue("note")))
+}
+
+// renderLines renders the basket lines and totals, refreshing the header badge
+func (h *BasketHandler) renderLines(c *fiber.Ctx) error {
+	basket, err := h.basketService.Get(c.UserContext(), c.Cookies(basketCookie))
+	if err != nil {
+		return c.Status(500).SendString("Failed to load basket")
+	}
+
+	c.Set("HX-Trigger", "basket-updated")
+	return c.Render("partials/basket-lines", fiber.Map{
+		"Basket": basket,
+	})
+}
+
+// setCookie keeps the basket token for BasketLifetime after the last change
+func (h *BasketHandler) setCookie(c *fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     basketCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  time.Now().Add(services.BasketLifetime),
+		HTTPOnly: true,
+		Secure:   h.env == "production",
+		SameSite: "Lax", // Sent when arriving from a shared link, not on cross-site posts
+	})
+}
+
+// basketErrorMessage returns the shopper-facing text for a basket error, in the
+// shopper's language
+func basketErrorMessage(c *fiber.Ctx, err error) string {
+	locale := i18n.FromContext(c.UserContext())
+	switch {
+	case errors.Is(err, services.ErrBasketVariantRequired):
+		return i18n.T(locale, "Pilih varian terlebih dahulu")
+	case errors.Is(err, services.ErrBasketSoldOut):
+		return i18n.T(locale, "Maaf, stok produk ini sedang habis")
+	case errors.Is(err, services.ErrBasketInvalidQuantity):
+		return i18n.T(locale, "Jumlah harus antara 1 dan %d", models.MaxBasketQuantity)
+	case errors.Is(err, services.ErrBasketProductNotFound):
+		return i18n.T(locale, "Produk tidak ditemukan")
+	case errors.Is(err, services.ErrBasketItemNotFound):
+		return i18n.T(locale, "Barang tidak ada di keranjang")
+	}
+	return i18n.T(locale, "Gagal memperbarui keranjang, silakan coba lagi")
+}
@@ -3,38 +3,45 @@ package handlers
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
+	"github.com/rizkysr90/aslam-flower/internal/models"
 	"github.com/rizkysr90/aslam-flower/internal/repositories"
 	"github.com/rizkysr90/aslam-flower/internal/services"
 )
 
 // CategoryHandler handles category CRUD routes
 type CategoryHandler struct {
-	categoryService *services.CategoryService
-	categoryRepo    *repositories.CategoryRepository
+	categoryService   *services.CategoryService
+	categoryRepo      *repositories.CategoryRepository
+	cloudinaryService *services.CloudinaryService
 }
 
 // NewCategoryHandler creates a new category handler
 func NewCategoryHandler(
 	categoryService *services.CategoryService,
 	categoryRepo *repositories.CategoryRepository,
+	cloudinaryService *services.CloudinaryService,
 ) *CategoryHandler {
 	return &CategoryHandler{
-		categoryService: categoryService,
-		categoryRepo:    categoryRepo,
+		categoryService:   categoryService,
+		categoryRepo:      categoryRepo,
+		cloudinaryService: cloudinaryService,
 	}
 }
 
-// ListCategories renders the categories list page
+// ListCategories renders the category tree
 func (h *CategoryHandler) ListCategories(c *fiber.Ctx) error {
 	ctx := c.Context()
 
-	// Get all categories
-	categories, err := h.categoryService.GetAll(ctx)
+	// Get all categories in tree order
+	tree, err := h.categoryService.GetTree(ctx)
 	if err != nil {
 		return c.Status(500).SendString("Failed to load categories")
 	}
+	categories := models.FlattenCategoryTree(tree)
 
 	// Get product count for each category and create map for template
 	type CategoryWithCount struct {
@@ -50,9 +57,10 @@ func (h *CategoryHandler) ListCategories(c *fiber.Ctx) error {
 			count = 0
 		}
 		catMap := map[string]interface{}{
-			"ID":   category.ID,
-			"Name": category.Name,
-			"Slug": category.Slug,
+			"ID":    category.ID,
+			"Name":  category.Name,
+			"Slug":  category.Slug,
+			"Depth": category.Depth(),
 		}
 		categoriesWithCounts = append(categoriesWithCounts, CategoryWithCount{
 			Category:     catMap,
@@ -73,16 +81,9 @@ func (h *CategoryHandler) ListCategories(c *fiber.Ctx) error {
 	}, "layouts/admin")
 }
 
-// NewCategoryForm renders the category creation form
+// NewCategoryForm renders the category creation form; ?parent=ID preselects the parent
 func (h *CategoryHandler) NewCategoryForm(c *fiber.Ctx) error {
-	return c.Render("pages/admin/category-form", fiber.Map{
-		"Title":        "Add Category",
-		"Category":     nil,
-		"IsEdit":       false,
-		"CSRFToken":    getCSRFToken(c),
-		"CurrentPage":  "categories",
-		"ContentBlock": "admin-content-category-form",
-	}, "layouts/admin")
+	return h.renderForm(c, nil, parseParentID(c.Query("parent")), "")
 }
 
 // CreateCategory handles category creation
@@ -90,31 +91,18 @@ func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
 	ctx := c.Context()
 
 	// Parse form data
-	name := c.FormValue("name")
-	if name == "" {
-		return c.Render("pages/admin/category-form", fiber.Map{
-			"Title":        "Add Category",
-			"Category":     nil,
-			"IsEdit":       false,
-			"Error":        "Category name is required",
-			"CSRFToken":    getCSRFToken(c),
-			"CurrentPage":  "categories",
-			"ContentBlock": "admin-content-category-form",
-		}, "layouts/admin")
+	input, err := h.parseCategoryForm(c, nil)
+	if err != nil {
+		return h.renderForm(c, nil, input.ParentID, err.Error())
+	}
+	if input.Name == "" {
+		return h.renderForm(c, nil, input.ParentID, "Category name is required")
 	}
 
 	// Create category
-	category, err := h.categoryService.Create(ctx, name)
+	category, err := h.categoryService.Create(ctx, input)
 	if err != nil {
-		return c.Render("pages/admin/category-form", fiber.Map{
-			"Title":        "Add Category",
-			"Category":     nil,
-			"IsEdit":       false,
-			"Error":        err.Error(),
-			"CSRFToken":    getCSRFToken(c),
-			"CurrentPage":  "categories",
-			"ContentBlock": "admin-content-category-form",
-		}, "layouts/admin")
+		return h.renderForm(c, nil, input.ParentID, err.Error())
 	}
 
 	// Redirect with success message
@@ -138,14 +126,7 @@ func (h *CategoryHandler) EditCategoryForm(c *fiber.Ctx) error {
 		return c.Status(404).SendString("Category not found")
 	}
 
-	return c.Render("pages/admin/category-form", fiber.Map{
-		"Title":        "Edit Category",
-		"Category":     category,
-		"IsEdit":       true,
-		"CSRFToken":    getCSRFToken(c),
-		"CurrentPage":  "categories",
-		"ContentBlock": "admin-content-category-form",
-	}, "layouts/admin")
+	return h.renderForm(c, category, category.ParentID, "")
 }
 
 // UpdateCategory handles category update
@@ -159,42 +140,127 @@ func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
 		return c.Status(404).SendString("Category not found")
 	}
 
+	// Get category for re-rendering
+	existingCategory, err := h.categoryService.GetByID(ctx, categoryID)
+	if err != nil {
+		return c.Status(404).SendString("Category not found")
+	}
+
 	// Parse form data
-	name := c.FormValue("name")
-	if name == "" {
-		// Get category for re-rendering
-		category, _ := h.categoryService.GetByID(ctx, categoryID)
-		return c.Render("pages/admin/category-form", fiber.Map{
-			"Title":        "Edit Category",
-			"Category":     category,
-			"IsEdit":       true,
-			"Error":        "Category name is required",
-			"CSRFToken":    getCSRFToken(c),
-			"CurrentPage":  "categories",
-			"ContentBlock": "admin-content-category-form",
-		}, "layouts/admin")
+	input, err := h.parseCategoryForm(c, existingCategory)
+	if err != nil {
+		return h.renderForm(c, existingCategory, input.ParentID, err.Error())
+	}
+	if input.Name == "" {
+		return h.renderForm(c, existingCategory, input.ParentID, "Category name is required")
 	}
 
 	// Update category
-	category, err := h.categoryService.Update(ctx, categoryID, name)
+	category, err := h.categoryService.Update(ctx, categoryID, input)
 	if err != nil {
-		// Get category for re-rendering
-		existingCategory, _ := h.categoryService.GetByID(ctx, categoryID)
-		return c.Render("pages/admin/category-form", fiber.Map{
-			"Title":        "Edit Category",
-			"Category":     existingCategory,
-			"IsEdit":       true,
-			"Error":        err.Error(),
-			"CSRFToken":    getCSRFToken(c),
-			"CurrentPage":  "categories",
-			"ContentBlock": "admin-content-category-form",
-		}, "layouts/admin")
+		return h.renderForm(c, existingCategory, input.ParentID, err.Error())
 	}
 
 	// Redirect with success message
 	return c.Redirect(fmt.Sprintf("/admin/categories?success=Category '%%27%s%%27 updated successfully", category.Name))
 }
 
+// parseCategoryForm reads the submitted category. A banner is either the existing one,
+// removed, or a fresh client direct upload to the categories folder. The returned
+// category is always non-nil so the form can be re-rendered on error.
+func (h *CategoryHandler) parseCategoryForm(c *fiber.Ctx, existing *models.Category) (*models.Category, error) {
+	input := &models.Category{
+		Name:        c.FormValue("name"),
+		ParentID:    parseParentID(c.FormValue("parent_id")),
+		Title:       c.FormValue("title"),
+		Description: c.FormValue("description"),
+	}
+	for _, locale := range i18n.Translated {
+		field := "translations[" + locale.String() + "]"
+		input.Translations = append(input.Translations, models.CategoryTranslation{
+			Locale:      locale.String(),
+			Name:        c.FormValue(field + "[name]"),
+			Title:       c.FormValue(field + "[title]"),
+			Description: c.FormValue(field + "[description]"),
+		})
+	}
+
+	if c.FormValue("remove_banner") == "on" {
+		return input, nil
+	}
+
+	bannerURL := strings.TrimSpace(c.FormValue("banner_url"))
+	bannerID := strings.TrimSpace(c.FormValue("banner_id"))
+	switch {
+	case bannerURL == "" && bannerID == "":
+	case existing != nil && bannerID == existing.BannerID:
+		input.BannerURL = existing.BannerURL
+		input.BannerID = existing.BannerID
+	default:
+		if err := h.cloudinaryService.ValidateClientUploadResult("category", bannerURL, bannerID); err != nil {
+			return input, fmt.Errorf("invalid banner: %w", err)
+		}
+		input.BannerURL = bannerURL
+		input.BannerID = bannerID
+	}
+
+	return input, nil
+}
+
+// renderForm renders the category form. The parent picker lists the tree in order,
+// leaving out the edited category and its subtree so a cycle cannot be chosen.
+func (h *CategoryHandler) renderForm(c *fiber.Ctx, category *models.Category, parentID *int, errMsg string) error {
+	ctx := c.Context()
+
+	tree, err := h.categoryService.GetTree(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load categories")
+	}
+
+	parentOptions := make([]models.Category, 0)
+	for _, option := range models.FlattenCategoryTree(tree) {
+		if category != nil && (option.ID == category.ID || option.IsDescendantOf(category)) {
+			continue
+		}
+		parentOptions = append(parentOptions, option)
+	}
+
+	selectedParentID := 0
+	if parentID != nil {
+		selectedParentID = *parentID
+	}
+
+	title := "Add Category"
+	if category != nil {
+		title = "Edit Category"
+		if category.Translations, err = h.categoryService.GetTranslations(ctx, category.ID); err != nil {
+			return c.Status(500).SendString("Failed to load category translations")
+		}
+	}
+
+	return c.Render("pages/admin/category-form", fiber.Map{
+		"Title":              title,
+		"Category":           category,
+		"IsEdit":             category != nil,
+		"Error":              errMsg,
+		"ParentOptions":      parentOptions,
+		"SelectedParentID":   selectedParentID,
+		"TranslationLocales": i18n.Translated,
+		"CSRFToken":          getCSRFToken(c),
+		"CurrentPage":        "categories",
+		"ContentBlock":       "admin-content-category-form",
+	}, "layouts/admin")
+}
+
+// parseParentID reads an optional parent category ID; empty or invalid means top level
+func parseParentID(value string) *int {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil
+	}
+	return &id
+}
+
 // DeleteCategory handles category deletion (htmx)
 func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
 	ctx := c.Context()
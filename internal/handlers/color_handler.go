@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// ColorHandler handles admin colour palette routes
+type ColorHandler struct {
+	colorService *services.ColorService
+}
+
+// NewColorHandler creates a new color handler
+func NewColorHandler(colorService *services.ColorService) *ColorHandler {
+	return &ColorHandler{
+		colorService: colorService,
+	}
+}
+
+// ListColors renders the palette with the merge tool
+func (h *ColorHandler) ListColors(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	colors, err := h.colorService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load colors")
+	}
+
+	return c.Render("pages/admin/colors", fiber.Map{
+		"Title":        "Colors",
+		"Colors":       colors,
+		"Success":      c.Query("success", ""),
+		"Error":        c.Query("error", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "colors",
+		"ContentBlock": "admin-content-colors",
+	}, "layouts/admin")
+}
+
+// NewColorForm renders the colour creation form
+func (h *ColorHandler) NewColorForm(c *fiber.Ctx) error {
+	return h.renderForm(c, &models.Color{Hex: "#ffffff", Family: models.ColorFamilies[0].Key}, false, "")
+}
+
+// CreateColor handles colour creation
+func (h *ColorHandler) CreateColor(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	color := parseColorForm(c)
+	if err := h.colorService.Create(ctx, color); err != nil {
+		return h.renderForm(c, color, false, err.Error())
+	}
+
+	return c.Redirect("/admin/colors?success=" + url.QueryEscape(fmt.Sprintf("Color '%s' created successfully", color.Name)))
+}
+
+// EditColorForm renders the colour edit form
+func (h *ColorHandler) EditColorForm(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	colorID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || colorID <= 0 {
+		return c.Status(404).SendString("Color not found")
+	}
+
+	color, err := h.colorService.GetByID(ctx, colorID)
+	if err != nil {
+		return c.Status(404).SendString("Color not found")
+	}
+
+	return h.renderForm(c, color, true, "")
+}
+
+// UpdateColor handles colour update
+func (h *ColorHandler) UpdateColor(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	colorID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || colorID <= 0 {
+		return c.Status(404).SendString("Color not found")
+	}
+
+	color := parseColorForm(c)
+	color.ID = colorID
+	if err := h.colorService.Update(ctx, colorID, color); err != nil {
+		return h.renderForm(c, color, true, err.Error())
+	}
+
+	return c.Redirect("/admin/colors?success=" + url.QueryEscape(fmt.Sprintf("Color '%s' updated successfully", color.Name)))
+}
+
+// MergeColors folds a duplicate colour into another one
+func (h *ColorHandler) MergeColors(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	sourceID, _ := strconv.Atoi(c.FormValue("source_id"))
+	targetID, _ := strconv.Atoi(c.FormValue("target_id"))
+
+	target, err := h.colorService.Merge(ctx, sourceID, targetID)
+	if err != nil {
+		return c.Redirect("/admin/colors?error=" + url.QueryEscape(err.Error()))
+	}
+
+	return c.Redirect("/admin/colors?success=" + url.QueryEscape(fmt.Sprintf("Colors merged into '%s'", target.Name)))
+}
+
+// DeleteColor handles colour deletion
+func (h *ColorHandler) DeleteColor(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	colorID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || colorID <= 0 {
+		return c.Status(400).SendString("Invalid color ID")
+	}
+
+	if err := h.colorService.Delete(ctx, colorID); err != nil {
+		return c.Status(500).SendString(fmt.Sprintf("Failed to delete color: %v", err))
+	}
+
+	return c.Redirect("/admin/colors?success=" + url.QueryEscape("Color deleted successfully"))
+}
+
+// parseColorForm reads a palette colour from the submitted form; aliases are comma-separated
+func parseColorForm(c *fiber.Ctx) *models.Color {
+	color := &models.Color{
+		Name:    strings.TrimSpace(c.FormValue("name")),
+		Hex:     strings.TrimSpace(c.FormValue("hex")),
+		Family:  c.FormValue("family"),
+		Aliases: pq.StringArray{},
+	}
+	for _, alias := range strings.Split(c.FormValue("aliases"), ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			color.Aliases = append(color.Aliases, alias)
+		}
+	}
+	return color
+}
+
+// renderForm renders the colour form
+func (h *ColorHandler) renderForm(c *fiber.Ctx, color *models.Color, isEdit bool, errMsg string) error {
+	title := "Add Color"
+	if isEdit {
+		title = "Edit Color"
+	}
+
+	return c.Render("pages/admin/color-form", fiber.Map{
+		"Title":        title,
+		"Color":        color,
+		"Aliases":      strings.Join(color.Aliases, ", "),
+		"Families":     models.ColorFamilies,
+		"IsEdit":       isEdit,
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "colors",
+		"ContentBlock": "admin-content-color-form",
+	}, "layouts/admin")
+}
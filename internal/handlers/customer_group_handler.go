@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// groupPriceField matches prices[N][variant_id|unit_price|label] form keys
+var groupPriceField = regexp.MustCompile(`^prices\[(\d+)\]\[(variant_id|unit_price|label)\]$`)
+
+// CustomerGroupHandler handles admin customer group routes
+type CustomerGroupHandler struct {
+	groupService *services.CustomerGroupService
+}
+
+// NewCustomerGroupHandler creates a new customer group handler
+func NewCustomerGroupHandler(groupService *services.CustomerGroupService) *CustomerGroupHandler {
+	return &CustomerGroupHandler{
+		groupService: groupService,
+	}
+}
+
+// ListGroups renders the customer groups
+func (h *CustomerGroupHandler) ListGroups(c *fiber.Ctx) error {
+	groups, err := h.groupService.GetAll(c.Context())
+	if err != nil {
+		return c.Status(500).SendString("Failed to load customer groups")
+	}
+
+	return c.Render("pages/admin/customer-groups", fiber.Map{
+		"Title":        "Customer Groups",
+		"Groups":       groups,
+		"Success":      c.Query("success", ""),
+		"Error":        c.Query("error", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "customers",
+		"ContentBlock": "admin-content-customer-groups",
+	}, "layouts/admin")
+}
+
+// NewGroupForm renders the customer group creation form
+func (h *CustomerGroupHandler) NewGroupForm(c *fiber.Ctx) error {
+	return h.renderForm(c, &models.CustomerGroup{}, false, "")
+}
+
+// CreateGroup handles customer group creation
+func (h *CustomerGroupHandler) CreateGroup(c *fiber.Ctx) error {
+	group := parseCustomerGroupForm(c)
+	if err := h.groupService.Create(c.Context(), group); err != nil {
+		return h.renderForm(c, group, false, err.Error())
+	}
+
+	return c.Redirect("/admin/customer-groups?success=" + url.QueryEscape(fmt.Sprintf("Group '%s' created successfully", group.Name)))
+}
+
+// EditGroupForm renders the customer group edit form with its price list
+func (h *CustomerGroupHandler) EditGroupForm(c *fiber.Ctx) error {
+	groupID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || groupID <= 0 {
+		return c.Status(404).SendString("Customer group not found")
+	}
+
+	group, err := h.groupService.GetByID(c.Context(), groupID)
+	if err != nil {
+		return c.Status(404).SendString("Customer group not found")
+	}
+
+	return h.renderForm(c, group, true, "")
+}
+
+// UpdateGroup handles customer group update
+func (h *CustomerGroupHandler) UpdateGroup(c *fiber.Ctx) error {
+	groupID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || groupID <= 0 {
+		return c.Status(404).SendString("Customer group not found")
+	}
+
+	group := parseCustomerGroupForm(c)
+	group.ID = groupID
+	if err := h.groupService.Update(c.Context(), groupID, group); err != nil {
+		return h.renderForm(c, group, true, err.Error())
+	}
+
+	return c.Redirect("/admin/customer-groups?success=" + url.QueryEscape(fmt.Sprintf("Group '%s' updated successfully", group.Name)))
+}
+
+// DeleteGroup handles customer group deletion
+func (h *CustomerGroupHandler) DeleteGroup(c *fiber.Ctx) error {
+	groupID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || groupID <= 0 {
+		return c.Status(400).SendString("Invalid customer group ID")
+	}
+
+	if err := h.groupService.Delete(c.Context(), groupID); err != nil {
+		return c.Redirect("/admin/customer-groups?error=" + url.QueryEscape(err.Error()))
+	}
+
+	return c.Redirect("/admin/customer-groups?success=" + url.QueryEscape("Group deleted successfully"))
+}
+
+// parseCustomerGroupForm reads the group fields and its price rows in row order.
+// Rows without a variant or a price are ignored.
+func parseCustomerGroupForm(c *fiber.Ctx) *models.CustomerGroup {
+	group := &models.CustomerGroup{
+		Name:        c.FormValue("name"),
+		Description: c.FormValue("description"),
+		Prices:      []models.CustomerGroupPrice{},
+	}
+	group.DiscountPercent, _ = strconv.ParseFloat(strings.TrimSpace(c.FormValue("discount_percent")), 64)
+
+	rows := make(map[int]map[string]string)
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		m := groupPriceField.FindStringSubmatch(string(key))
+		if m == nil {
+			return
+		}
+		index, _ := strconv.Atoi(m[1])
+		if rows[index] == nil {
+			rows[index] = make(map[string]string)
+		}
+		rows[index][m[2]] = strings.TrimSpace(string(value))
+	})
+
+	indexes := make([]int, 0, len(rows))
+	for index := range rows {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		row := rows[index]
+		variantID, err := strconv.Atoi(row["variant_id"])
+		if err != nil || variantID <= 0 {
+			continue
+		}
+		unitPrice, err := strconv.ParseFloat(row["unit_price"], 64)
+		if err != nil {
+			continue
+		}
+		// The picked label is kept so the row reads the same if the form is shown again
+		group.Prices = append(group.Prices, models.CustomerGroupPrice{
+			VariantID:    variantID,
+			UnitPrice:    unitPrice,
+			ProductTitle: row["label"],
+		})
+	}
+
+	return group
+}
+
+// renderForm renders the customer group form
+func (h *CustomerGroupHandler) renderForm(c *fiber.Ctx, group *models.CustomerGroup, isEdit bool, errMsg string) error {
+	title := "Add Customer Group"
+	if isEdit {
+		title = "Edit Customer Group"
+	}
+
+	return c.Render("pages/admin/customer-group-form", fiber.Map{
+		"Title":        title,
+		"Group":        group,
+		"IsEdit":       isEdit,
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "customers",
+		"ContentBlock": "admin-content-customer-group-form",
+	}, "layouts/admin")
+}
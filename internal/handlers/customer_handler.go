@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/middleware"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// CustomerHandler handles reseller sign-up and sign-in on the public site and the
+// admin review of customer accounts
+type CustomerHandler struct {
+	customerService *services.CustomerService
+	groupService    *services.CustomerGroupService
+	wishlistService *services.WishlistService
+	env             string
+}
+
+// NewCustomerHandler creates a new customer handler
+func NewCustomerHandler(customerService *services.CustomerService, groupService *services.CustomerGroupService, wishlistService *services.WishlistService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+		groupService:    groupService,
+		wishlistService: wishlistService,
+		env:             os.Getenv("ENV"),
+	}
+}
+
+// RegisterPage renders the reseller application form
+func (h *CustomerHandler) RegisterPage(c *fiber.Ctx) error {
+	if currentCustomer(c) != nil {
+		return c.Redirect(localePath(c, "/reseller/akun"))
+	}
+
+	return h.renderRegister(c, services.CustomerRegistration{}, "")
+}
+
+// Register stores a reseller application and tells the visitor to wait for approval
+func (h *CustomerHandler) Register(c *fiber.Ctx) error {
+	input := services.CustomerRegistration{
+		Name:         c.FormValue("name"),
+		BusinessName: c.FormValue("business_name"),
+		Phone:        c.FormValue("phone"),
+		City:         c.FormValue("city"),
+		Password:     c.FormValue("password"),
+	}
+
+	if _, err := h.customerService.Register(c.UserContext(), input); err != nil {
+		input.Password = ""
+		return h.renderRegister(c, input, customerErrorMessage(err))
+	}
+
+	return c.Redirect(localePath(c, "/reseller/masuk?registered=1"))
+}
+
+// LoginPage renders the reseller sign-in form
+func (h *CustomerHandler) LoginPage(c *fiber.Ctx) error {
+	if currentCustomer(c) != nil {
+		return c.Redirect(localePath(c, "/reseller/akun"))
+	}
+
+	success := ""
+	if c.Query("registered") != "" {
+		success = "Pendaftaran terkirim. Kami akan menghubungi Anda lewat WhatsApp setelah akun disetujui."
+	}
+
+	return h.renderLogin(c, "", "", success)
+}
+
+// Login signs in an approved reseller, moves the wishlist and recently viewed
+// products saved before signing in to their account and returns to the catalog
+func (h *CustomerHandler) Login(c *fiber.Ctx) error {
+	phone := c.FormValue("phone")
+
+	customer, token, err := h.customerService.Login(c.UserContext(), phone, c.FormValue("password"))
+	if err != nil {
+		return h.renderLogin(c, phone, customerErrorMessage(err), "")
+	}
+
+	// Best effort: lists that failed to move stay with the visitor for the next sign-in
+	_ = h.wishlistService.Merge(c.UserContext(), c.Cookies(visitorCookie), customer.ID)
+
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.CustomerCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(services.CustomerTokenExpiry.Seconds()),
+		HTTPOnly: true,
+		Secure:   h.env == "production",
+		SameSite: "Lax", // Kept when arriving from a shared product link
+	})
+
+	return c.Redirect(localePath(c, "/"))
+}
+
+// Logout signs the reseller out
+func (h *CustomerHandler) Logout(c *fiber.Ctx) error {
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.CustomerCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1, // Delete cookie
+		HTTPOnly: true,
+		Secure:   h.env == "production",
+		SameSite: "Lax",
+	})
+
+	return c.Redirect(localePath(c, "/"))
+}
+
+// Account renders the signed-in reseller's account page
+func (h *CustomerHandler) Account(c *fiber.Ctx) error {
+	customer := currentCustomer(c)
+	if customer == nil {
+		return c.Redirect(localePath(c, "/reseller/masuk"))
+	}
+
+	return c.Render("pages/reseller-account", fiber.Map{
+		"Title":        "Akun Reseller",
+		"ContentBlock": "reseller-account-content",
+		"Customer":     customer,
+	}, "layouts/base")
+}
+
+// Badge renders the header account link (htmx partial)
+func (h *CustomerHandler) Badge(c *fiber.Ctx) error {
+	return c.Render("partials/account-badge", fiber.Map{
+		"Customer": currentCustomer(c),
+	})
+}
+
+// ListCustomers renders the customer accounts with status tabs and search
+func (h *CustomerHandler) ListCustomers(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := repositories.CustomerFilters{
+		Status:      c.Query("status"),
+		GroupID:     c.QueryInt("group_id", 0),
+		SearchQuery: strings.TrimSpace(c.Query("search")),
+		Page:        c.QueryInt("page", 1),
+		PageSize:    50,
+	}
+
+	result, err := h.customerService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load customers")
+	}
+
+	counts, err := h.customerService.CountByStatus(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load customers")
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	groups, err := h.groupService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load customer groups")
+	}
+
+	return c.Render("pages/admin/customers", fiber.Map{
+		"Title":        "Customers",
+		"Customers":    result.Customers,
+		"Status":       filters.Status,
+		"GroupID":      filters.GroupID,
+		"SearchQuery":  filters.SearchQuery,
+		"Statuses":     models.CustomerStatuses,
+		"StatusCounts": counts,
+		"TotalCount":   total,
+		"Groups":       groups,
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+		"Success":      c.Query("success", ""),
+		"Error":        c.Query("error", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "customers",
+		"ContentBlock": "admin-content-customers",
+	}, "layouts/admin")
+}
+
+// ShowCustomer renders a customer account with the review form
+func (h *CustomerHandler) ShowCustomer(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	customerID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || customerID <= 0 {
+		return c.Status(404).SendString("Customer not found")
+	}
+
+	customer, err := h.customerService.GetByID(ctx, customerID)
+	if err != nil {
+		return c.Status(404).SendString("Customer not found")
+	}
+
+	return h.renderCustomer(c, customer, c.Query("error", ""))
+}
+
+// ReviewCustomer saves the admin decision on an account: status, group and notes
+func (h *CustomerHandler) ReviewCustomer(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	customerID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || customerID <= 0 {
+		return c.Status(404).SendString("Customer not found")
+	}
+
+	review := services.CustomerReview{
+		Status:     models.CustomerStatus(c.FormValue("status")),
+		AdminNotes: c.FormValue("admin_notes"),
+	}
+	if groupID, err := strconv.Atoi(c.FormValue("group_id")); err == nil && groupID > 0 {
+		review.GroupID = &groupID
+	}
+
+	customer, err := h.customerService.Review(ctx, customerID, review)
+	if err != nil {
+		return c.Redirect("/admin/customers/" + strconv.Itoa(customerID) + "?error=" + url.QueryEscape(err.Error()))
+	}
+
+	return c.Redirect("/admin/customers/" + strconv.Itoa(customer.ID) + "?success=" + url.QueryEscape("Customer account updated"))
+}
+
+// renderCustomer renders the customer detail page
+func (h *CustomerHandler) renderCustomer(c *fiber.Ctx, customer *models.Customer, errMsg string) error {
+	groups, err := h.groupService.GetAll(c.Context())
+	if err != nil {
+		return c.Status(500).SendString("Failed to load customer groups")
+	}
+
+	return c.Render("pages/admin/customer-detail", fiber.Map{
+		"Title":        customer.Name,
+		"Customer":     customer,
+		"Groups":       groups,
+		"Statuses":     models.CustomerStatuses,
+		"Success":      c.Query("success", ""),
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "customers",
+		"ContentBlock": "admin-content-customer-detail",
+	}, "layouts/admin")
+}
+
+// renderRegister renders the reseller application form
+func (h *CustomerHandler) renderRegister(c *fiber.Ctx, input services.CustomerRegistration, errMsg string) error {
+	return c.Render("pages/reseller-register", fiber.Map{
+		"Title":        "Daftar Reseller",
+		"ContentBlock": "reseller-register-content",
+		"Input":        input,
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+	}, "layouts/base")
+}
+
+// renderLogin renders the reseller sign-in form
+func (h *CustomerHandler) renderLogin(c *fiber.Ctx, phone, errMsg, success string) error {
+	return c.Render("pages/reseller-login", fiber.Map{
+		"Title":        "Masuk Reseller",
+		"ContentBlock": "reseller-login-content",
+		"Phone":        phone,
+		"Error":        errMsg,
+		"Success":      success,
+		"CSRFToken":    getCSRFToken(c),
+	}, "layouts/base")
+}
+
+// currentCustomer returns the reseller signed in for this request, or nil
+func currentCustomer(c *fiber.Ctx) *models.Customer {
+	customer, _ := c.Locals("customer").(*models.Customer)
+	return customer
+}
+
+// customerErrorMessage returns the visitor-facing text for an account error
+func customerErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, services.ErrCustomerInvalidName):
+		return "Nama harus 2 sampai 100 karakter"
+	case errors.Is(err, services.ErrCustomerDetailsTooLong):
+		return "Nama usaha dan kota maksimal 100 karakter"
+	case errors.Is(err, services.ErrCustomerInvalidPhone):
+		return "Nomor WhatsApp tidak valid"
+	case errors.Is(err, services.ErrCustomerWeakPassword):
+		return "Kata sandi minimal 8 karakter"
+	case errors.Is(err, services.ErrCustomerPhoneTaken):
+		return "Nomor WhatsApp ini sudah terdaftar"
+	case errors.Is(err, services.ErrCustomerInvalidCredentials):
+		return "Nomor WhatsApp atau kata sandi salah"
+	case errors.Is(err, services.ErrCustomerNotApproved):
+		return "Akun Anda belum aktif. Kami akan menghubungi Anda setelah pendaftaran disetujui."
+	}
+	return "Terjadi kesalahan, silakan coba lagi"
+}
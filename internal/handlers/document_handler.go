@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// documentItemField matches items[N][product_id|variant_id|quantity|discount|label] form keys
+var documentItemField = regexp.MustCompile(`^items\[(\d+)\]\[(product_id|variant_id|quantity|discount|label)\]$`)
+
+// DocumentHandler handles the admin quotation and invoice screens
+type DocumentHandler struct {
+	documentService *services.DocumentService
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(documentService *services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		documentService: documentService,
+	}
+}
+
+// ListDocuments renders issued documents with type tabs and search
+func (h *DocumentHandler) ListDocuments(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := repositories.DocumentFilters{
+		Page:        1,
+		PageSize:    50,
+		SearchQuery: strings.TrimSpace(c.Query("search", "")),
+	}
+	if p, err := strconv.Atoi(c.Query("page", "1")); err == nil && p > 0 {
+		filters.Page = p
+	}
+	for _, docType := range models.DocumentTypes {
+		if c.Query("type") == string(docType) {
+			filters.Type = string(docType)
+		}
+	}
+
+	result, err := h.documentService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load documents")
+	}
+
+	return c.Render("pages/admin/documents", fiber.Map{
+		"Title":       "Quotations & Invoices",
+		"Documents":   result.Documents,
+		"Type":        filters.Type,
+		"SearchQuery": filters.SearchQuery,
+		"Types":       models.DocumentTypes,
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+		"Success":      c.Query("success", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "documents",
+		"ContentBlock": "admin-content-documents",
+	}, "layouts/admin")
+}
+
+// NewDocumentForm renders the form to issue a quotation or invoice. With ?from=ID
+// the form starts from an issued document, e.g. to invoice an accepted quotation.
+func (h *DocumentHandler) NewDocumentForm(c *fiber.Ctx) error {
+	docType := models.DocumentType(c.Query("type", string(models.DocumentQuotation)))
+	if docType != models.DocumentInvoice {
+		docType = models.DocumentQuotation
+	}
+
+	input := &services.DocumentInput{Type: docType}
+	if from, err := strconv.Atoi(c.Query("from", "")); err == nil {
+		draft, err := h.documentService.DraftFrom(c.Context(), from, docType)
+		if err != nil {
+			return c.Status(404).SendString("Document not found")
+		}
+		input = draft
+	}
+
+	return h.renderForm(c, input, "")
+}
+
+// CreateDocument issues a quotation or invoice
+func (h *DocumentHandler) CreateDocument(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	input := parseDocumentForm(c)
+	if userID, ok := c.Locals("user_id").(int); ok {
+		input.AdminID = &userID
+	}
+
+	doc, err := h.documentService.Issue(ctx, *input)
+	if err != nil {
+		return h.renderForm(c, input, err.Error())
+	}
+
+	return c.Redirect(fmt.Sprintf("/admin/documents/%d?success=%s", doc.ID, url.QueryEscape(doc.Number+" issued")))
+}
+
+// ShowDocument renders an issued document inside the admin shell
+func (h *DocumentHandler) ShowDocument(c *fiber.Ctx) error {
+	doc, err := h.findDocument(c)
+	if err != nil {
+		return c.Status(404).SendString("Document not found")
+	}
+
+	return c.Render("pages/admin/document-detail", fiber.Map{
+		"Title":        doc.Number,
+		"Document":     doc,
+		"Success":      c.Query("success", ""),
+		"CurrentPage":  "documents",
+		"ContentBlock": "admin-content-document-detail",
+	}, "layouts/admin")
+}
+
+// PrintDocument renders the print-ready document without the admin shell
+func (h *DocumentHandler) PrintDocument(c *fiber.Ctx) error {
+	doc, err := h.findDocument(c)
+	if err != nil {
+		return c.Status(404).SendString("Document not found")
+	}
+
+	return c.Render("pages/admin/document-print", fiber.Map{
+		"Title":    doc.Number,
+		"Document": doc,
+	})
+}
+
+// DownloadPDF sends the document as a PDF file
+func (h *DocumentHandler) DownloadPDF(c *fiber.Ctx) error {
+	doc, err := h.findDocument(c)
+	if err != nil {
+		return c.Status(404).SendString("Document not found")
+	}
+
+	filename := strings.ReplaceAll(doc.Number, "/", "-") + ".pdf"
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, filename))
+	return c.Send(h.documentService.RenderPDF(doc))
+}
+
+// findDocument loads the document named by the :id route parameter
+func (h *DocumentHandler) findDocument(c *fiber.Ctx) (*models.Document, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return h.documentService.GetByID(c.Context(), id)
+}
+
+// renderForm renders the document form, re-populating input after a validation error
+func (h *DocumentHandler) renderForm(c *fiber.Ctx, input *services.DocumentInput, errMsg string) error {
+	return c.Render("pages/admin/document-form", fiber.Map{
+		"Title":        "New " + input.Type.Label(),
+		"Input":        input,
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "documents",
+		"ContentBlock": "admin-content-document-form",
+	}, "layouts/admin")
+}
+
+// parseDocumentForm reads the customer details and the picked lines in row order.
+// Rows without a product are ignored.
+func parseDocumentForm(c *fiber.Ctx) *services.DocumentInput {
+	input := &services.DocumentInput{
+		Type:            models.DocumentType(c.FormValue("type")),
+		CustomerName:    c.FormValue("customer_name"),
+		CustomerPhone:   c.FormValue("customer_phone"),
+		CustomerAddress: c.FormValue("customer_address"),
+		Notes:           c.FormValue("notes"),
+		DueDate:         c.FormValue("due_date"),
+	}
+	if discount, err := strconv.ParseFloat(strings.TrimSpace(c.FormValue("discount_amount")), 64); err == nil {
+		input.DiscountAmount = discount
+	}
+
+	rows := make(map[int]map[string]string)
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		m := documentItemField.FindStringSubmatch(string(key))
+		if m == nil {
+			return
+		}
+		index, _ := strconv.Atoi(m[1])
+		if rows[index] == nil {
+			rows[index] = make(map[string]string)
+		}
+		rows[index][m[2]] = strings.TrimSpace(string(value))
+	})
+
+	indexes := make([]int, 0, len(rows))
+	for index := range rows {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		row := rows[index]
+		productID, err := strconv.Atoi(row["product_id"])
+		if err != nil || productID <= 0 {
+			continue
+		}
+		item := services.DocumentItemInput{
+			ProductID: productID,
+			Label:     row["label"],
+		}
+		if variantID, err := strconv.Atoi(row["variant_id"]); err == nil && variantID > 0 {
+			item.VariantID = &variantID
+		}
+		item.Quantity, _ = strconv.Atoi(row["quantity"])
+		item.DiscountPercent, _ = strconv.ParseFloat(row["discount"], 64)
+		input.Items = append(input.Items, item)
+	}
+
+	return input
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"io"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// FeedHandler serves the marketplace product feeds
+type FeedHandler struct {
+	feedService *services.FeedService
+	baseURL     string
+	token       string
+}
+
+// NewFeedHandler creates a new feed handler. When token is set, the feeds require
+// it as ?token= so only the marketplaces fetch them.
+func NewFeedHandler(feedService *services.FeedService, baseURL, token string) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+		baseURL:     baseURL,
+		token:       token,
+	}
+}
+
+// GoogleFeed streams the Google Merchant Center RSS feed
+func (h *FeedHandler) GoogleFeed(c *fiber.Ctx) error {
+	return h.stream(c, "google", fiber.MIMEApplicationXMLCharsetUTF8, h.feedService.WriteGoogle)
+}
+
+// MetaFeed streams the Meta Commerce Manager CSV feed
+func (h *FeedHandler) MetaFeed(c *fiber.Ctx) error {
+	return h.stream(c, "meta", "text/csv; charset=utf-8", h.feedService.WriteMeta)
+}
+
+// stream checks the token, then writes the feed as the catalog is read. Errors
+// after the first bytes can only end the response early, so they are logged.
+func (h *FeedHandler) stream(c *fiber.Ctx, name, contentType string, write func(w io.Writer, baseURL string) error) error {
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(h.token)) != 1 {
+		return c.Status(fiber.StatusForbidden).SendString("Invalid feed token")
+	}
+
+	baseURL := h.baseURL
+	if baseURL == "" {
+		baseURL = c.BaseURL()
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := write(w, baseURL); err != nil {
+			log.Printf("ERROR: Failed to write %s product feed: %v", name, err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// InquiryHandler records WhatsApp inquiries and serves the admin inquiry inbox
+type InquiryHandler struct {
+	inquiryService *services.InquiryService
+}
+
+// NewInquiryHandler creates a new inquiry handler
+func NewInquiryHandler(inquiryService *services.InquiryService) *InquiryHandler {
+	return &InquiryHandler{
+		inquiryService: inquiryService,
+	}
+}
+
+// Inquire stores a lead for the product page selection and redirects to the WhatsApp chat.
+// It only answers the product page's POST, so prefetchers and link previews record nothing.
+func (h *InquiryHandler) Inquire(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("productId"))
+	if err != nil || productID <= 0 {
+		return c.Status(404).SendString("Product not found")
+	}
+
+	quantity, err := strconv.Atoi(c.FormValue("qty"))
+	if err != nil {
+		quantity = 1
+	}
+	input := services.ProductInquiryInput{
+		ProductID: productID,
+		Quantity:  quantity,
+		Referrer:  c.FormValue("ref", c.Get("Referer")), // The product page passes where the visitor came from
+	}
+	if variantID, err := strconv.Atoi(c.FormValue("variant_id")); err == nil && variantID > 0 {
+		input.VariantID = &variantID
+	}
+
+	waURL, err := h.inquiryService.RecordProductInquiry(c.UserContext(), input)
+	if errors.Is(err, services.ErrInquiryProductNotFound) {
+		return c.Status(404).SendString("Product not found")
+	}
+	if waURL == "" {
+		return c.Status(500).SendString("Failed to open WhatsApp chat")
+	}
+
+	// A lead that failed to save must not keep the visitor from the chat
+	return c.Redirect(waURL)
+}
+
+// ListInquiries renders the inquiry inbox with status, product and search filters
+func (h *InquiryHandler) ListInquiries(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := inquiryFiltersFromQuery(c.Queries())
+
+	result, err := h.inquiryService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load inquiries")
+	}
+
+	counts, err := h.inquiryService.CountByStatus(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load inquiries")
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	return c.Render("pages/admin/inquiries", fiber.Map{
+		"Title":        "Inquiries",
+		"Inquiries":    result.Inquiries,
+		"Status":       filters.Status,
+		"ProductID":    filters.ProductID,
+		"SearchQuery":  filters.SearchQuery,
+		"Statuses":     models.InquiryStatuses,
+		"StatusCounts": counts,
+		"TotalCount":   total,
+		"FilterQuery":  inquiryFilterValues(filters).Encode(),
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+		"Success":      c.Query("success", ""),
+		"Error":        c.Query("error", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "inquiries",
+		"ContentBlock": "admin-content-inquiries",
+	}, "layouts/admin")
+}
+
+// UpdateInquiry saves the follow-up status and notes of an inquiry and returns to the filtered inbox
+func (h *InquiryHandler) UpdateInquiry(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).SendString("Invalid inquiry ID")
+	}
+
+	// Only the known filter keys are carried back, so the redirect stays on the inbox
+	values, _ := url.ParseQuery(c.FormValue("filters"))
+	filters := inquiryFiltersFromQuery(map[string]string{
+		"status":     values.Get("status"),
+		"product_id": values.Get("product_id"),
+		"search":     values.Get("search"),
+		"page":       values.Get("page"),
+	})
+	back := inquiryFilterValues(filters)
+
+	err = h.inquiryService.UpdateStatus(ctx, id, models.InquiryStatus(c.FormValue("status")), c.FormValue("notes"))
+	if err != nil {
+		back.Set("error", err.Error())
+	} else {
+		back.Set("success", "Inquiry #"+strconv.Itoa(id)+" updated")
+	}
+
+	return c.Redirect("/admin/inquiries?" + back.Encode())
+}
+
+// inquiryFiltersFromQuery parses inbox filters from query parameters
+func inquiryFiltersFromQuery(query map[string]string) repositories.InquiryFilters {
+	filters := repositories.InquiryFilters{
+		Page:        1,
+		PageSize:    50,
+		SearchQuery: strings.TrimSpace(query["search"]),
+	}
+	if p, err := strconv.Atoi(query["page"]); err == nil && p > 0 {
+		filters.Page = p
+	}
+	if productID, err := strconv.Atoi(query["product_id"]); err == nil && productID > 0 {
+		filters.ProductID = &productID
+	}
+	for _, status := range models.InquiryStatuses {
+		if query["status"] == string(status) {
+			filters.Status = query["status"]
+		}
+	}
+	return filters
+}
+
+// inquiryFilterValues encodes inbox filters and the page as query values
+func inquiryFilterValues(filters repositories.InquiryFilters) url.Values {
+	values := url.Values{}
+	if filters.Status != "" {
+		values.Set("status", filters.Status)
+	}
+	if filters.ProductID != nil {
+		values.Set("product_id", strconv.Itoa(*filters.ProductID))
+	}
+	if filters.SearchQuery != "" {
+		values.Set("search", filters.SearchQuery)
+	}
+	if filters.Page > 1 {
+		values.Set("page", strconv.Itoa(filters.Page))
+	}
+	return values
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// InventoryHandler handles admin stock screens and the movement ledger
+type InventoryHandler struct {
+	inventoryService *services.InventoryService
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(inventoryService *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// ListStock renders the stock levels of all variants
+func (h *InventoryHandler) ListStock(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	searchQuery := strings.TrimSpace(c.Query("search", ""))
+	levels, err := h.inventoryService.GetStockLevels(ctx, searchQuery)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load stock levels")
+	}
+
+	return c.Render("pages/admin/inventory", fiber.Map{
+		"Title":        "Inventory",
+		"StockLevels":  levels,
+		"SearchQuery":  searchQuery,
+		"Success":      c.Query("success", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "inventory",
+		"ContentBlock": "admin-content-inventory",
+	}, "layouts/admin")
+}
+
+// ListMovements renders the movement ledger, optionally for one variant
+func (h *InventoryHandler) ListMovements(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := repositories.MovementFilters{
+		Page:     1,
+		PageSize: 50,
+	}
+	if p, err := strconv.Atoi(c.Query("page", "1")); err == nil && p > 0 {
+		filters.Page = p
+	}
+
+	var variant *models.VariantStock
+	if variantID, err := strconv.Atoi(c.Query("variant_id", "")); err == nil && variantID > 0 {
+		filters.VariantID = &variantID
+		variant, _ = h.inventoryService.GetVariantStock(ctx, variantID)
+	}
+	if movementType := c.Query("type", ""); movementType != "" {
+		filters.MovementType = movementType
+	}
+
+	result, err := h.inventoryService.GetMovements(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load inventory movements")
+	}
+
+	return c.Render("pages/admin/inventory-movements", fiber.Map{
+		"Title":         "Stock Movements",
+		"Movements":     result.Movements,
+		"Variant":       variant,
+		"MovementType":  filters.MovementType,
+		"MovementTypes": models.MovementTypes,
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "inventory",
+		"ContentBlock": "admin-content-inventory-movements",
+	}, "layouts/admin")
+}
+
+// NewMovementForm renders the form to record a stock movement for a variant
+func (h *InventoryHandler) NewMovementForm(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	variantID, err := strconv.Atoi(c.Query("variant_id", ""))
+	if err != nil || variantID <= 0 {
+		return c.Redirect("/admin/inventory")
+	}
+
+	variant, err := h.inventoryService.GetVariantStock(ctx, variantID)
+	if err != nil {
+		return c.Status(404).SendString("Variant not found")
+	}
+
+	return h.renderMovementForm(c, variant, "", nil)
+}
+
+// CreateMovement records a stock movement
+func (h *InventoryHandler) CreateMovement(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	variantID, err := strconv.Atoi(c.FormValue("variant_id"))
+	if err != nil || variantID <= 0 {
+		return c.Status(400).SendString("Invalid variant ID")
+	}
+
+	variant, err := h.inventoryService.GetVariantStock(ctx, variantID)
+	if err != nil {
+		return c.Status(404).SendString("Variant not found")
+	}
+
+	input := services.MovementInput{
+		VariantID:    variantID,
+		MovementType: models.MovementType(c.FormValue("movement_type")),
+		Reason:       c.FormValue("reason"),
+	}
+	if qty, err := strconv.Atoi(strings.TrimSpace(c.FormValue("quantity"))); err == nil {
+		input.Quantity = qty
+	}
+	if userID, ok := c.Locals("user_id").(int); ok {
+		input.AdminID = &userID
+	}
+
+	movement, err := h.inventoryService.RecordMovement(ctx, input)
+	if err != nil {
+		return h.renderMovementForm(c, variant, err.Error(), &input)
+	}
+
+	msg := fmt.Sprintf("Stock for %s (%s) is now %d", variant.ProductCode, variant.Color, movement.BalanceAfter)
+	return c.Redirect("/admin/inventory?success=" + url.QueryEscape(msg))
+}
+
+// renderMovementForm renders the movement form, re-populating input after a validation error
+func (h *InventoryHandler) renderMovementForm(c *fiber.Ctx, variant *models.VariantStock, errMsg string, input *services.MovementInput) error {
+	return c.Render("pages/admin/inventory-movement-form", fiber.Map{
+		"Title":         "Record Stock Movement",
+		"Variant":       variant,
+		"Input":         input,
+		"Error":         errMsg,
+		"MovementTypes": models.MovementTypes,
+		"CSRFToken":     getCSRFToken(c),
+		"CurrentPage":   "inventory",
+		"ContentBlock":  "admin-content-inventory-form",
+	}, "layouts/admin")
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// orderItemField matches items[N][product_id|variant_id|quantity|label] form keys
+var orderItemField = regexp.MustCompile(`^items\[(\d+)\]\[(product_id|variant_id|quantity|label)\]$`)
+
+// OrderHandler handles the admin order screens
+type OrderHandler struct {
+	orderService   *services.OrderService
+	productService *services.ProductService
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *services.OrderService, productService *services.ProductService) *OrderHandler {
+	return &OrderHandler{
+		orderService:   orderService,
+		productService: productService,
+	}
+}
+
+// ListOrders renders the order list with status tabs and search
+func (h *OrderHandler) ListOrders(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := repositories.OrderFilters{
+		Page:        1,
+		PageSize:    50,
+		SearchQuery: strings.TrimSpace(c.Query("search", "")),
+	}
+	if p, err := strconv.Atoi(c.Query("page", "1")); err == nil && p > 0 {
+		filters.Page = p
+	}
+	for _, status := range models.OrderStatuses {
+		if c.Query("status") == string(status) {
+			filters.Status = string(status)
+		}
+	}
+
+	result, err := h.orderService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load orders")
+	}
+
+	counts, err := h.orderService.CountByStatus(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load orders")
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	return c.Render("pages/admin/orders", fiber.Map{
+		"Title":        "Orders",
+		"Orders":       result.Orders,
+		"Status":       filters.Status,
+		"SearchQuery":  filters.SearchQuery,
+		"Statuses":     models.OrderStatuses,
+		"StatusCounts": counts,
+		"TotalCount":   total,
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+		"Success":      c.Query("success", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "orders",
+		"ContentBlock": "admin-content-orders",
+	}, "layouts/admin")
+}
+
+// NewOrderForm renders the form to create an order from the catalog
+func (h *OrderHandler) NewOrderForm(c *fiber.Ctx) error {
+	return h.renderForm(c, &services.OrderInput{}, "")
+}
+
+// CreateOrder stores an order picked from the catalog
+func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	input := parseOrderForm(c)
+	if userID, ok := c.Locals("user_id").(int); ok {
+		input.AdminID = &userID
+	}
+
+	order, err := h.orderService.Create(ctx, *input)
+	if err != nil {
+		return h.renderForm(c, input, err.Error())
+	}
+
+	return c.Redirect(fmt.Sprintf("/admin/orders/%d?success=%s", order.ID, url.QueryEscape("Order "+order.Number()+" created")))
+}
+
+// ShowOrder renders an order with its items, status history and status actions
+func (h *OrderHandler) ShowOrder(c *fiber.Ctx) error {
+	order, err := h.findOrder(c)
+	if err != nil {
+		return c.Status(404).SendString("Order not found")
+	}
+
+	return c.Render("pages/admin/order-detail", fiber.Map{
+		"Title":        "Order " + order.Number(),
+		"Order":        order,
+		"Success":      c.Query("success", ""),
+		"Error":        c.Query("error", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "orders",
+		"ContentBlock": "admin-content-order-detail",
+	}, "layouts/admin")
+}
+
+// UpdateOrderStatus moves an order to the next status chosen on the detail page
+func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).SendString("Invalid order ID")
+	}
+
+	var adminID *int
+	if userID, ok := c.Locals("user_id").(int); ok {
+		adminID = &userID
+	}
+
+	status := models.OrderStatus(c.FormValue("status"))
+	if err := h.orderService.ChangeStatus(ctx, id, status, c.FormValue("note"), adminID); err != nil {
+		return c.Redirect(fmt.Sprintf("/admin/orders/%d?error=%s", id, url.QueryEscape(err.Error())))
+	}
+
+	return c.Redirect(fmt.Sprintf("/admin/orders/%d?success=%s", id, url.QueryEscape("Status changed to "+status.Label())))
+}
+
+// PackingSlip renders a printable packing slip without the admin shell
+func (h *OrderHandler) PackingSlip(c *fiber.Ctx) error {
+	order, err := h.findOrder(c)
+	if err != nil {
+		return c.Status(404).SendString("Order not found")
+	}
+
+	return c.Render("pages/admin/packing-slip", fiber.Map{
+		"Title": "Packing Slip " + order.Number(),
+		"Order": order,
+	})
+}
+
+// ProductPicker searches the catalog for the order form (htmx partial)
+func (h *OrderHandler) ProductPicker(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := repositories.ProductFilters{
+		Page:     1,
+		PageSize: 10,
+		SortBy:   "newest",
+	}
+	if query := strings.TrimSpace(c.Query("q", "")); query != "" {
+		filters.SearchQuery = query
+		filters.SortBy = "relevance"
+	}
+
+	result, err := h.productService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load products")
+	}
+
+	return c.Render("partials/order-picker", fiber.Map{
+		"Products": result.Products,
+	})
+}
+
+// findOrder loads the order named by the :id route parameter
+func (h *OrderHandler) findOrder(c *fiber.Ctx) (*models.Order, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	return h.orderService.GetByID(c.Context(), id)
+}
+
+// renderForm renders the order form, re-populating input after a validation error
+func (h *OrderHandler) renderForm(c *fiber.Ctx, input *services.OrderInput, errMsg string) error {
+	return c.Render("pages/admin/order-form", fiber.Map{
+		"Title":        "New Order",
+		"Input":        input,
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "orders",
+		"ContentBlock": "admin-content-order-form",
+	}, "layouts/admin")
+}
+
+// parseOrderForm reads the customer details and the picked lines in row order.
+// Rows without a product are ignored.
+func parseOrderForm(c *fiber.Ctx) *services.OrderInput {
+	input := &services.OrderInput{
+		CustomerName:    c.FormValue("customer_name"),
+		CustomerPhone:   c.FormValue("customer_phone"),
+		ShippingAddress: c.FormValue("shipping_address"),
+		Notes:           c.FormValue("notes"),
+	}
+	if cost, err := strconv.ParseFloat(strings.TrimSpace(c.FormValue("shipping_cost")), 64); err == nil {
+		input.ShippingCost = cost
+	}
+
+	rows := make(map[int]map[string]string)
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		m := orderItemField.FindStringSubmatch(string(key))
+		if m == nil {
+			return
+		}
+		index, _ := strconv.Atoi(m[1])
+		if rows[index] == nil {
+			rows[index] = make(map[string]string)
+		}
+		rows[index][m[2]] = strings.TrimSpace(string(value))
+	})
+
+	indexes := make([]int, 0, len(rows))
+	for index := range rows {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		row := rows[index]
+		productID, err := strconv.Atoi(row["product_id"])
+		if err != nil || productID <= 0 {
+			continue
+		}
+		item := services.OrderItemInput{
+			ProductID: productID,
+			Label:     row["label"],
+		}
+		if variantID, err := strconv.Atoi(row["variant_id"]); err == nil && variantID > 0 {
+			item.VariantID = &variantID
+		}
+		item.Quantity, _ = strconv.Atoi(row["quantity"])
+		input.Items = append(input.Items, item)
+	}
+
+	return input
+}
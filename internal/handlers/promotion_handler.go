@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// promotionTimeLayout matches the value of an <input type="datetime-local">
+const promotionTimeLayout = "2006-01-02T15:04"
+
+// PromotionHandler handles admin promotion CRUD routes
+type PromotionHandler struct {
+	promotionService *services.PromotionService
+	categoryService  *services.CategoryService
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionService *services.PromotionService, categoryService *services.CategoryService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		categoryService:  categoryService,
+	}
+}
+
+// promotionProductOption groups a product's variants for the target picker
+type promotionProductOption struct {
+	ID       int
+	Code     string
+	Title    string
+	Variants []promotionVariantOption
+}
+
+type promotionVariantOption struct {
+	ID    int
+	Color string
+}
+
+// ListPromotions renders the promotions list page
+func (h *PromotionHandler) ListPromotions(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	promotions, err := h.promotionService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load promotions")
+	}
+
+	return c.Render("pages/admin/promotions", fiber.Map{
+		"Title":        "Promotions",
+		"Promotions":   promotions,
+		"Success":      c.Query("success", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "promotions",
+		"ContentBlock": "admin-content-promotions",
+	}, "layouts/admin")
+}
+
+// NewPromotionForm renders the promotion creation form
+func (h *PromotionHandler) NewPromotionForm(c *fiber.Ctx) error {
+	now := time.Now().In(models.StoreLocation)
+	promotion := &models.Promotion{
+		DiscountType: models.DiscountPercent,
+		StartsAt:     now.Truncate(time.Hour),
+		EndsAt:       now.Truncate(time.Hour).Add(7 * 24 * time.Hour),
+		IsActive:     true,
+	}
+	return h.renderForm(c, promotion, false, "")
+}
+
+// CreatePromotion handles promotion creation
+func (h *PromotionHandler) CreatePromotion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	promotion, err := parsePromotionForm(c)
+	if err == nil {
+		err = h.promotionService.Create(ctx, promotion)
+	}
+	if err != nil {
+		return h.renderForm(c, promotion, false, err.Error())
+	}
+
+	return c.Redirect("/admin/promotions?success=" + url.QueryEscape(fmt.Sprintf("Promotion '%s' created successfully", promotion.Name)))
+}
+
+// EditPromotionForm renders the promotion edit form
+func (h *PromotionHandler) EditPromotionForm(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	promotionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || promotionID <= 0 {
+		return c.Status(404).SendString("Promotion not found")
+	}
+
+	promotion, err := h.promotionService.GetByID(ctx, promotionID)
+	if err != nil {
+		return c.Status(404).SendString("Promotion not found")
+	}
+
+	return h.renderForm(c, promotion, true, "")
+}
+
+// UpdatePromotion handles promotion update
+func (h *PromotionHandler) UpdatePromotion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	promotionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || promotionID <= 0 {
+		return c.Status(404).SendString("Promotion not found")
+	}
+
+	promotion, err := parsePromotionForm(c)
+	promotion.ID = promotionID
+	if err == nil {
+		err = h.promotionService.Update(ctx, promotionID, promotion)
+	}
+	if err != nil {
+		return h.renderForm(c, promotion, true, err.Error())
+	}
+
+	return c.Redirect("/admin/promotions?success=" + url.QueryEscape(fmt.Sprintf("Promotion '%s' updated successfully", promotion.Name)))
+}
+
+// DeletePromotion handles promotion deletion
+func (h *PromotionHandler) DeletePromotion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	promotionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || promotionID <= 0 {
+		return c.Status(400).SendString("Invalid promotion ID")
+	}
+
+	if err := h.promotionService.Delete(ctx, promotionID); err != nil {
+		return c.Status(500).SendString(fmt.Sprintf("Failed to delete promotion: %v", err))
+	}
+
+	return c.Redirect("/admin/promotions?success=" + url.QueryEscape("Promotion deleted successfully"))
+}
+
+// parsePromotionForm reads a promotion and its targets from the submitted form.
+// The returned promotion is always non-nil so the form can be re-rendered on error.
+func parsePromotionForm(c *fiber.Ctx) (*models.Promotion, error) {
+	promotion := &models.Promotion{
+		Name:         strings.TrimSpace(c.FormValue("name")),
+		DiscountType: models.DiscountType(c.FormValue("discount_type")),
+		IsActive:     c.FormValue("is_active") == "on" || c.FormValue("is_active") == "true",
+	}
+
+	args := c.Request().PostArgs()
+	for _, raw := range args.PeekMulti("category_ids") {
+		if id, err := strconv.Atoi(string(raw)); err == nil && id > 0 {
+			promotion.Targets = append(promotion.Targets, models.PromotionTarget{CategoryID: &id})
+		}
+	}
+	for _, raw := range args.PeekMulti("product_ids") {
+		if id, err := strconv.Atoi(string(raw)); err == nil && id > 0 {
+			promotion.Targets = append(promotion.Targets, models.PromotionTarget{ProductID: &id})
+		}
+	}
+	for _, raw := range args.PeekMulti("variant_ids") {
+		if id, err := strconv.Atoi(string(raw)); err == nil && id > 0 {
+			promotion.Targets = append(promotion.Targets, models.PromotionTarget{VariantID: &id})
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(c.FormValue("discount_value")), 64)
+	if err != nil {
+		return promotion, errors.New("invalid discount value")
+	}
+	promotion.DiscountValue = value
+
+	// Times are wall-clock store time, compared against the database clock in the store's zone
+	if promotion.StartsAt, err = time.ParseInLocation(promotionTimeLayout, c.FormValue("starts_at"), models.StoreLocation); err != nil {
+		return promotion, errors.New("invalid start time")
+	}
+	if promotion.EndsAt, err = time.ParseInLocation(promotionTimeLayout, c.FormValue("ends_at"), models.StoreLocation); err != nil {
+		return promotion, errors.New("invalid end time")
+	}
+
+	return promotion, nil
+}
+
+// renderForm renders the promotion form with target pickers
+func (h *PromotionHandler) renderForm(c *fiber.Ctx, promotion *models.Promotion, isEdit bool, errMsg string) error {
+	ctx := c.Context()
+
+	categories, err := h.categoryService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load categories")
+	}
+
+	options, err := h.promotionService.GetTargetOptions(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load products")
+	}
+
+	var products []promotionProductOption
+	for _, opt := range options {
+		if len(products) == 0 || products[len(products)-1].ID != opt.ProductID {
+			products = append(products, promotionProductOption{
+				ID:    opt.ProductID,
+				Code:  opt.ProductCode,
+				Title: opt.ProductTitle,
+			})
+		}
+		if opt.VariantID != nil && opt.VariantColor != nil {
+			last := &products[len(products)-1]
+			last.Variants = append(last.Variants, promotionVariantOption{ID: *opt.VariantID, Color: *opt.VariantColor})
+		}
+	}
+
+	selectedCategories := make(map[int]bool)
+	selectedProducts := make(map[int]bool)
+	selectedVariants := make(map[int]bool)
+	for _, target := range promotion.Targets {
+		switch {
+		case target.CategoryID != nil:
+			selectedCategories[*target.CategoryID] = true
+		case target.ProductID != nil:
+			selectedProducts[*target.ProductID] = true
+		case target.VariantID != nil:
+			selectedVariants[*target.VariantID] = true
+		}
+	}
+
+	title := "Add Promotion"
+	if isEdit {
+		title = "Edit Promotion"
+	}
+
+	return c.Render("pages/admin/promotion-form", fiber.Map{
+		"Title":              title,
+		"Promotion":          promotion,
+		"IsEdit":             isEdit,
+		"Error":              errMsg,
+		"Categories":         categories,
+		"Products":           products,
+		"SelectedCategories": selectedCategories,
+		"SelectedProducts":   selectedProducts,
+		"SelectedVariants":   selectedVariants,
+		"TimeLayout":         promotionTimeLayout,
+		"CSRFToken":          getCSRFToken(c),
+		"CurrentPage":        "promotions",
+		"ContentBlock":       "admin-content-promotion-form",
+	}, "layouts/admin")
+}
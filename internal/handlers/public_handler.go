@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
+	"github.com/rizkysr90/aslam-flower/internal/models"
 	"github.com/rizkysr90/aslam-flower/internal/repositories"
 	"github.com/rizkysr90/aslam-flower/internal/services"
 )
@@ -12,59 +15,209 @@ import (
 type PublicHandler struct {
 	productService  *services.ProductService
 	categoryService *services.CategoryService
-	whatsAppNumber  string
-	storeName       string
-	storeAddress    string
-	shopeeLink      string
-	tiktokLink      string
-	instagramLink   string
+	wishlistService *services.WishlistService
+	reviewService   *services.ReviewService
+	baseURL         string
+}
+
+// productPageReviews is the number of approved reviews shown on a product page
+const productPageReviews = 10
+
+// productPageData is the JSON read by the product detail script to price the
+// selected variant and quantity
+type productPageData struct {
+	BasePrice    float64              `json:"basePrice"`    // After the product's live promotion
+	RegularPrice float64              `json:"regularPrice"` // Before any promotion
+	Promotion    *productPagePromo    `json:"promotion"`
+	Variants     []productPageVariant `json:"variants"`
+}
+
+type productPageVariant struct {
+	ID           int                `json:"id"`
+	Color        string             `json:"color"`
+	Price        float64            `json:"price"`
+	RegularPrice float64            `json:"regularPrice"`
+	Promotion    *productPagePromo  `json:"promotion"`
+	PriceTiers   []models.PriceTier `json:"priceTiers"`
+}
+
+type productPagePromo struct {
+	Name   string `json:"name"`
+	EndsAt string `json:"endsAt"`
+}
+
+// defaultShareImage is the picture shown for shared links to pages without one of their own
+const defaultShareImage = "/static/images/hero.jpeg"
+
+// productSchema is the schema.org Product read by search engines from a product page
+type productSchema struct {
+	Context         string                 `json:"@context"`
+	Type            string                 `json:"@type"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description,omitempty"`
+	SKU             string                 `json:"sku"`
+	Image           []string               `json:"image,omitempty"`
+	URL             string                 `json:"url"`
+	Category        string                 `json:"category,omitempty"`
+	Offers          interface{}            `json:"offers"` // schemaOffer, or schemaAggregateOffer for several variants
+	AggregateRating *schemaAggregateRating `json:"aggregateRating,omitempty"`
+}
+
+type schemaOffer struct {
+	Type            string  `json:"@type"`
+	Name            string  `json:"name,omitempty"`
+	Price           float64 `json:"price"`
+	PriceCurrency   string  `json:"priceCurrency"`
+	PriceValidUntil string  `json:"priceValidUntil,omitempty"` // End of the live promotion
+	Availability    string  `json:"availability"`
+	ItemCondition   string  `json:"itemCondition"`
+	URL             string  `json:"url"`
+}
+
+type schemaAggregateOffer struct {
+	Type          string        `json:"@type"`
+	LowPrice      float64       `json:"lowPrice"`
+	HighPrice     float64       `json:"highPrice"`
+	PriceCurrency string        `json:"priceCurrency"`
+	OfferCount    int           `json:"offerCount"`
+	Offers        []schemaOffer `json:"offers"`
+}
+
+type schemaAggregateRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	ReviewCount int     `json:"reviewCount"`
+	BestRating  int     `json:"bestRating"`
+}
+
+// newProductSchema describes product, shown at url, with one offer per variant
+func newProductSchema(product *models.Product, url string) productSchema {
+	schema := productSchema{
+		Context:     "https://schema.org",
+		Type:        "Product",
+		Name:        product.Title,
+		Description: metaDescription(product.Description),
+		SKU:         product.Code,
+		URL:         url,
+	}
+	if product.MainPhotoURL != "" {
+		schema.Image = append(schema.Image, product.MainPhotoURL)
+	}
+	for _, image := range product.Images {
+		schema.Image = append(schema.Image, image.ImageURL)
+	}
+	if product.Category != nil {
+		schema.Category = product.Category.Name
+	}
+	if product.RatingCount > 0 {
+		schema.AggregateRating = &schemaAggregateRating{
+			Type:        "AggregateRating",
+			RatingValue: product.RatingAverage,
+			ReviewCount: product.RatingCount,
+			BestRating:  5,
+		}
+	}
+
+	if len(product.Variants) == 0 {
+		schema.Offers = newSchemaOffer("", product.FinalPrice(), product.Promotion, !product.IsSold, url)
+		return schema
+	}
+
+	offers := make([]schemaOffer, 0, len(product.Variants))
+	for i := range product.Variants {
+		v := &product.Variants[i]
+		offers = append(offers, newSchemaOffer(v.Color, v.FinalPrice(product.BasePrice), v.Promotion, v.StockQty > 0, url))
+	}
+	if len(offers) == 1 {
+		schema.Offers = offers[0]
+		return schema
+	}
+
+	low, high := product.PriceRange()
+	schema.Offers = schemaAggregateOffer{
+		Type:          "AggregateOffer",
+		LowPrice:      low,
+		HighPrice:     high,
+		PriceCurrency: "IDR",
+		OfferCount:    len(offers),
+		Offers:        offers,
+	}
+	return schema
+}
+
+// newSchemaOffer describes one price of a product; a live promotion bounds its validity
+func newSchemaOffer(name string, price float64, promo *models.Promotion, inStock bool, url string) schemaOffer {
+	offer := schemaOffer{
+		Type:          "Offer",
+		Name:          name,
+		Price:         price,
+		PriceCurrency: "IDR",
+		Availability:  "https://schema.org/OutOfStock",
+		ItemCondition: "https://schema.org/NewCondition",
+		URL:           url,
+	}
+	if inStock {
+		offer.Availability = "https://schema.org/InStock"
+	}
+	if promo != nil {
+		offer.PriceValidUntil = promo.EndsAt.Format("2006-01-02")
+	}
+	return offer
+}
+
+// newProductPagePromo returns the display data for a live promotion, or nil
+func newProductPagePromo(promo *models.Promotion) *productPagePromo {
+	if promo == nil {
+		return nil
+	}
+	return &productPagePromo{
+		Name:   promo.Name,
+		EndsAt: promo.EndsAt.Format("02 Jan 2006 15:04"),
+	}
 }
 
 // NewPublicHandler creates a new public handler
-func NewPublicHandler(productService *services.ProductService, categoryService *services.CategoryService, whatsAppNumber, storeName, storeAddress, shopeeLink, tiktokLink, instagramLink string) *PublicHandler {
+func NewPublicHandler(productService *services.ProductService, categoryService *services.CategoryService, wishlistService *services.WishlistService, reviewService *services.ReviewService, baseURL string) *PublicHandler {
 	return &PublicHandler{
 		productService:  productService,
 		categoryService: categoryService,
-		whatsAppNumber:  whatsAppNumber,
-		storeName:       storeName,
-		storeAddress:    storeAddress,
-		shopeeLink:      shopeeLink,
-		tiktokLink:      tiktokLink,
-		instagramLink:   instagramLink,
+		wishlistService: wishlistService,
+		reviewService:   reviewService,
+		baseURL:         baseURL,
 	}
 }
 
 // Landing renders the main catalog page with products and filters
 func (h *PublicHandler) Landing(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
-	// Get all categories for filter sidebar
-	categories, err := h.categoryService.GetAll(ctx)
+	// Get the category tree for the filter sidebar
+	categories, err := h.categoryService.GetTree(ctx)
 	if err != nil {
 		return c.Status(500).SendString("Failed to load categories")
 	}
 
 	// Parse query parameters
 	filters := h.parseFilters(c)
+	filters.WithFacets = true
 
 	// Get products with filters
 	result, err := h.productService.GetAll(ctx, filters)
 	if err != nil {
 		return c.Status(500).SendString("Failed to load products")
 	}
+	markWishlisted(c, h.wishlistService, result.Products)
 
 	data := fiber.Map{
-		"Title":          "Katalog Produk",
-		"ContentBlock":   "landing-content",
-		"Products":       result.Products,
-		"Categories":     categories,
-		"Filters":        filters,
-		"StoreName":      h.storeName,
-		"StoreAddress":   h.storeAddress,
-		"ShopeeLink":     h.shopeeLink,
-		"TiktokLink":     h.tiktokLink,
-		"InstagramLink":  h.instagramLink,
-		"WhatsAppNumber": h.whatsAppNumber,
+		"Title":        "Katalog Produk",
+		"ContentBlock": "landing-content",
+		"CanonicalURL": h.canonicalPage(c, "/", result.Page),
+		"MetaImage":    h.origin(c) + defaultShareImage,
+		"Products":     result.Products,
+		"Facets":       result.Facets,
+		"Suggestion":   result.Suggestion,
+		"Categories":   categories,
+		"Filters":      filters,
 		"Pagination": fiber.Map{
 			"CurrentPage": result.Page,
 			"TotalPages":  result.TotalPages,
@@ -83,36 +236,180 @@ func (h *PublicHandler) Landing(c *fiber.Ctx) error {
 	return c.Render("pages/landing", data, "layouts/base")
 }
 
-// ProductDetail renders product detail page
-func (h *PublicHandler) ProductDetail(c *fiber.Ctx) error {
-	ctx := c.Context()
+// CategoryPage renders the catalog of one category (and its subcategories) at
+// /kategori/:slug. Old slugs from before a rename redirect permanently.
+func (h *PublicHandler) CategoryPage(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	slug := c.Params("slug")
+	category, err := h.categoryService.GetBySlug(ctx, slug)
+	if err != nil {
+		return c.Status(404).SendString("Category not found")
+	}
+	if category.Slug != slug {
+		target := localePath(c, category.URL())
+		if query := string(c.Request().URI().QueryString()); query != "" {
+			target += "?" + query
+		}
+		return c.Redirect(target, fiber.StatusMovedPermanently)
+	}
+
+	filters := h.parseFilters(c)
+	filters.CategoryID = &category.ID
+
+	result, err := h.productService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load products")
+	}
+	markWishlisted(c, h.wishlistService, result.Products)
+
+	data := fiber.Map{
+		"Title":        category.PageTitle(),
+		"ContentBlock": "category-content",
+		"Category":     category,
+		"Products":     result.Products,
+		"Suggestion":   result.Suggestion,
+		"Filters":      filters,
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+	}
+
+	// Pagination and sorting swap only the catalog fragment
+	if c.Get("HX-Request") == "true" {
+		return c.Render("partials/landing-catalog", data)
+	}
+
+	breadcrumbs, err := h.categoryService.GetBreadcrumbs(ctx, category.ID)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load categories")
+	}
+
+	categories, err := h.categoryService.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load categories")
+	}
+	var subcategories []models.Category
+	for _, sub := range categories {
+		if sub.ParentID != nil && *sub.ParentID == category.ID {
+			subcategories = append(subcategories, sub)
+		}
+	}
+
+	data["Breadcrumbs"] = breadcrumbs
+	data["Subcategories"] = subcategories
+	data["MetaDescription"] = metaDescription(category.Description)
+	data["CanonicalURL"] = h.canonicalPage(c, category.URL(), result.Page)
+	data["MetaImage"] = h.origin(c) + defaultShareImage
+	if category.BannerURL != "" {
+		data["MetaImage"] = category.BannerURL
+	}
+
+	return c.Render("pages/category", data, "layouts/base")
+}
 
-	// Get product ID from URL params
-	idParam := c.Params("id")
-	productID, err := strconv.Atoi(idParam)
+// ProductRedirect permanently redirects the legacy /products/:id URL to /p/:slug
+func (h *PublicHandler) ProductRedirect(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	productID, err := strconv.Atoi(c.Params("id"))
 	if err != nil || productID <= 0 {
 		return c.Status(404).SendString("Product not found")
 	}
 
-	// Load product with variants
 	product, err := h.productService.GetByID(ctx, productID)
 	if err != nil {
 		return c.Status(404).SendString("Product not found")
 	}
 
+	return c.Redirect(product.URL(), fiber.StatusMovedPermanently)
+}
+
+// ProductDetail renders the product page at /p/:slug. Slugs from before a rename
+// redirect permanently to the current one.
+func (h *PublicHandler) ProductDetail(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	// Load product with variants
+	slug := c.Params("slug")
+	product, err := h.productService.GetBySlug(ctx, slug)
+	if err != nil {
+		return c.Status(404).SendString("Product not found")
+	}
+	if product.Slug != slug {
+		return c.Redirect(localePath(c, product.URL()), fiber.StatusMovedPermanently)
+	}
+	if wishlisted, err := h.wishlistService.IDs(ctx, c.Cookies(visitorCookie)); err == nil {
+		product.Wishlisted = wishlisted[product.ID]
+	}
+
+	// Breadcrumbs run from the top-level category down to the product's own
+	var breadcrumbs []models.Category
+	if product.CategoryID != nil {
+		breadcrumbs, err = h.categoryService.GetBreadcrumbs(ctx, *product.CategoryID)
+		if err != nil {
+			return c.Status(500).SendString("Failed to load categories")
+		}
+		if len(breadcrumbs) > 0 {
+			product.Category = &breadcrumbs[len(breadcrumbs)-1]
+		}
+	}
+
+	reviews, err := h.reviewService.GetApproved(ctx, product.ID, productPageReviews)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load reviews")
+	}
+
+	pageData := productPageData{
+		BasePrice:    product.FinalPrice(),
+		RegularPrice: product.BasePrice,
+		Promotion:    newProductPagePromo(product.Promotion),
+		Variants:     make([]productPageVariant, 0, len(product.Variants)),
+	}
+	for _, v := range product.Variants {
+		tiers := v.PriceTiers
+		if tiers == nil {
+			tiers = []models.PriceTier{}
+		}
+		pageData.Variants = append(pageData.Variants, productPageVariant{
+			ID:           v.ID,
+			Color:        v.Color,
+			Price:        v.FinalPrice(product.BasePrice),
+			RegularPrice: v.RegularPrice(product.BasePrice),
+			Promotion:    newProductPagePromo(v.Promotion),
+			PriceTiers:   tiers,
+		})
+	}
+
+	canonicalURL := h.absoluteURL(c, product.URL())
+	metaImage := product.MainPhotoURL
+	if metaImage == "" {
+		metaImage = h.origin(c) + defaultShareImage
+	}
+
 	// Render template
 	return c.Render("pages/product-detail", fiber.Map{
-		"Title":          product.Title,
-		"ContentBlock":   "product-detail-content",
-		"Product":        product,
-		"WhatsAppNumber": h.whatsAppNumber,
-		"StoreAddress":   h.storeAddress,
+		"Title":           product.Title,
+		"ContentBlock":    "product-detail-content",
+		"Product":         product,
+		"Breadcrumbs":     breadcrumbs,
+		"Reviews":         reviews,
+		"ProductData":     pageData,
+		"ProductSchema":   newProductSchema(product, canonicalURL),
+		"MetaDescription": metaDescription(product.Description),
+		"MetaImage":       metaImage,
+		"MetaType":        "product",
+		"CanonicalURL":    canonicalURL,
+		"Customer":        currentCustomer(c),
 	}, "layouts/base")
 }
 
 // SearchProducts handles product search (htmx partial)
 func (h *PublicHandler) SearchProducts(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Parse search query from form
 	query := c.FormValue("q")
@@ -128,20 +425,103 @@ func (h *PublicHandler) SearchProducts(c *fiber.Ctx) error {
 	}
 
 	// Search products
-	products, err := h.productService.Search(ctx, query)
+	result, err := h.productService.Search(ctx, query)
 	if err != nil {
 		return c.Status(500).SendString("Search failed")
 	}
+	markWishlisted(c, h.wishlistService, result.Products)
 
 	// Return HTML partial for htmx
 	return c.Render("partials/product-grid", fiber.Map{
-		"Products": products,
+		"Products":   result.Products,
+		"Suggestion": result.Suggestion,
+	})
+}
+
+// Search-as-you-type limits
+const (
+	suggestProductLimit  = 6
+	suggestCategoryLimit = 3
+)
+
+// searchSuggestion is the JSON shape of /search/suggest
+type searchSuggestion struct {
+	Query      string                     `json:"query"`
+	Products   []searchSuggestionProduct  `json:"products"`
+	Categories []searchSuggestionCategory `json:"categories"`
+}
+
+type searchSuggestionProduct struct {
+	Code         string  `json:"code"`
+	Title        string  `json:"title"`
+	URL          string  `json:"url"`
+	Thumbnail    string  `json:"thumbnail"`
+	Price        float64 `json:"price"`
+	RegularPrice float64 `json:"regularPrice"`
+	IsSold       bool    `json:"isSold"`
+}
+
+type searchSuggestionCategory struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// SearchSuggest returns search-as-you-type suggestions for ?q=: the dropdown fragment
+// by default, or JSON when asked for with ?format=json or an Accept header
+func (h *PublicHandler) SearchSuggest(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		query = ""
+	}
+
+	products, err := h.productService.Suggest(ctx, query, suggestProductLimit)
+	if err != nil {
+		return c.Status(500).SendString("Search failed")
+	}
+	categories, err := h.categoryService.Suggest(ctx, query, suggestCategoryLimit)
+	if err != nil {
+		return c.Status(500).SendString("Search failed")
+	}
+
+	if c.Query("format") == "json" || c.Accepts("text/html", "application/json") == "application/json" {
+		result := searchSuggestion{
+			Query:      query,
+			Products:   make([]searchSuggestionProduct, 0, len(products)),
+			Categories: make([]searchSuggestionCategory, 0, len(categories)),
+		}
+		for i := range products {
+			p := &products[i]
+			result.Products = append(result.Products, searchSuggestionProduct{
+				Code:         p.Code,
+				Title:        p.Title,
+				URL:          p.URL(),
+				Thumbnail:    p.MainPhotoURL,
+				Price:        p.FinalPrice(),
+				RegularPrice: p.BasePrice,
+				IsSold:       p.IsSold,
+			})
+		}
+		for _, category := range categories {
+			result.Categories = append(result.Categories, searchSuggestionCategory{
+				Name: category.Name,
+				URL:  category.URL(),
+			})
+		}
+		return c.JSON(result)
+	}
+
+	return c.Render("partials/search-suggestions", fiber.Map{
+		"Query":      query,
+		"Products":   products,
+		"Categories": categories,
 	})
 }
 
 // FilterProducts handles product filtering (htmx partial)
 func (h *PublicHandler) FilterProducts(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Parse filter parameters (from form or query)
 	filters := h.parseFilters(c)
@@ -193,9 +573,18 @@ func (h *PublicHandler) FilterProducts(c *fiber.Ctx) error {
 			filters.IsSale = &isSale
 		}
 
+		// Parse colour from form
+		if color := strings.TrimSpace(c.FormValue("color")); color != "" {
+			filters.Color = color
+		}
+		if family, ok := models.FindColorFamily(c.FormValue("color_family")); ok {
+			filters.ColorFamily = family.Key
+		}
+
 		// Parse sort from form
 		if sort := c.FormValue("sort"); sort != "" {
 			validSorts := map[string]bool{
+				"relevance":  true,
 				"newest":     true,
 				"price_asc":  true,
 				"price_desc": true,
@@ -204,18 +593,32 @@ func (h *PublicHandler) FilterProducts(c *fiber.Ctx) error {
 			if validSorts[sort] {
 				filters.SortBy = sort
 			}
+		} else if filters.SearchQuery != "" && c.Query("sort", "") == "" {
+			filters.SortBy = "relevance"
 		}
 	}
 
-	// Get products with filters
+	// Get products with filters; the sidebar counts are refreshed out-of-band
+	filters.WithFacets = true
 	result, err := h.productService.GetAll(ctx, filters)
 	if err != nil {
 		return c.Status(500).SendString("Failed to filter products")
 	}
+	markWishlisted(c, h.wishlistService, result.Products)
+
+	categories, err := h.categoryService.GetTree(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load categories")
+	}
 
 	// Return HTML partial for htmx
 	return c.Render("partials/product-grid", fiber.Map{
-		"Products": result.Products,
+		"Products":   result.Products,
+		"Suggestion": result.Suggestion,
+		"Categories": categories,
+		"Filters":    filters,
+		"Facets":     result.Facets,
+		"FacetsOOB":  true,
 		"Pagination": fiber.Map{
 			"CurrentPage": result.Page,
 			"TotalPages":  result.TotalPages,
@@ -282,9 +685,18 @@ func (h *PublicHandler) parseFilters(c *fiber.Ctx) repositories.ProductFilters {
 		filters.IsSale = &isSale
 	}
 
+	// Parse colour filter
+	if color := strings.TrimSpace(c.Query("color", "")); color != "" {
+		filters.Color = color
+	}
+	if family, ok := models.FindColorFamily(c.Query("color_family", "")); ok {
+		filters.ColorFamily = family.Key
+	}
+
 	// Parse sort
 	if sort := c.Query("sort", ""); sort != "" {
 		validSorts := map[string]bool{
+			"relevance":  true,
 			"newest":     true,
 			"price_asc":  true,
 			"price_desc": true,
@@ -303,5 +715,53 @@ func (h *PublicHandler) parseFilters(c *fiber.Ctx) repositories.ProductFilters {
 		filters.SearchQuery = q
 	}
 
+	// Search results are ranked unless another order was asked for
+	if filters.SearchQuery != "" && c.Query("sort", "") == "" {
+		filters.SortBy = "relevance"
+	}
+
 	return filters
 }
+
+// absoluteURL joins path, in the page's language, to the public origin
+func (h *PublicHandler) absoluteURL(c *fiber.Ctx, path string) string {
+	return h.origin(c) + localePath(c, path)
+}
+
+// canonicalPage returns the canonical URL of one page of a catalog listing. Filters
+// and sorting are left out, so their variations count as the same page.
+func (h *PublicHandler) canonicalPage(c *fiber.Ctx, path string, page int) string {
+	url := h.absoluteURL(c, path)
+	if page > 1 {
+		url += "?page=" + strconv.Itoa(page)
+	}
+	return url
+}
+
+// origin returns the configured public origin, or the request's own when BASE_URL is not set
+func (h *PublicHandler) origin(c *fiber.Ctx) string {
+	if h.baseURL != "" {
+		return h.baseURL
+	}
+	return c.BaseURL()
+}
+
+// localePath returns a storefront path in the language of the request
+func localePath(c *fiber.Ctx, path string) string {
+	return i18n.FromContext(c.UserContext()).Path(path)
+}
+
+// metaDescription shortens text to fit a search result snippet, cutting at a word boundary
+func metaDescription(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	const limit = 160
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > limit/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
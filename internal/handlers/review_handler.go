@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// ReviewHandler handles the review form on the public site and the admin
+// moderation queue
+type ReviewHandler struct {
+	reviewService     *services.ReviewService
+	productService    *services.ProductService
+	cloudinaryService *services.CloudinaryService
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(reviewService *services.ReviewService, productService *services.ProductService, cloudinaryService *services.CloudinaryService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService:     reviewService,
+		productService:    productService,
+		cloudinaryService: cloudinaryService,
+	}
+}
+
+// ReviewForm renders the review form of a product
+func (h *ReviewHandler) ReviewForm(c *fiber.Ctx) error {
+	product, err := h.productService.GetBySlug(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return c.Status(404).SendString("Product not found")
+	}
+	if product.Slug != c.Params("slug") {
+		return c.Redirect(localePath(c, product.URL()+"/ulasan"), fiber.StatusMovedPermanently)
+	}
+
+	input := services.ReviewSubmission{}
+	if customer := currentCustomer(c); customer != nil {
+		input.AuthorName = customer.Name
+		input.City = customer.City
+	}
+
+	success := ""
+	if c.Query("terkirim") != "" {
+		success = "Terima kasih! Ulasan Anda akan tampil setelah diperiksa oleh tim kami."
+	}
+
+	return h.renderForm(c, product, input, "", success)
+}
+
+// SubmitReview stores a review in the moderation queue. Submissions that filled in
+// the hidden honeypot field are treated as spam: they get the same thank-you page
+// but nothing is saved.
+func (h *ReviewHandler) SubmitReview(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	product, err := h.productService.GetBySlug(ctx, c.Params("slug"))
+	if err != nil {
+		return c.Status(404).SendString("Product not found")
+	}
+	thanks := localePath(c, product.URL()+"/ulasan?terkirim=1")
+
+	if c.FormValue("website") != "" {
+		return c.Redirect(thanks)
+	}
+
+	input := services.ReviewSubmission{
+		ProductID:  product.ID,
+		AuthorName: c.FormValue("author_name"),
+		City:       c.FormValue("city"),
+		Body:       c.FormValue("body"),
+	}
+	input.Rating, _ = strconv.Atoi(c.FormValue("rating"))
+
+	args := c.Request().PostArgs()
+	for _, raw := range args.PeekMulti("photo_url") {
+		input.PhotoURLs = append(input.PhotoURLs, string(raw))
+	}
+	for _, raw := range args.PeekMulti("photo_id") {
+		input.PhotoIDs = append(input.PhotoIDs, string(raw))
+	}
+
+	if _, err := h.reviewService.Submit(ctx, input, c.IP()); err != nil {
+		// Photos are uploaded again if the form has to be filled in again
+		input.PhotoURLs, input.PhotoIDs = nil, nil
+		return h.renderForm(c, product, input, reviewErrorMessage(err), "")
+	}
+
+	return c.Redirect(thanks)
+}
+
+// SignPhoto returns signed parameters for uploading one review photo directly to
+// Cloudinary (JSON)
+func (h *ReviewHandler) SignPhoto(c *fiber.Ctx) error {
+	params, err := h.cloudinaryService.GenerateReviewUpload()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": i18n.T(i18n.FromContext(c.UserContext()), "Upload foto sedang tidak tersedia")})
+	}
+	return c.JSON(params)
+}
+
+// ListReviews renders the moderation queue, pending reviews first
+func (h *ReviewHandler) ListReviews(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	filters := repositories.ReviewFilters{
+		Status:   c.Query("status", string(models.ReviewPending)),
+		Page:     c.QueryInt("page", 1),
+		PageSize: 20,
+	}
+	if filters.Status == "all" {
+		filters.Status = ""
+	}
+
+	result, err := h.reviewService.GetAll(ctx, filters)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load reviews")
+	}
+
+	counts, err := h.reviewService.CountByStatus(ctx)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load reviews")
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	status := filters.Status
+	if status == "" {
+		status = "all"
+	}
+
+	return c.Render("pages/admin/reviews", fiber.Map{
+		"Title":        "Reviews",
+		"Reviews":      result.Reviews,
+		"Status":       status,
+		"Statuses":     models.ReviewStatuses,
+		"StatusCounts": counts,
+		"TotalCount":   total,
+		"Pagination": fiber.Map{
+			"CurrentPage": result.Page,
+			"TotalPages":  result.TotalPages,
+			"Total":       result.Total,
+			"PageSize":    result.PageSize,
+		},
+		"Success":      c.Query("success", ""),
+		"Error":        c.Query("error", ""),
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "reviews",
+		"ContentBlock": "admin-content-reviews",
+	}, "layouts/admin")
+}
+
+// ModerateReview approves, rejects or re-queues a review
+func (h *ReviewHandler) ModerateReview(c *fiber.Ctx) error {
+	back := "/admin/reviews?status=" + url.QueryEscape(c.FormValue("return_status", string(models.ReviewPending)))
+
+	reviewID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || reviewID <= 0 {
+		return c.Status(400).SendString("Invalid review ID")
+	}
+
+	review, err := h.reviewService.Moderate(c.Context(), reviewID, models.ReviewStatus(c.FormValue("status")))
+	if err != nil {
+		return c.Redirect(back + "&error=" + url.QueryEscape(err.Error()))
+	}
+
+	return c.Redirect(back + "&success=" + url.QueryEscape("Review by "+review.AuthorName+" marked as "+review.Status.Label()))
+}
+
+// DeleteReview deletes a review with its photos
+func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
+	back := "/admin/reviews?status=" + url.QueryEscape(c.FormValue("return_status", string(models.ReviewPending)))
+
+	reviewID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || reviewID <= 0 {
+		return c.Status(400).SendString("Invalid review ID")
+	}
+
+	if err := h.reviewService.Delete(c.Context(), reviewID); err != nil {
+		return c.Redirect(back + "&error=" + url.QueryEscape(err.Error()))
+	}
+
+	return c.Redirect(back + "&success=" + url.QueryEscape("Review deleted successfully"))
+}
+
+// renderForm renders the review form page
+func (h *ReviewHandler) renderForm(c *fiber.Ctx, product *models.Product, input services.ReviewSubmission, errMsg, success string) error {
+	locale := i18n.FromContext(c.UserContext())
+	return c.Render("pages/review-form", fiber.Map{
+		"Title":           i18n.T(locale, "Ulas %s", product.Title),
+		"ContentBlock":    "review-form-content",
+		"Product":         product,
+		"Input":           input,
+		"MaxPhotos":       models.MaxReviewPhotos,
+		"Error":           errMsg,
+		"Success":         success,
+		"CSRFToken":       getCSRFToken(c),
+		"MetaDescription": i18n.T(locale, "Tulis ulasan untuk %s", product.Title),
+	}, "layouts/base")
+}
+
+// reviewErrorMessage returns the visitor-facing text for a review error
+func reviewErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, services.ErrReviewInvalidName):
+		return "Nama harus 2 sampai 100 karakter"
+	case errors.Is(err, services.ErrReviewCityTooLong):
+		return "Kota maksimal 100 karakter"
+	case errors.Is(err, services.ErrReviewInvalidRating):
+		return "Pilih rating 1 sampai 5 bintang"
+	case errors.Is(err, services.ErrReviewInvalidBody):
+		return "Ulasan harus 10 sampai 2000 karakter"
+	case errors.Is(err, services.ErrReviewTooManyPhotos):
+		return "Foto terlalu banyak untuk satu ulasan"
+	case errors.Is(err, services.ErrReviewInvalidPhoto):
+		return "Foto tidak valid, silakan unggah ulang"
+	case errors.Is(err, services.ErrReviewProductNotFound):
+		return "Produk tidak ditemukan"
+	}
+	return "Terjadi kesalahan, silakan coba lagi"
+}
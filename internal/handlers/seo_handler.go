@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// seoCacheControl lets crawlers and proxies reuse the sitemap and robots.txt for an hour
+const seoCacheControl = "public, max-age=3600"
+
+// sitemapIndex is the /sitemap.xml document pointing at the sitemap pages
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapURLSet is one sitemap page; every URL names its versions in the other languages
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	XHTML   string       `xml:"xmlns:xhtml,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// SEOHandler serves the sitemap and robots.txt
+type SEOHandler struct {
+	sitemapService *services.SitemapService
+	baseURL        string
+	disallow       []string
+	noIndex        bool
+}
+
+// NewSEOHandler creates a new SEO handler
+func NewSEOHandler(sitemapService *services.SitemapService, baseURL string, disallow []string, noIndex bool) *SEOHandler {
+	return &SEOHandler{
+		sitemapService: sitemapService,
+		baseURL:        baseURL,
+		disallow:       disallow,
+		noIndex:        noIndex,
+	}
+}
+
+// Sitemap serves the sitemap index: one sitemap for the landing and category pages
+// and one per SitemapPageSize products
+func (h *SEOHandler) Sitemap(c *fiber.Ctx) error {
+	pages, err := h.sitemapService.ProductPages()
+	if err != nil {
+		return c.Status(500).SendString("Failed to build sitemap")
+	}
+
+	base := h.origin(c)
+	index := sitemapIndex{
+		Xmlns:    "http://www.sitemaps.org/schemas/sitemap/0.9",
+		Sitemaps: []sitemapRef{{Loc: base + "/sitemap-pages.xml"}},
+	}
+	for page := 1; page <= pages; page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: base + "/sitemap-products-" + strconv.Itoa(page) + ".xml"})
+	}
+
+	return h.sendXML(c, index)
+}
+
+// SitemapPages serves the sitemap of the landing and category pages
+func (h *SEOHandler) SitemapPages(c *fiber.Ctx) error {
+	entries, err := h.sitemapService.Pages()
+	if err != nil {
+		return c.Status(500).SendString("Failed to build sitemap")
+	}
+	return h.sendXML(c, h.urlSet(c, entries))
+}
+
+// SitemapProducts serves one page of the product sitemap
+func (h *SEOHandler) SitemapProducts(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Params("page"))
+	if err != nil {
+		return c.Status(404).SendString("Sitemap not found")
+	}
+
+	entries, err := h.sitemapService.Products(page)
+	if err != nil {
+		if errors.Is(err, services.ErrSitemapPageNotFound) {
+			return c.Status(404).SendString("Sitemap not found")
+		}
+		return c.Status(500).SendString("Failed to build sitemap")
+	}
+	return h.sendXML(c, h.urlSet(c, entries))
+}
+
+// Robots serves robots.txt. Storefront paths are disallowed under every language
+// prefix; ROBOTS_NOINDEX shuts crawlers out entirely.
+func (h *SEOHandler) Robots(c *fiber.Ctx) error {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+
+	if h.noIndex {
+		b.WriteString("Disallow: /\n")
+	} else {
+		seen := make(map[string]bool)
+		for _, path := range h.disallow {
+			for _, locale := range i18n.Locales {
+				localized := locale.Path(path)
+				if !seen[localized] {
+					seen[localized] = true
+					b.WriteString("Disallow: " + localized + "\n")
+				}
+			}
+		}
+		b.WriteString("\nSitemap: " + h.origin(c) + "/sitemap.xml\n")
+	}
+
+	c.Set(fiber.HeaderCacheControl, seoCacheControl)
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(b.String())
+}
+
+// urlSet lists every entry once per language, each naming all its versions
+func (h *SEOHandler) urlSet(c *fiber.Ctx, entries []services.SitemapEntry) sitemapURLSet {
+	base := h.origin(c)
+	set := sitemapURLSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XHTML: "http://www.w3.org/1999/xhtml",
+		URLs:  make([]sitemapURL, 0, len(entries)*len(i18n.Locales)),
+	}
+
+	for _, entry := range entries {
+		alternates := i18n.Alternates(base, entry.Path, "")
+		links := make([]sitemapAlternate, 0, len(alternates)+1)
+		for _, alternate := range alternates {
+			links = append(links, sitemapAlternate{Rel: "alternate", Hreflang: alternate.Locale.String(), Href: alternate.URL})
+		}
+		links = append(links, sitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: alternates[0].URL})
+
+		var lastMod string
+		if !entry.UpdatedAt.IsZero() {
+			lastMod = entry.UpdatedAt.UTC().Format("2006-01-02")
+		}
+		for _, alternate := range alternates {
+			set.URLs = append(set.URLs, sitemapURL{Loc: alternate.URL, LastMod: lastMod, Alternates: links})
+		}
+	}
+	return set
+}
+
+// sendXML writes doc as a cacheable XML response
+func (h *SEOHandler) sendXML(c *fiber.Ctx, doc interface{}) error {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return c.Status(500).SendString("Failed to build sitemap")
+	}
+
+	c.Set(fiber.HeaderCacheControl, seoCacheControl)
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	return c.Send(append([]byte(xml.Header), body...))
+}
+
+// origin returns the configured public origin, or the request's own
+func (h *SEOHandler) origin(c *fiber.Ctx) string {
+	if h.baseURL != "" {
+		return h.baseURL
+	}
+	return c.BaseURL()
+}
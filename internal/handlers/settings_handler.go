@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// SettingsHandler handles the admin store settings page
+type SettingsHandler struct {
+	settingsService *services.SettingsService
+}
+
+// NewSettingsHandler creates a new settings handler
+func NewSettingsHandler(settingsService *services.SettingsService) *SettingsHandler {
+	return &SettingsHandler{
+		settingsService: settingsService,
+	}
+}
+
+// EditSettings renders the store settings form
+func (h *SettingsHandler) EditSettings(c *fiber.Ctx) error {
+	settings := h.settingsService.Get()
+	return h.renderForm(c, &settings, "")
+}
+
+// UpdateSettings saves the store settings; the storefront shows them on the next request
+func (h *SettingsHandler) UpdateSettings(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	settings := &models.StoreSettings{
+		Name:           c.FormValue("name"),
+		Address:        c.FormValue("address"),
+		WhatsAppNumber: c.FormValue("whatsapp_number"),
+		ShopeeLink:     c.FormValue("shopee_link"),
+		TiktokLink:     c.FormValue("tiktok_link"),
+		InstagramLink:  c.FormValue("instagram_link"),
+	}
+	if err := h.settingsService.Update(ctx, settings); err != nil {
+		return h.renderForm(c, settings, err.Error())
+	}
+
+	return c.Redirect("/admin/settings?success=" + url.QueryEscape("Store settings saved successfully"))
+}
+
+// renderForm renders the store settings form
+func (h *SettingsHandler) renderForm(c *fiber.Ctx, settings *models.StoreSettings, errMsg string) error {
+	return c.Render("pages/admin/settings", fiber.Map{
+		"Title":        "Store Settings",
+		"Settings":     settings,
+		"Success":      c.Query("success", ""),
+		"Error":        errMsg,
+		"CSRFToken":    getCSRFToken(c),
+		"CurrentPage":  "settings",
+		"ContentBlock": "admin-content-settings",
+	}, "layouts/admin")
+}
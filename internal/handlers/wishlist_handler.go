@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// visitorCookie holds the anonymous visitor token of the wishlist and recently viewed products
+const visitorCookie = "visitor"
+
+// WishlistHandler handles the public wishlist and recently viewed routes
+type WishlistHandler struct {
+	wishlistService *services.WishlistService
+	baseURL         string
+	env             string
+}
+
+// NewWishlistHandler creates a new wishlist handler
+func NewWishlistHandler(wishlistService *services.WishlistService, baseURL, env string) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+		baseURL:         baseURL,
+		env:             env,
+	}
+}
+
+// ShowWishlist renders the wishlist page with the share action
+func (h *WishlistHandler) ShowWishlist(c *fiber.Ctx) error {
+	products, err := h.wishlistService.Products(c.UserContext(), c.Cookies(visitorCookie))
+	if err != nil {
+		return c.Status(500).SendString("Failed to load wishlist")
+	}
+
+	return c.Render("pages/wishlist", fiber.Map{
+		"Title":        "Wishlist",
+		"ContentBlock": "wishlist-content",
+		"Products":     products,
+	}, "layouts/base")
+}
+
+// Toggle adds a product to the wishlist or removes it. htmx requests get the heart
+// button in its new state; other requests go to the wishlist.
+func (h *WishlistHandler) Toggle(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("productId"))
+	if err != nil || productID <= 0 {
+		return c.Status(404).SendString("Produk tidak ditemukan")
+	}
+
+	token, wishlisted, err := h.wishlistService.Toggle(c.UserContext(), c.Cookies(visitorCookie), productID)
+	if token != "" {
+		h.setCookie(c, token)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrWishlistProductNotFound) {
+			return c.Status(404).SendString("Produk tidak ditemukan")
+		}
+		return c.Status(500).SendString("Gagal memperbarui wishlist, silakan coba lagi")
+	}
+
+	if c.Get("HX-Request") != "true" {
+		return c.Redirect(localePath(c, "/wishlist"))
+	}
+
+	return c.Render("partials/wishlist-button", fiber.Map{
+		"ProductID":  productID,
+		"Wishlisted": wishlisted,
+	})
+}
+
+// Share opens WhatsApp with the whole wishlist as one message, for the shopper to
+// pick the chat to send it to
+func (h *WishlistHandler) Share(c *fiber.Ctx) error {
+	products, err := h.wishlistService.Products(c.UserContext(), c.Cookies(visitorCookie))
+	if err != nil {
+		return c.Status(500).SendString("Failed to load wishlist")
+	}
+	if len(products) == 0 {
+		return c.Redirect(localePath(c, "/wishlist"))
+	}
+
+	baseURL := h.baseURL
+	if baseURL == "" {
+		baseURL = c.BaseURL()
+	}
+
+	return c.Redirect(h.wishlistService.ShareURL(products, baseURL))
+}
+
+// RecentlyViewed renders the recently viewed strip (htmx partial)
+func (h *WishlistHandler) RecentlyViewed(c *fiber.Ctx) error {
+	return h.renderRecentlyViewed(c, c.QueryInt("exclude", 0))
+}
+
+// RecordView records a product page view and renders the strip of the other
+// recently viewed products (htmx partial). Product pages post it on load, so
+// crawlers that do not run scripts leave no visitors behind.
+func (h *WishlistHandler) RecordView(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("productId"))
+	if err != nil || productID <= 0 {
+		return c.Status(404).SendString("Produk tidak ditemukan")
+	}
+
+	// Best effort: a view that failed to save must not hide the strip
+	token, _ := h.wishlistService.RecordView(c.UserContext(), c.Cookies(visitorCookie), productID)
+	if token != "" {
+		h.setCookie(c, token)
+	}
+
+	return h.renderRecentlyViewed(c, productID)
+}
+
+// renderRecentlyViewed renders the recently viewed products other than excludeID
+func (h *WishlistHandler) renderRecentlyViewed(c *fiber.Ctx, excludeID int) error {
+	products, err := h.wishlistService.RecentlyViewed(c.UserContext(), c.Cookies(visitorCookie), excludeID)
+	if err != nil {
+		return c.Status(500).SendString("Failed to load recently viewed products")
+	}
+
+	return c.Render("partials/recently-viewed", fiber.Map{
+		"Products": products,
+	})
+}
+
+// setCookie keeps the visitor token for VisitorLifetime after the last change
+func (h *WishlistHandler) setCookie(c *fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     visitorCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  time.Now().Add(services.VisitorLifetime),
+		HTTPOnly: true,
+		Secure:   h.env == "production",
+		SameSite: "Lax", // Sent when arriving from a shared link, not on cross-site posts
+	})
+}
+
+// markWishlisted flags the products on the shopper's wishlist so their cards show
+// a filled heart. A failed lookup only leaves the hearts empty.
+func markWishlisted(c *fiber.Ctx, wishlistService *services.WishlistService, products []models.Product) {
+	if len(products) == 0 {
+		return
+	}
+
+	ids, err := wishlistService.IDs(c.UserContext(), c.Cookies(visitorCookie))
+	if err != nil {
+		return
+	}
+	for i := range products {
+		products[i].Wishlisted = ids[products[i].ID]
+	}
+}
@@ -0,0 +1,46 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+)
+
+// catalogFiles holds one JSON catalog per translated locale, mapping the
+// Indonesian text of a message to its translation
+//
+//go:embed locales/*.json
+var catalogFiles embed.FS
+
+// catalogs are the parsed message catalogs by locale
+var catalogs = loadCatalogs()
+
+// loadCatalogs parses the embedded catalogs; a broken catalog stops the server at boot
+func loadCatalogs() map[Locale]map[string]string {
+	loaded := make(map[Locale]map[string]string, len(Translated))
+	for _, locale := range Translated {
+		data, err := catalogFiles.ReadFile("locales/" + string(locale) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %q: %v", locale, err))
+		}
+
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %q: %v", locale, err))
+		}
+		loaded[locale] = messages
+	}
+	return loaded
+}
+
+// T translates an Indonesian message into locale, falling back to the Indonesian
+// text. Messages with args are fmt.Sprintf formats ("%d ulasan").
+func T(locale Locale, message string, args ...interface{}) string {
+	if translated, ok := catalogs[locale][message]; ok && translated != "" {
+		message = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
@@ -0,0 +1,184 @@
+// Package i18n translates the storefront. Indonesian is the source language: the
+// templates are written in it and its text is the message key, so anything missing
+// from a catalog shows in Indonesian.
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a storefront language
+type Locale string
+
+const (
+	ID Locale = "id" // Indonesian; its pages have no URL prefix
+	EN Locale = "en" // English, served under /en
+)
+
+// Default is the language of unprefixed pages and of visitors with no preference
+const Default = ID
+
+// Locales lists the storefront languages, the default first
+var Locales = []Locale{ID, EN}
+
+// Translated lists the languages that have product and category translations:
+// every locale except the default, whose text lives on the records themselves
+var Translated = []Locale{EN}
+
+// Label returns the language's own name, for the language switcher
+func (l Locale) Label() string {
+	switch l {
+	case ID:
+		return "Bahasa Indonesia"
+	case EN:
+		return "English"
+	}
+	return string(l)
+}
+
+// Territory returns the language with its region as OpenGraph writes it ("id_ID")
+func (l Locale) Territory() string {
+	switch l {
+	case ID:
+		return "id_ID"
+	case EN:
+		return "en_US"
+	}
+	return string(l)
+}
+
+// String returns the locale code
+func (l Locale) String() string {
+	return string(l)
+}
+
+// Prefix returns the URL prefix of the locale's pages ("" for the default)
+func (l Locale) Prefix() string {
+	if l == Default || l == "" {
+		return ""
+	}
+	return "/" + string(l)
+}
+
+// Path returns path under the locale's URL prefix
+func (l Locale) Path(path string) string {
+	if prefix := l.Prefix(); prefix != "" {
+		if path == "/" {
+			return prefix
+		}
+		return prefix + path
+	}
+	return path
+}
+
+// Parse returns the locale named by s, ignoring case and any region ("en-US")
+func Parse(s string) (Locale, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+	for _, locale := range Locales {
+		if s == string(locale) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the supported locale an Accept-Language header prefers most,
+// or the default
+func Negotiate(header string) Locale {
+	type choice struct {
+		locale Locale
+		q      float64
+	}
+
+	var choices []choice
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		locale, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			choices = append(choices, choice{locale, q})
+		}
+	}
+	if len(choices) == 0 {
+		return Default
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].locale
+}
+
+// SplitPath separates a locale prefix from a request path: "/en/p/pita" gives EN
+// and "/p/pita". ok is false for paths without a prefix, returned unchanged.
+func SplitPath(path string) (locale Locale, rest string, ok bool) {
+	for _, candidate := range Locales {
+		prefix := "/" + string(candidate)
+		if path == prefix {
+			return candidate, "/", true
+		}
+		if strings.HasPrefix(path, prefix+"/") {
+			return candidate, path[len(prefix):], true
+		}
+	}
+	return "", path, false
+}
+
+// Alternate is the current page in one of the storefront languages
+type Alternate struct {
+	Locale Locale
+	URL    string // Absolute address of the page in this language, for hreflang links
+	Switch string // Path that switches the visitor to this language on this page
+}
+
+// Alternates lists a page, given by its unprefixed path and query, in every locale
+func Alternates(baseURL, path, query string) []Alternate {
+	if query != "" {
+		query = "?" + query
+	}
+
+	alternates := make([]Alternate, 0, len(Locales))
+	for _, locale := range Locales {
+		switchPath := "/" + string(locale) + path
+		if path == "/" {
+			switchPath = "/" + string(locale)
+		}
+		alternates = append(alternates, Alternate{
+			Locale: locale,
+			URL:    baseURL + locale.Path(path),
+			Switch: switchPath + query,
+		})
+	}
+	return alternates
+}
+
+// localeKey is the request context key of the storefront language
+type localeKey struct{}
+
+// WithLocale returns a context whose catalog reads are translated into locale
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale carried by the context, or the default
+func FromContext(ctx context.Context) Locale {
+	if ctx == nil {
+		return Default
+	}
+	if locale, ok := ctx.Value(localeKey{}).(Locale); ok {
+		return locale
+	}
+	return Default
+}
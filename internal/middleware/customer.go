@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// CustomerCookie holds a signed-in reseller's token
+const CustomerCookie = "customer_token"
+
+// CustomerSession loads the reseller signed in with the customer_token cookie.
+// Approved customers are stored in locals ("customer") and in the request's user
+// context, which prices the catalog for their group; everyone else browses with
+// public prices. Invalid tokens and accounts no longer approved are signed out.
+func CustomerSession(customerService *services.CustomerService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Cookies(CustomerCookie)
+		if token == "" {
+			return c.Next()
+		}
+
+		customer, err := customerService.Authenticate(c.UserContext(), token)
+		if err != nil {
+			// A database hiccup shows public prices for this request only
+			if errors.Is(err, services.ErrCustomerSessionInvalid) {
+				c.ClearCookie(CustomerCookie)
+			}
+			return c.Next()
+		}
+
+		c.Locals("customer", customer)
+		c.SetUserContext(services.WithCustomer(c.UserContext(), customer))
+
+		return c.Next()
+	}
+}
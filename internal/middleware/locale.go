@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
+)
+
+// LocaleCookie remembers the storefront language a visitor last chose
+const LocaleCookie = "lang"
+
+// Locale picks the storefront language. A /en prefix serves the English pages
+// (routes are registered under the prefix too) and remembers the choice; /id/...
+// remembers Indonesian and redirects to the unprefixed page. Other requests use
+// the remembered choice, then Accept-Language, then Indonesian.
+//
+// The locale is stored in the request's user context for the services and bound
+// to the views as "Locale", with "Alternates" listing the page in every language.
+func Locale(baseURL, env string) fiber.Handler {
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return func(c *fiber.Ctx) error {
+		locale, path, prefixed := i18n.SplitPath(c.Path())
+		query := string(c.Request().URI().QueryString())
+
+		if prefixed {
+			if c.Cookies(LocaleCookie) != locale.String() {
+				c.Cookie(&fiber.Cookie{
+					Name:     LocaleCookie,
+					Value:    locale.String(),
+					Path:     "/",
+					Expires:  time.Now().Add(365 * 24 * time.Hour),
+					HTTPOnly: true,
+					Secure:   env == "production",
+					SameSite: "Lax",
+				})
+			}
+			// The default language has no prefix of its own
+			if locale == i18n.Default {
+				path = "/" + strings.TrimLeft(path, "/") // never a protocol-relative URL
+				if query != "" {
+					path += "?" + query
+				}
+				return c.Redirect(path)
+			}
+		} else {
+			if remembered, ok := i18n.Parse(c.Cookies(LocaleCookie)); ok {
+				locale = remembered
+			} else {
+				locale = i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+			}
+			c.Vary(fiber.HeaderAcceptLanguage, fiber.HeaderCookie)
+		}
+
+		base := baseURL
+		if base == "" {
+			base = c.BaseURL()
+		}
+
+		c.SetUserContext(i18n.WithLocale(c.UserContext(), locale))
+		if err := c.Bind(fiber.Map{
+			"Locale":     locale,
+			"Alternates": i18n.Alternates(base, path, query),
+		}); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rizkysr90/aslam-flower/internal/services"
+)
+
+// StoreSettings binds the store settings to the views as "Store", the one place
+// templates read the store name, address, WhatsApp number and social links from
+func StoreSettings(settingsService *services.SettingsService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Bind(fiber.Map{"Store": settingsService.Get()}); err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import "time"
+
+// MaxBasketQuantity caps the quantity of a single basket line
+const MaxBasketQuantity = 9999
+
+// Basket is a visitor's inquiry basket, identified by the token in their cookie
+type Basket struct {
+	ID        int       `db:"id" json:"id"`
+	Token     string    `db:"token" json:"-"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Relations (not in DB)
+	Lines []BasketLine `db:"-" json:"lines"`
+}
+
+// BasketItem is one stored basket line
+type BasketItem struct {
+	ID        int       `db:"id" json:"id"`
+	BasketID  int       `db:"basket_id" json:"basket_id"`
+	ProductID int       `db:"product_id" json:"product_id"`
+	VariantID *int      `db:"variant_id" json:"variant_id,omitempty"`
+	Quantity  int       `db:"quantity" json:"quantity"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// BasketLine is a basket item with the product and variant it is priced from
+type BasketLine struct {
+	BasketItem
+	Product *Product        `json:"product"`
+	Variant *ProductVariant `json:"variant,omitempty"`
+}
+
+// Label names the line for display and the WhatsApp message ("Title - Colour")
+func (l *BasketLine) Label() string {
+	if l.Variant != nil {
+		return l.Product.Title + " - " + l.Variant.Color
+	}
+	return l.Product.Title
+}
+
+// UnitPrice returns the per-unit price for the line's quantity, including live
+// promotions and wholesale tiers of the variant
+func (l *BasketLine) UnitPrice() float64 {
+	if l.Variant != nil {
+		return l.Variant.UnitPrice(l.Product.BasePrice, l.Quantity)
+	}
+	return l.Product.FinalPrice()
+}
+
+// HasGroupPrice reports whether the line is charged the signed-in reseller's group
+// price rather than a wholesale tier or the public price
+func (l *BasketLine) HasGroupPrice() bool {
+	if l.Variant != nil {
+		if l.Variant.GroupPrice == nil {
+			return false
+		}
+		tier := l.Variant.TierFor(l.Quantity)
+		return tier == nil || tier.UnitPrice >= *l.Variant.GroupPrice
+	}
+	return l.Product.GroupPrice != nil
+}
+
+// Subtotal returns the line total
+func (l *BasketLine) Subtotal() float64 {
+	return l.UnitPrice() * float64(l.Quantity)
+}
+
+// Available reports whether the line's product or variant is in stock
+func (l *BasketLine) Available() bool {
+	if l.Variant != nil {
+		return l.Variant.InStock()
+	}
+	return !l.Product.IsSold
+}
+
+// Total returns the sum of the line subtotals
+func (b *Basket) Total() float64 {
+	total := 0.0
+	for i := range b.Lines {
+		total += b.Lines[i].Subtotal()
+	}
+	return total
+}
+
+// ItemCount returns the number of units in the basket
+func (b *Basket) ItemCount() int {
+	count := 0
+	for _, line := range b.Lines {
+		count += line.Quantity
+	}
+	return count
+}
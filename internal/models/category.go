@@ -1,12 +1,100 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
-// Category represents a product category
+// Category represents a product category. Categories form a tree through ParentID;
+// Path lists the IDs from the root down to the category itself, e.g. "/1/4/9/".
 type Category struct {
-	ID        int       `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Slug      string    `db:"slug" json:"slug"`
+	ID       int    `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	Slug     string `db:"slug" json:"slug"`
+	ParentID *int   `db:"parent_id" json:"parent_id,omitempty"`
+	Path     string `db:"path" json:"path"`
+
+	// Public category page content
+	Title       string `db:"title" json:"title"` // Page title; falls back to Name
+	Description string `db:"description" json:"description"`
+	BannerURL   string `db:"banner_url" json:"banner_url"`
+	BannerID    string `db:"banner_id" json:"banner_id"`
+
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Relations (not in DB)
+	Children []Category `db:"-" json:"children,omitempty"`
+
+	// Other languages, loaded for the admin form only
+	Translations []CategoryTranslation `db:"-" json:"translations,omitempty"`
+}
+
+// Translation returns the category's text in locale, blank when it has none
+func (c *Category) Translation(locale string) CategoryTranslation {
+	for _, translation := range c.Translations {
+		if translation.Locale == locale {
+			return translation
+		}
+	}
+	return CategoryTranslation{CategoryID: c.ID, Locale: locale}
+}
+
+// PageTitle returns the title for the category page
+func (c *Category) PageTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return c.Name
+}
+
+// URL returns the public category page path
+func (c *Category) URL() string {
+	return "/kategori/" + c.Slug
+}
+
+// Depth returns how deep the category sits in the tree (0 for a top-level category)
+func (c *Category) Depth() int {
+	return strings.Count(c.Path, "/") - 2
+}
+
+// IsDescendantOf reports whether c sits anywhere below other in the tree
+func (c *Category) IsDescendantOf(other *Category) bool {
+	return other.Path != "" && c.Path != other.Path && strings.HasPrefix(c.Path, other.Path)
+}
+
+// BuildCategoryTree nests a flat list of categories under their parents.
+// Siblings keep the order of the input list.
+func BuildCategoryTree(categories []Category) []Category {
+	children := make(map[int][]Category)
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	return attach(roots)
+}
+
+// FlattenCategoryTree lists a category tree depth-first, parents before their children
+func FlattenCategoryTree(tree []Category) []Category {
+	var flat []Category
+	for _, node := range tree {
+		children := node.Children
+		node.Children = nil
+		flat = append(flat, node)
+		flat = append(flat, FlattenCategoryTree(children)...)
+	}
+	return flat
 }
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Color is a palette colour that variants point at for swatches and filtering.
+// Variants keep their own display label; Name and Aliases are how labels are matched.
+type Color struct {
+	ID        int            `db:"id" json:"id"`
+	Name      string         `db:"name" json:"name"`
+	Aliases   pq.StringArray `db:"aliases" json:"aliases"` // Lowercase alternative labels
+	Hex       string         `db:"hex" json:"hex"`         // "#rrggbb", lowercase
+	Family    string         `db:"family" json:"family"`   // Key of a ColorFamilies entry
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+
+	// Computed (not a column of colors)
+	VariantCount int `db:"variant_count" json:"variant_count"`
+}
+
+// Matches reports whether a variant label names this colour (case-insensitive)
+func (c *Color) Matches(label string) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == strings.ToLower(c.Name) {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if label == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// FamilyLabel returns the display name of the colour's family
+func (c *Color) FamilyLabel() string {
+	if family, ok := FindColorFamily(c.Family); ok {
+		return family.Label
+	}
+	return c.Family
+}
+
+// ColorFamily groups palette colours for the catalog colour filter
+type ColorFamily struct {
+	Key   string
+	Label string
+	Hex   string // Representative swatch
+}
+
+// ColorFamilies lists the colour families in filter order
+var ColorFamilies = []ColorFamily{
+	{Key: "merah", Label: "Merah", Hex: "#dc2626"},
+	{Key: "pink", Label: "Pink", Hex: "#f472b6"},
+	{Key: "oranye", Label: "Oranye", Hex: "#f97316"},
+	{Key: "kuning", Label: "Kuning", Hex: "#facc15"},
+	{Key: "hijau", Label: "Hijau", Hex: "#16a34a"},
+	{Key: "biru", Label: "Biru", Hex: "#2563eb"},
+	{Key: "ungu", Label: "Ungu", Hex: "#7c3aed"},
+	{Key: "coklat", Label: "Coklat", Hex: "#92400e"},
+	{Key: "krem", Label: "Krem", Hex: "#f5e6c8"},
+	{Key: "putih", Label: "Putih", Hex: "#ffffff"},
+	{Key: "hitam", Label: "Hitam", Hex: "#111827"},
+	{Key: "abu", Label: "Abu-abu", Hex: "#9ca3af"},
+	{Key: "emas", Label: "Emas", Hex: "#d4a017"},
+	{Key: "perak", Label: "Perak", Hex: "#c0c0c0"},
+	{Key: "multi", Label: "Multiwarna", Hex: "#e5e7eb"},
+}
+
+// FindColorFamily looks up a colour family by key
+func FindColorFamily(key string) (ColorFamily, bool) {
+	for _, family := range ColorFamilies {
+		if family.Key == key {
+			return family, true
+		}
+	}
+	return ColorFamily{}, false
+}
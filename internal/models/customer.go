@@ -0,0 +1,123 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// CustomerStatus is the review state of a reseller account
+type CustomerStatus string
+
+const (
+	CustomerPending   CustomerStatus = "pending"   // Applied, waiting for an admin
+	CustomerApproved  CustomerStatus = "approved"  // May sign in and sees the group prices
+	CustomerRejected  CustomerStatus = "rejected"  // Application turned down
+	CustomerSuspended CustomerStatus = "suspended" // Approved before, signed out until reinstated
+)
+
+// CustomerStatuses lists all customer statuses in review order
+var CustomerStatuses = []CustomerStatus{
+	CustomerPending,
+	CustomerApproved,
+	CustomerRejected,
+	CustomerSuspended,
+}
+
+// Label returns a human-readable label for the customer status
+func (s CustomerStatus) Label() string {
+	switch s {
+	case CustomerPending:
+		return "Menunggu Persetujuan"
+	case CustomerApproved:
+		return "Disetujui"
+	case CustomerRejected:
+		return "Ditolak"
+	case CustomerSuspended:
+		return "Dinonaktifkan"
+	}
+	return string(s)
+}
+
+// IsValid reports whether s is a known customer status
+func (s CustomerStatus) IsValid() bool {
+	for _, status := range CustomerStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Customer is a reseller account
+type Customer struct {
+	ID           int            `db:"id" json:"id"`
+	Name         string         `db:"name" json:"name"`
+	BusinessName string         `db:"business_name" json:"business_name"`
+	Phone        string         `db:"phone" json:"phone"` // WhatsApp number, used to sign in
+	City         string         `db:"city" json:"city"`
+	PasswordHash string         `db:"password_hash" json:"-"` // Never expose in JSON
+	Status       CustomerStatus `db:"status" json:"status"`
+	GroupID      *int           `db:"group_id" json:"group_id,omitempty"`
+	AdminNotes   string         `db:"admin_notes" json:"-"`
+	ApprovedAt   *time.Time     `db:"approved_at" json:"approved_at,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
+
+	// Joined from customer_groups
+	GroupName *string `db:"group_name" json:"group_name,omitempty"`
+
+	// Relations (not in DB)
+	Group *CustomerGroup `db:"-" json:"group,omitempty"`
+}
+
+// DisplayName returns the business name with the contact name, or the contact name alone
+func (c *Customer) DisplayName() string {
+	if c.BusinessName != "" {
+		return c.BusinessName + " (" + c.Name + ")"
+	}
+	return c.Name
+}
+
+// CustomerGroup prices the catalog for its customers
+type CustomerGroup struct {
+	ID              int       `db:"id" json:"id"`
+	Name            string    `db:"name" json:"name"`
+	Description     string    `db:"description" json:"description"`
+	DiscountPercent float64   `db:"discount_percent" json:"discount_percent"` // Off the regular price of variants without an explicit price
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+
+	// Computed on read
+	CustomerCount int `db:"customer_count" json:"customer_count"`
+
+	// Relations (not in DB)
+	Prices []CustomerGroupPrice `db:"-" json:"prices,omitempty"`
+}
+
+// Apply returns price after the group discount, rounded to whole rupiah
+func (g *CustomerGroup) Apply(price float64) float64 {
+	return math.Max(math.Round(price*(1-g.DiscountPercent/100)), 0)
+}
+
+// CustomerGroupPrice is an explicit unit price of one variant for a group
+type CustomerGroupPrice struct {
+	GroupID   int     `db:"group_id" json:"group_id"`
+	VariantID int     `db:"variant_id" json:"variant_id"`
+	UnitPrice float64 `db:"unit_price" json:"unit_price"`
+
+	// Joined for display
+	ProductID    int     `db:"product_id" json:"product_id"`
+	ProductCode  string  `db:"product_code" json:"product_code"`
+	ProductTitle string  `db:"product_title" json:"product_title"`
+	VariantColor string  `db:"variant_color" json:"variant_color"`
+	RegularPrice float64 `db:"regular_price" json:"regular_price"`
+}
+
+// Label names the priced variant ("CODE · Title - Colour"). Rows echoed back from
+// the admin form only carry that label, in ProductTitle.
+func (p *CustomerGroupPrice) Label() string {
+	if p.VariantColor == "" {
+		return p.ProductTitle
+	}
+	return p.ProductCode + " · " + p.ProductTitle + " - " + p.VariantColor
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DocumentType is the kind of sales document issued to a customer
+type DocumentType string
+
+const (
+	DocumentQuotation DocumentType = "quotation" // Price offer sent before payment
+	DocumentInvoice   DocumentType = "invoice"   // Bill for goods the customer has agreed to buy
+)
+
+// DocumentTypes lists all document types
+var DocumentTypes = []DocumentType{
+	DocumentQuotation,
+	DocumentInvoice,
+}
+
+// Label returns a human-readable label for the document type
+func (t DocumentType) Label() string {
+	switch t {
+	case DocumentQuotation:
+		return "Penawaran Harga"
+	case DocumentInvoice:
+		return "Invoice"
+	}
+	return string(t)
+}
+
+// Prefix returns the number prefix for the document type (e.g. INV)
+func (t DocumentType) Prefix() string {
+	switch t {
+	case DocumentQuotation:
+		return "QUO"
+	case DocumentInvoice:
+		return "INV"
+	}
+	return "DOC"
+}
+
+// DueDateLabel names the document's date field (quotations expire, invoices fall due)
+func (t DocumentType) DueDateLabel() string {
+	if t == DocumentQuotation {
+		return "Berlaku hingga"
+	}
+	return "Jatuh tempo"
+}
+
+// DocumentNumber formats a per-year sequence number (e.g. INV/2026/000123)
+func DocumentNumber(docType DocumentType, year, sequence int) string {
+	return fmt.Sprintf("%s/%d/%06d", docType.Prefix(), year, sequence)
+}
+
+// Document is an issued quotation or invoice. Documents are immutable: the
+// customer, store details, lines and totals are a snapshot taken at issue time.
+type Document struct {
+	ID              int          `db:"id" json:"id"`
+	Type            DocumentType `db:"doc_type" json:"doc_type"`
+	Number          string       `db:"number" json:"number"`
+	Year            int          `db:"year" json:"year"`
+	Sequence        int          `db:"sequence" json:"sequence"`
+	CustomerName    string       `db:"customer_name" json:"customer_name"`
+	CustomerPhone   string       `db:"customer_phone" json:"customer_phone"`
+	CustomerAddress string       `db:"customer_address" json:"customer_address"`
+	StoreName       string       `db:"store_name" json:"store_name"`
+	StoreAddress    string       `db:"store_address" json:"store_address"`
+	Notes           string       `db:"notes" json:"notes"`
+	Subtotal        float64      `db:"subtotal" json:"subtotal"`               // Sum of line totals
+	DiscountAmount  float64      `db:"discount_amount" json:"discount_amount"` // Discount on the whole document
+	Total           float64      `db:"total" json:"total"`
+	DueDate         *time.Time   `db:"due_date" json:"due_date,omitempty"`
+	AdminID         *int         `db:"admin_id" json:"admin_id"`
+	IssuedAt        time.Time    `db:"issued_at" json:"issued_at"`
+
+	// Relations (not in DB)
+	Items []DocumentItem `db:"-" json:"items,omitempty"`
+}
+
+// IssuedLocal returns the issue time in the store's zone, the zone document numbers
+// count years in. issued_at is stored as a UTC wall-clock time.
+func (d *Document) IssuedLocal() time.Time {
+	t := d.IssuedAt
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).In(StoreLocation)
+}
+
+// DocumentItem is one line of a document
+type DocumentItem struct {
+	ID              int     `db:"id" json:"id"`
+	DocumentID      int     `db:"document_id" json:"document_id"`
+	ProductID       *int    `db:"product_id" json:"product_id"` // Nil once the product is deleted
+	VariantID       *int    `db:"variant_id" json:"variant_id,omitempty"`
+	ProductCode     string  `db:"product_code" json:"product_code"`
+	ProductTitle    string  `db:"product_title" json:"product_title"`
+	VariantColor    string  `db:"variant_color" json:"variant_color"`
+	Quantity        int     `db:"quantity" json:"quantity"`
+	UnitPrice       float64 `db:"unit_price" json:"unit_price"`
+	DiscountPercent float64 `db:"discount_percent" json:"discount_percent"`
+	LineTotal       float64 `db:"line_total" json:"line_total"` // After the line discount
+}
+
+// Label names the line ("Title - Colour")
+func (i *DocumentItem) Label() string {
+	if i.VariantColor != "" {
+		return i.ProductTitle + " - " + i.VariantColor
+	}
+	return i.ProductTitle
+}
+
+// GrossAmount returns the line amount before its discount
+func (i *DocumentItem) GrossAmount() float64 {
+	return i.UnitPrice * float64(i.Quantity)
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// InquiryStatus tracks how far a WhatsApp lead has been followed up
+type InquiryStatus string
+
+const (
+	InquiryNew       InquiryStatus = "new"       // Chat opened, not yet answered
+	InquiryContacted InquiryStatus = "contacted" // Customer has been answered
+	InquiryWon       InquiryStatus = "won"       // Inquiry turned into a sale
+	InquiryLost      InquiryStatus = "lost"      // Customer did not buy
+)
+
+// InquiryStatuses lists all inquiry statuses in workflow order
+var InquiryStatuses = []InquiryStatus{
+	InquiryNew,
+	InquiryContacted,
+	InquiryWon,
+	InquiryLost,
+}
+
+// Label returns a human-readable label for the inquiry status
+func (s InquiryStatus) Label() string {
+	switch s {
+	case InquiryNew:
+		return "Baru"
+	case InquiryContacted:
+		return "Dihubungi"
+	case InquiryWon:
+		return "Terjual"
+	case InquiryLost:
+		return "Batal"
+	}
+	return string(s)
+}
+
+// InquirySource tells which button opened the WhatsApp chat
+type InquirySource string
+
+const (
+	InquirySourceProduct InquirySource = "product" // "Chat via WhatsApp" on a product page
+	InquirySourceBasket  InquirySource = "basket"  // Basket checkout, one inquiry per line
+)
+
+// Inquiry is a WhatsApp lead for one product (and variant). The product fields
+// are a snapshot taken when the chat was opened.
+type Inquiry struct {
+	ID           int           `db:"id" json:"id"`
+	ProductID    *int          `db:"product_id" json:"product_id"` // Nil once the product is deleted
+	VariantID    *int          `db:"variant_id" json:"variant_id,omitempty"`
+	ProductCode  string        `db:"product_code" json:"product_code"`
+	ProductTitle string        `db:"product_title" json:"product_title"`
+	VariantColor string        `db:"variant_color" json:"variant_color"`
+	Quantity     int           `db:"quantity" json:"quantity"`
+	UnitPrice    float64       `db:"unit_price" json:"unit_price"`
+	Source       InquirySource `db:"source" json:"source"`
+	Referrer     string        `db:"referrer" json:"referrer"`
+	Status       InquiryStatus `db:"status" json:"status"`
+	Notes        string        `db:"notes" json:"notes"`
+	CreatedAt    time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// Label names the inquired item ("Title - Colour")
+func (i *Inquiry) Label() string {
+	if i.VariantColor != "" {
+		return i.ProductTitle + " - " + i.VariantColor
+	}
+	return i.ProductTitle
+}
+
+// Subtotal returns the quoted value of the inquiry
+func (i *Inquiry) Subtotal() float64 {
+	return i.UnitPrice * float64(i.Quantity)
+}
+
+// InquiryConversion is a read model of the inquiries received for one product
+// and how many of them were won
+type InquiryConversion struct {
+	ProductID    int    `db:"product_id" json:"product_id"`
+	ProductCode  string `db:"product_code" json:"product_code"`
+	ProductTitle string `db:"product_title" json:"product_title"`
+	Total        int    `db:"total" json:"total"`
+	Won          int    `db:"won" json:"won"`
+	Lost         int    `db:"lost" json:"lost"`
+}
+
+// Rate returns the share of inquiries that were won, as a percentage
+func (c *InquiryConversion) Rate() int {
+	if c.Total == 0 {
+		return 0
+	}
+	return c.Won * 100 / c.Total
+}
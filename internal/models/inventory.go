@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// MovementType identifies the kind of stock movement recorded in the ledger
+type MovementType string
+
+const (
+	MovementStockIn    MovementType = "stock_in"   // Goods received from a supplier
+	MovementSale       MovementType = "sale"       // Goods sold to a customer
+	MovementAdjustment MovementType = "adjustment" // Manual correction after a stock count (signed)
+	MovementReturn     MovementType = "return"     // Goods returned by a customer
+	MovementDamage     MovementType = "damage"     // Goods written off as damaged
+)
+
+// MovementTypes lists all movement types in display order
+var MovementTypes = []MovementType{
+	MovementStockIn,
+	MovementSale,
+	MovementAdjustment,
+	MovementReturn,
+	MovementDamage,
+}
+
+// Label returns a human-readable label for the movement type
+func (t MovementType) Label() string {
+	switch t {
+	case MovementStockIn:
+		return "Stok Masuk"
+	case MovementSale:
+		return "Penjualan"
+	case MovementAdjustment:
+		return "Penyesuaian"
+	case MovementReturn:
+		return "Retur"
+	case MovementDamage:
+		return "Rusak"
+	}
+	return string(t)
+}
+
+// InventoryMovement represents one entry in the stock ledger of a variant
+type InventoryMovement struct {
+	ID           int          `db:"id" json:"id"`
+	VariantID    int          `db:"variant_id" json:"variant_id"` // 0 once the variant is deleted
+	MovementType MovementType `db:"movement_type" json:"movement_type"`
+	Quantity     int          `db:"quantity" json:"quantity"` // Signed delta applied to stock_qty
+	BalanceAfter int          `db:"balance_after" json:"balance_after"`
+	Reason       string       `db:"reason" json:"reason"`
+	AdminID      *int         `db:"admin_id" json:"admin_id"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+
+	// Snapshot taken when the entry is recorded
+	ProductCode  string `db:"product_code" json:"product_code"`
+	ProductTitle string `db:"product_title" json:"product_title"`
+	VariantColor string `db:"variant_color" json:"variant_color"`
+
+	// Joined fields (read-only)
+	ProductID     int     `db:"product_id" json:"product_id"`
+	AdminUsername *string `db:"admin_username" json:"admin_username,omitempty"`
+}
+
+// VariantStock is a read model of one variant's stock level together with its product
+type VariantStock struct {
+	VariantID    int       `db:"variant_id" json:"variant_id"`
+	ProductID    int       `db:"product_id" json:"product_id"`
+	ProductCode  string    `db:"product_code" json:"product_code"`
+	ProductTitle string    `db:"product_title" json:"product_title"`
+	Color        string    `db:"color" json:"color"`
+	StockQty     int       `db:"stock_qty" json:"stock_qty"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// OrderStatus is a step in the order workflow
+type OrderStatus string
+
+const (
+	OrderPendingPayment OrderStatus = "pending_payment" // Confirmed in chat, waiting for the transfer
+	OrderPaid           OrderStatus = "paid"            // Payment received
+	OrderPacked         OrderStatus = "packed"          // Goods packed and ready to ship
+	OrderShipped        OrderStatus = "shipped"         // Handed to the courier
+	OrderCompleted      OrderStatus = "completed"       // Received by the customer
+	OrderCancelled      OrderStatus = "cancelled"       // Deal called off before shipping
+)
+
+// OrderStatuses lists all order statuses in workflow order
+var OrderStatuses = []OrderStatus{
+	OrderPendingPayment,
+	OrderPaid,
+	OrderPacked,
+	OrderShipped,
+	OrderCompleted,
+	OrderCancelled,
+}
+
+// orderTransitions lists the statuses each status may move to. Orders only move
+// forward and can be cancelled until they are shipped.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPendingPayment: {OrderPaid, OrderCancelled},
+	OrderPaid:           {OrderPacked, OrderCancelled},
+	OrderPacked:         {OrderShipped, OrderCancelled},
+	OrderShipped:        {OrderCompleted},
+}
+
+// Label returns a human-readable label for the order status
+func (s OrderStatus) Label() string {
+	switch s {
+	case OrderPendingPayment:
+		return "Menunggu Pembayaran"
+	case OrderPaid:
+		return "Dibayar"
+	case OrderPacked:
+		return "Dikemas"
+	case OrderShipped:
+		return "Dikirim"
+	case OrderCompleted:
+		return "Selesai"
+	case OrderCancelled:
+		return "Dibatalkan"
+	}
+	return string(s)
+}
+
+// NextStatuses returns the statuses the order may move to from s
+func (s OrderStatus) NextStatuses() []OrderStatus {
+	return orderTransitions[s]
+}
+
+// CanTransitionTo reports whether an order in status s may move to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Order is a deal confirmed by an admin, with line items priced at the time of the deal
+type Order struct {
+	ID              int         `db:"id" json:"id"`
+	CustomerName    string      `db:"customer_name" json:"customer_name"`
+	CustomerPhone   string      `db:"customer_phone" json:"customer_phone"`
+	ShippingAddress string      `db:"shipping_address" json:"shipping_address"`
+	Notes           string      `db:"notes" json:"notes"`
+	Status          OrderStatus `db:"status" json:"status"`
+	Subtotal        float64     `db:"subtotal" json:"subtotal"`
+	ShippingCost    float64     `db:"shipping_cost" json:"shipping_cost"`
+	Total           float64     `db:"total" json:"total"`
+	AdminID         *int        `db:"admin_id" json:"admin_id"`
+	CreatedAt       time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time   `db:"updated_at" json:"updated_at"`
+
+	// Aggregated in listings (read-only)
+	ItemCount int `db:"item_count" json:"item_count"`
+
+	// Relations (not in DB)
+	Items   []OrderItem         `db:"-" json:"items,omitempty"`
+	History []OrderStatusChange `db:"-" json:"history,omitempty"`
+}
+
+// Number returns the reference shown to admins and customers (e.g. ORD-000123)
+func (o *Order) Number() string {
+	return fmt.Sprintf("ORD-%06d", o.ID)
+}
+
+// OrderItem is one order line with a snapshot of the product, variant and unit price
+type OrderItem struct {
+	ID           int       `db:"id" json:"id"`
+	OrderID      int       `db:"order_id" json:"order_id"`
+	ProductID    *int      `db:"product_id" json:"product_id"` // Nil once the product is deleted
+	VariantID    *int      `db:"variant_id" json:"variant_id,omitempty"`
+	ProductCode  string    `db:"product_code" json:"product_code"`
+	ProductTitle string    `db:"product_title" json:"product_title"`
+	VariantColor string    `db:"variant_color" json:"variant_color"`
+	Quantity     int       `db:"quantity" json:"quantity"`
+	UnitPrice    float64   `db:"unit_price" json:"unit_price"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// Label names the line ("Title - Colour")
+func (i *OrderItem) Label() string {
+	if i.VariantColor != "" {
+		return i.ProductTitle + " - " + i.VariantColor
+	}
+	return i.ProductTitle
+}
+
+// Subtotal returns the line total
+func (i *OrderItem) Subtotal() float64 {
+	return i.UnitPrice * float64(i.Quantity)
+}
+
+// OrderStatusChange is one entry in an order's status history
+type OrderStatusChange struct {
+	ID         int          `db:"id" json:"id"`
+	OrderID    int          `db:"order_id" json:"order_id"`
+	FromStatus *OrderStatus `db:"from_status" json:"from_status"` // Nil when the order was created
+	ToStatus   OrderStatus  `db:"to_status" json:"to_status"`
+	Note       string       `db:"note" json:"note"`
+	AdminID    *int         `db:"admin_id" json:"admin_id"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+
+	// Joined fields (read-only)
+	AdminUsername *string `db:"admin_username" json:"admin_username,omitempty"`
+}
@@ -6,37 +6,254 @@ import "time"
 type Product struct {
 	ID           int       `db:"id" json:"id"`
 	Code         string    `db:"code" json:"code"`
+	Slug         string    `db:"slug" json:"slug"` // Unique, generated from Title; public URL is /p/:slug
 	Title        string    `db:"title" json:"title"`
 	Description  string    `db:"description" json:"description"`
 	MainPhotoURL string    `db:"main_photo_url" json:"main_photo_url"`
 	MainPhotoID  string    `db:"main_photo_id" json:"main_photo_id"`
 	CategoryID   *int      `db:"category_id" json:"category_id"`
 	BasePrice    float64   `db:"base_price" json:"base_price"`
-	IsSold       bool      `db:"is_sold" json:"is_sold"` // For availability filtering
+	MinPrice     float64   `db:"min_price" json:"min_price"` // Lowest regular variant price (see ProductVariant.RegularPrice)
+	MaxPrice     float64   `db:"max_price" json:"max_price"` // Highest regular variant price
+	IsSold       bool      `db:"is_sold" json:"is_sold"`     // Derived from variant stock (true = no variant has stock left)
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 
+	// Approved reviews (see ReviewRepository.RefreshProductRating)
+	RatingAverage float64 `db:"rating_average" json:"rating_average"`
+	RatingCount   int     `db:"rating_count" json:"rating_count"`
+
 	// Relations (not in DB)
-	Category *Category        `db:"-" json:"category,omitempty"`
-	Variants []ProductVariant `db:"-" json:"variants,omitempty"`
+	Category   *Category        `db:"-" json:"category,omitempty"`
+	Variants   []ProductVariant `db:"-" json:"variants,omitempty"`
+	Promotion  *Promotion       `db:"-" json:"promotion,omitempty"`   // Best live promotion on the product or its category
+	GroupPrice *float64         `db:"-" json:"group_price,omitempty"` // Signed-in reseller's price, set only when it beats the public price
+	Images     []ProductImage   `db:"-" json:"images,omitempty"`      // Gallery, sorted by SortOrder
+	Wishlisted bool             `db:"-" json:"-"`                     // On the current shopper's wishlist
+
+	// Other languages, loaded for the admin form only
+	Translations []ProductTranslation `db:"-" json:"translations,omitempty"`
+}
+
+// Translation returns the product's text in locale, blank when it has none
+func (p *Product) Translation(locale string) ProductTranslation {
+	for _, translation := range p.Translations {
+		if translation.Locale == locale {
+			return translation
+		}
+	}
+	return ProductTranslation{ProductID: p.ID, Locale: locale}
+}
+
+// ProductImage is an additional gallery photo of a product, optionally showing one variant
+type ProductImage struct {
+	ID        int       `db:"id" json:"id"`
+	ProductID int       `db:"product_id" json:"product_id"`
+	VariantID *int      `db:"variant_id" json:"variant_id,omitempty"`
+	ImageURL  string    `db:"image_url" json:"image_url"`
+	ImageID   string    `db:"image_id" json:"image_id"`
+	AltText   string    `db:"alt_text" json:"alt_text"`
+	SortOrder int       `db:"sort_order" json:"sort_order"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Joined from product_variants; on save it names the variant to link
+	VariantColor string `db:"variant_color" json:"variant_color,omitempty"`
+}
+
+// URL returns the public product page path
+func (p *Product) URL() string {
+	return "/p/" + p.Slug
+}
+
+// FinalPrice returns the group price of a signed-in reseller, or the base price after
+// the product's live promotion, if any
+func (p *Product) FinalPrice() float64 {
+	if p.GroupPrice != nil {
+		return *p.GroupPrice
+	}
+	if p.Promotion != nil {
+		return p.Promotion.Apply(p.BasePrice)
+	}
+	return p.BasePrice
+}
+
+// PriceRange returns the lowest and highest single-unit price across the variants after
+// live promotions, or the product's own price when it has no variants
+func (p *Product) PriceRange() (low, high float64) {
+	if len(p.Variants) == 0 {
+		price := p.FinalPrice()
+		return price, price
+	}
+	for i := range p.Variants {
+		price := p.Variants[i].FinalPrice(p.BasePrice)
+		if i == 0 || price < low {
+			low = price
+		}
+		if i == 0 || price > high {
+			high = price
+		}
+	}
+	return low, high
+}
+
+// MinFinalPrice returns the lowest price of PriceRange
+func (p *Product) MinFinalPrice() float64 {
+	low, _ := p.PriceRange()
+	return low
+}
+
+// MaxFinalPrice returns the highest price of PriceRange
+func (p *Product) MaxFinalPrice() float64 {
+	_, high := p.PriceRange()
+	return high
+}
+
+// OnSale reports whether the product or any of its variants has a live promotion
+func (p *Product) OnSale() bool {
+	if p.Promotion != nil {
+		return true
+	}
+	for i := range p.Variants {
+		if p.Variants[i].OnSale() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGroupPrice reports whether the product or any of its variants shows a reseller price
+func (p *Product) HasGroupPrice() bool {
+	if p.GroupPrice != nil {
+		return true
+	}
+	for i := range p.Variants {
+		if p.Variants[i].GroupPrice != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // ProductVariant represents a product color variant
 type ProductVariant struct {
 	ID              int       `db:"id" json:"id"`
 	ProductID       int       `db:"product_id" json:"product_id"`
-	Color           string    `db:"color" json:"color"`
+	Color           string    `db:"color" json:"color"`                 // Display label
+	ColorID         *int      `db:"color_id" json:"color_id,omitempty"` // Palette colour, matched from the label when not chosen
 	PhotoURL        string    `db:"photo_url" json:"photo_url"`
 	PhotoID         string    `db:"photo_id" json:"photo_id"`
 	PriceAdjustment float64   `db:"price_adjustment" json:"price_adjustment"`
-	IsSale          bool      `db:"is_sale" json:"is_sale"` // true = SALE, false = SOLD
+	StockQty        int       `db:"stock_qty" json:"stock_qty"` // Maintained by inventory movements only
 	CreatedAt       time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+
+	// Joined from colors
+	ColorHex    string `db:"color_hex" json:"color_hex,omitempty"`
+	ColorFamily string `db:"color_family" json:"color_family,omitempty"`
+
+	// Relations (not in DB)
+	PriceTiers []PriceTier `db:"-" json:"price_tiers,omitempty"` // Sorted by MinQty ascending
+	Promotion  *Promotion  `db:"-" json:"promotion,omitempty"`   // Best live promotion on the variant, its product or category
+	GroupPrice *float64    `db:"-" json:"group_price,omitempty"` // Signed-in reseller's price, set only when it beats the public price
 }
 
-// FinalPrice returns the variant's final price.
+// PriceTier is a wholesale price break: from MinQty units on, each unit costs UnitPrice
+type PriceTier struct {
+	ID        int       `db:"id" json:"-"`
+	VariantID int       `db:"variant_id" json:"-"`
+	MinQty    int       `db:"min_qty" json:"min_qty"`
+	UnitPrice float64   `db:"unit_price" json:"unit_price"`
+	CreatedAt time.Time `db:"created_at" json:"-"`
+	UpdatedAt time.Time `db:"updated_at" json:"-"`
+}
+
+// PriceBreak is one row of a variant's price table, including the regular price row
+type PriceBreak struct {
+	MinQty    int
+	MaxQty    int // 0 = no upper bound
+	UnitPrice float64
+}
+
+// FinalPrice returns the variant's single-unit price: the group price of a signed-in
+// reseller, or the regular price after its live promotion, if any.
 // Note: in this project `price_adjustment` is used as the stored final variant price.
 func (v *ProductVariant) FinalPrice(basePrice float64) float64 {
-	_ = basePrice
-	return v.PriceAdjustment
+	if v.GroupPrice != nil {
+		return *v.GroupPrice
+	}
+	price := v.RegularPrice(basePrice)
+	if v.Promotion != nil {
+		return v.Promotion.Apply(price)
+	}
+	return price
+}
+
+// OnSale reports whether a live promotion discounts the variant
+func (v *ProductVariant) OnSale() bool {
+	return v.Promotion != nil
+}
+
+// RegularPrice returns the single-unit price of the variant, falling back to the
+// product base price when no variant price is set
+func (v *ProductVariant) RegularPrice(basePrice float64) float64 {
+	if v.PriceAdjustment > 0 {
+		return v.PriceAdjustment
+	}
+	return basePrice
+}
+
+// InStock reports whether the variant has at least one unit available
+func (v *ProductVariant) InStock() bool {
+	return v.StockQty > 0
+}
+
+// TierFor returns the price tier that applies when buying qty units,
+// or nil when the regular variant price applies
+func (v *ProductVariant) TierFor(qty int) *PriceTier {
+	var tier *PriceTier
+	for i := range v.PriceTiers {
+		if v.PriceTiers[i].MinQty <= qty {
+			tier = &v.PriceTiers[i]
+		}
+	}
+	return tier
+}
+
+// UnitPrice returns the per-unit price when buying qty units: the applicable
+// wholesale tier or the promotional price, whichever is lower
+func (v *ProductVariant) UnitPrice(basePrice float64, qty int) float64 {
+	price := v.FinalPrice(basePrice)
+	if tier := v.TierFor(qty); tier != nil && tier.UnitPrice < price {
+		return tier.UnitPrice
+	}
+	return price
+}
+
+// PriceBreaks returns the full price table (regular or group price first, then each
+// tier) with quantity ranges, for display
+func (v *ProductVariant) PriceBreaks(basePrice float64) []PriceBreak {
+	if len(v.PriceTiers) == 0 {
+		return nil
+	}
+
+	first := v.RegularPrice(basePrice)
+	if v.GroupPrice != nil {
+		first = *v.GroupPrice
+	}
+
+	breaks := make([]PriceBreak, 0, len(v.PriceTiers)+1)
+	breaks = append(breaks, PriceBreak{
+		MinQty:    1,
+		MaxQty:    v.PriceTiers[0].MinQty - 1,
+		UnitPrice: first,
+	})
+	for i, tier := range v.PriceTiers {
+		b := PriceBreak{MinQty: tier.MinQty, UnitPrice: tier.UnitPrice}
+		if i+1 < len(v.PriceTiers) {
+			b.MaxQty = v.PriceTiers[i+1].MinQty - 1
+		}
+		breaks = append(breaks, b)
+	}
+	return breaks
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DiscountType is how a promotion reduces the price
+type DiscountType string
+
+const (
+	DiscountPercent DiscountType = "percent"
+	DiscountFixed   DiscountType = "fixed"
+)
+
+// Promotion status values, computed by the database clock
+const (
+	PromotionScheduled = "scheduled"
+	PromotionLive      = "live"
+	PromotionEnded     = "ended"
+	PromotionDisabled  = "disabled"
+)
+
+// Promotion is a time-boxed discount on products, variants or whole categories
+type Promotion struct {
+	ID            int          `db:"id" json:"id"`
+	Name          string       `db:"name" json:"name"`
+	DiscountType  DiscountType `db:"discount_type" json:"discount_type"`
+	DiscountValue float64      `db:"discount_value" json:"discount_value"`
+	StartsAt      time.Time    `db:"starts_at" json:"starts_at"`
+	EndsAt        time.Time    `db:"ends_at" json:"ends_at"`
+	IsActive      bool         `db:"is_active" json:"is_active"` // Manual kill switch
+	Status        string       `db:"status" json:"status"`       // Computed on read (scheduled, live, ended, disabled)
+	CreatedAt     time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time    `db:"updated_at" json:"updated_at"`
+
+	// Relations (not in DB)
+	Targets []PromotionTarget `db:"-" json:"targets,omitempty"`
+}
+
+// PromotionTarget links a promotion to exactly one product, variant or category
+type PromotionTarget struct {
+	ID          int  `db:"id" json:"id"`
+	PromotionID int  `db:"promotion_id" json:"promotion_id"`
+	ProductID   *int `db:"product_id" json:"product_id,omitempty"`
+	VariantID   *int `db:"variant_id" json:"variant_id,omitempty"`
+	CategoryID  *int `db:"category_id" json:"category_id,omitempty"`
+
+	// Joined for display
+	Label string `db:"label" json:"label"`
+
+	// A category target covers the category and everything below it
+	CategorySubtree pq.Int64Array `db:"category_subtree" json:"-"`
+}
+
+// IsLive reports whether the promotion currently discounts prices
+func (p *Promotion) IsLive() bool {
+	return p.Status == PromotionLive
+}
+
+// Apply returns price after the promotion's discount, never below zero
+func (p *Promotion) Apply(price float64) float64 {
+	discounted := price
+	switch p.DiscountType {
+	case DiscountPercent:
+		discounted = price * (1 - p.DiscountValue/100)
+	case DiscountFixed:
+		discounted = price - p.DiscountValue
+	}
+	return math.Max(math.Round(discounted), 0)
+}
+
+// BestPromotion returns whichever promotion gives the lowest price, or nil
+func BestPromotion(price float64, promotions []*Promotion) *Promotion {
+	var best *Promotion
+	for _, promo := range promotions {
+		if best == nil || promo.Apply(price) < best.Apply(price) {
+			best = promo
+		}
+	}
+	return best
+}
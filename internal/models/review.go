@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// MaxReviewPhotos caps the photos attached to one review
+const MaxReviewPhotos = 4
+
+// ReviewStatus is the moderation state of a product review
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"  // Waiting in the moderation queue
+	ReviewApproved ReviewStatus = "approved" // Shown on the product page and counted in the rating
+	ReviewRejected ReviewStatus = "rejected" // Hidden
+)
+
+// ReviewStatuses lists all review statuses in moderation order
+var ReviewStatuses = []ReviewStatus{
+	ReviewPending,
+	ReviewApproved,
+	ReviewRejected,
+}
+
+// Label returns a human-readable label for the review status
+func (s ReviewStatus) Label() string {
+	switch s {
+	case ReviewPending:
+		return "Menunggu Moderasi"
+	case ReviewApproved:
+		return "Ditampilkan"
+	case ReviewRejected:
+		return "Ditolak"
+	}
+	return string(s)
+}
+
+// IsValid reports whether s is a known review status
+func (s ReviewStatus) IsValid() bool {
+	for _, status := range ReviewStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Review is a customer's rating and testimonial of a product
+type Review struct {
+	ID         int          `db:"id" json:"id"`
+	ProductID  int          `db:"product_id" json:"product_id"`
+	CustomerID *int         `db:"customer_id" json:"customer_id,omitempty"`
+	AuthorName string       `db:"author_name" json:"author_name"`
+	City       string       `db:"city" json:"city"`
+	Rating     int          `db:"rating" json:"rating"` // 1 to 5 stars
+	Body       string       `db:"body" json:"body"`
+	Status     ReviewStatus `db:"status" json:"status"`
+	IPAddress  string       `db:"ip_address" json:"-"`
+	ApprovedAt *time.Time   `db:"approved_at" json:"approved_at,omitempty"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at" json:"updated_at"`
+
+	// Joined from products
+	ProductCode  string `db:"product_code" json:"product_code,omitempty"`
+	ProductTitle string `db:"product_title" json:"product_title,omitempty"`
+	ProductSlug  string `db:"product_slug" json:"product_slug,omitempty"`
+
+	// Relations (not in DB)
+	Photos []ReviewPhoto `db:"-" json:"photos,omitempty"`
+}
+
+// ProductURL returns the public page of the reviewed product
+func (r *Review) ProductURL() string {
+	return "/p/" + r.ProductSlug
+}
+
+// ReviewPhoto is a photo attached to a review
+type ReviewPhoto struct {
+	ID        int       `db:"id" json:"id"`
+	ReviewID  int       `db:"review_id" json:"review_id"`
+	ImageURL  string    `db:"image_url" json:"image_url"`
+	ImageID   string    `db:"image_id" json:"image_id"`
+	SortOrder int       `db:"sort_order" json:"sort_order"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// StoreLocation is the store's time zone (WIB, UTC+7, no daylight saving).
+// Promotion windows and document dates are wall-clock times in this zone.
+var StoreLocation = time.FixedZone("WIB", 7*60*60)
+
+// Setting keys in the settings table
+const (
+	SettingStoreName      = "store_name"
+	SettingStoreAddress   = "store_address"
+	SettingWhatsAppNumber = "whatsapp_number"
+	SettingShopeeLink     = "shopee_link"
+	SettingTiktokLink     = "tiktok_link"
+	SettingInstagramLink  = "instagram_link"
+)
+
+// StoreSettings are the store details shown on the storefront and in messages,
+// editable from the admin panel
+type StoreSettings struct {
+	Name           string // Shop name in WhatsApp messages, documents and feeds
+	Address        string // Top bar, contact section and documents
+	WhatsAppNumber string // International format without "+", e.g. 628123456789
+	ShopeeLink     string
+	TiktokLink     string
+	InstagramLink  string
+}
+
+// Values returns the settings by key, as stored
+func (s StoreSettings) Values() map[string]string {
+	return map[string]string{
+		SettingStoreName:      s.Name,
+		SettingStoreAddress:   s.Address,
+		SettingWhatsAppNumber: s.WhatsAppNumber,
+		SettingShopeeLink:     s.ShopeeLink,
+		SettingTiktokLink:     s.TiktokLink,
+		SettingInstagramLink:  s.InstagramLink,
+	}
+}
+
+// StoreSettingsFrom reads settings stored by key; missing keys stay blank
+func StoreSettingsFrom(values map[string]string) StoreSettings {
+	return StoreSettings{
+		Name:           values[SettingStoreName],
+		Address:        values[SettingStoreAddress],
+		WhatsAppNumber: values[SettingWhatsAppNumber],
+		ShopeeLink:     values[SettingShopeeLink],
+		TiktokLink:     values[SettingTiktokLink],
+		InstagramLink:  values[SettingInstagramLink],
+	}
+}
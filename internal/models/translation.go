@@ -0,0 +1,30 @@
+package models
+
+// ProductTranslation is a product's text in a language other than Indonesian.
+// Blank fields fall back to the Indonesian text on the product.
+type ProductTranslation struct {
+	ProductID   int    `db:"product_id" json:"product_id"`
+	Locale      string `db:"locale" json:"locale"`
+	Title       string `db:"title" json:"title"`
+	Description string `db:"description" json:"description"`
+}
+
+// IsBlank reports whether the translation has no text, so there is nothing to store
+func (t *ProductTranslation) IsBlank() bool {
+	return t.Title == "" && t.Description == ""
+}
+
+// CategoryTranslation is a category's name and page content in a language other
+// than Indonesian. Blank fields fall back to the Indonesian text on the category.
+type CategoryTranslation struct {
+	CategoryID  int    `db:"category_id" json:"category_id"`
+	Locale      string `db:"locale" json:"locale"`
+	Name        string `db:"name" json:"name"`
+	Title       string `db:"title" json:"title"`
+	Description string `db:"description" json:"description"`
+}
+
+// IsBlank reports whether the translation has no text, so there is nothing to store
+func (t *CategoryTranslation) IsBlank() bool {
+	return t.Name == "" && t.Title == "" && t.Description == ""
+}
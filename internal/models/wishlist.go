@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// MaxRecentlyViewed caps the recently viewed products kept per shopper
+const MaxRecentlyViewed = 12
+
+// Visitor is an anonymous shopper, identified by the token in their cookie
+type Visitor struct {
+	ID        int       `db:"id" json:"id"`
+	Token     string    `db:"token" json:"-"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Shopper owns a wishlist and a recently viewed list: a signed-in customer, or
+// else an anonymous visitor. Exactly one of the IDs is set; neither is set for a
+// visitor who has not saved anything yet.
+type Shopper struct {
+	VisitorID  int
+	CustomerID int
+}
+
+// IsZero reports whether the shopper has no lists yet
+func (s Shopper) IsZero() bool {
+	return s.VisitorID == 0 && s.CustomerID == 0
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// BasketRepository handles inquiry basket data access
+type BasketRepository struct {
+	db *sqlx.DB
+}
+
+// NewBasketRepository creates a new basket repository
+func NewBasketRepository(db *sqlx.DB) *BasketRepository {
+	return &BasketRepository{db: db}
+}
+
+// FindByToken retrieves a basket by its cookie token
+func (r *BasketRepository) FindByToken(token string) (*models.Basket, error) {
+	query := `
+		SELECT id, token, created_at, updated_at
+		FROM baskets
+		WHERE token = $1
+	`
+
+	var basket models.Basket
+	if err := r.db.Get(&basket, query, token); err != nil {
+		return nil, fmt.Errorf("failed to fetch basket: %w", err)
+	}
+
+	return &basket, nil
+}
+
+// Create inserts an empty basket
+func (r *BasketRepository) Create(basket *models.Basket) error {
+	query := `
+		INSERT INTO baskets (token)
+		VALUES ($1)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, basket.Token).Scan(&basket.ID, &basket.CreatedAt, &basket.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create basket: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStale removes baskets (with their items) not changed for longer than maxAge
+func (r *BasketRepository) DeleteStale(maxAge time.Duration) error {
+	query := `DELETE FROM baskets WHERE updated_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`
+	if _, err := r.db.Exec(query, maxAge.Seconds()); err != nil {
+		return fmt.Errorf("failed to delete stale baskets: %w", err)
+	}
+	return nil
+}
+
+// FindItems retrieves the items of a basket in the order they were added
+func (r *BasketRepository) FindItems(basketID int) ([]models.BasketItem, error) {
+	query := `
+		SELECT id, basket_id, product_id, variant_id, quantity, created_at, updated_at
+		FROM basket_items
+		WHERE basket_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	items := []models.BasketItem{}
+	if err := r.db.Select(&items, query, basketID); err != nil {
+		return nil, fmt.Errorf("failed to fetch basket items: %w", err)
+	}
+
+	return items, nil
+}
+
+// AddItem adds quantity units of a product (and variant) to a basket within a
+// transaction, raising the quantity of an existing line up to models.MaxBasketQuantity
+func (r *BasketRepository) AddItem(tx *sqlx.Tx, basketID, productID int, variantID *int, quantity int) error {
+	_, err := tx.Exec(`
+		INSERT INTO basket_items (basket_id, product_id, variant_id, quantity)
+		VALUES ($1, $2, $3, LEAST($4, $5))
+		ON CONFLICT (basket_id, product_id, COALESCE(variant_id, 0))
+		DO UPDATE SET
+			quantity = LEAST(basket_items.quantity + EXCLUDED.quantity, $5),
+			updated_at = CURRENT_TIMESTAMP
+	`, basketID, productID, variantID, quantity, models.MaxBasketQuantity)
+	if err != nil {
+		return fmt.Errorf("failed to add basket item: %w", err)
+	}
+	return nil
+}
+
+// UpdateItemQuantity sets the quantity of a basket line within a transaction
+func (r *BasketRepository) UpdateItemQuantity(tx *sqlx.Tx, basketID, itemID, quantity int) error {
+	result, err := tx.Exec(`
+		UPDATE basket_items SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND basket_id = $3
+	`, quantity, itemID, basketID)
+	if err != nil {
+		return fmt.Errorf("failed to update basket item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("basket item with id %d not found", itemID)
+	}
+
+	return nil
+}
+
+// DeleteItem removes a line from a basket within a transaction
+func (r *BasketRepository) DeleteItem(tx *sqlx.Tx, basketID, itemID int) error {
+	if _, err := tx.Exec(`DELETE FROM basket_items WHERE id = $1 AND basket_id = $2`, itemID, basketID); err != nil {
+		return fmt.Errorf("failed to delete basket item: %w", err)
+	}
+	return nil
+}
+
+// Touch marks a basket as changed within a transaction so it does not expire
+func (r *BasketRepository) Touch(tx *sqlx.Tx, basketID int) error {
+	if _, err := tx.Exec(`UPDATE baskets SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, basketID); err != nil {
+		return fmt.Errorf("failed to update basket: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every line from a basket
+func (r *BasketRepository) Clear(basketID int) error {
+	if _, err := r.db.Exec(`DELETE FROM basket_items WHERE basket_id = $1`, basketID); err != nil {
+		return fmt.Errorf("failed to clear basket: %w", err)
+	}
+	return nil
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/rizkysr90/aslam-flower/internal/models"
 )
 
+// categoryColumns lists the columns selected for a category
+const categoryColumns = `id, name, slug, parent_id, path, title, description, banner_url, banner_id, created_at, updated_at`
+
 // CategoryRepository handles category data access
 type CategoryRepository struct {
 	db *sqlx.DB
@@ -20,7 +23,7 @@ func NewCategoryRepository(db *sqlx.DB) *CategoryRepository {
 // FindAll retrieves all categories
 func (r *CategoryRepository) FindAll() ([]models.Category, error) {
 	query := `
-		SELECT id, name, slug, created_at, updated_at
+		SELECT ` + categoryColumns + `
 		FROM categories
 		ORDER BY name ASC
 	`
@@ -34,10 +37,29 @@ func (r *CategoryRepository) FindAll() ([]models.Category, error) {
 	return categories, nil
 }
 
+// FindByPrefix lists categories whose name, or a word in it, starts with text
+func (r *CategoryRepository) FindByPrefix(text string, limit int) ([]models.Category, error) {
+	start, word := prefixPatterns(text)
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE lower(name) LIKE $1 OR name ILIKE $2
+		ORDER BY (lower(name) LIKE $1) DESC, name ASC
+		LIMIT $3
+	`
+
+	var categories []models.Category
+	if err := r.db.Select(&categories, query, start, word, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch category suggestions: %w", err)
+	}
+
+	return categories, nil
+}
+
 // FindByID retrieves a category by ID
 func (r *CategoryRepository) FindByID(id int) (*models.Category, error) {
 	query := `
-		SELECT id, name, slug, created_at, updated_at
+		SELECT ` + categoryColumns + `
 		FROM categories
 		WHERE id = $1
 	`
@@ -54,7 +76,7 @@ func (r *CategoryRepository) FindByID(id int) (*models.Category, error) {
 // FindBySlug retrieves a category by slug
 func (r *CategoryRepository) FindBySlug(slug string) (*models.Category, error) {
 	query := `
-		SELECT id, name, slug, created_at, updated_at
+		SELECT ` + categoryColumns + `
 		FROM categories
 		WHERE slug = $1
 	`
@@ -68,19 +90,99 @@ func (r *CategoryRepository) FindBySlug(slug string) (*models.Category, error) {
 	return &category, nil
 }
 
-// Create inserts a new category
-func (r *CategoryRepository) Create(category *models.Category) error {
+// SlugTaken reports whether a category other than excludeID uses slug
+func (r *CategoryRepository) SlugTaken(slug string, excludeID int) (bool, error) {
+	var taken bool
+	err := r.db.Get(&taken, `SELECT EXISTS (SELECT 1 FROM categories WHERE slug = $1 AND id <> $2)`, slug, excludeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category slug: %w", err)
+	}
+
+	return taken, nil
+}
+
+// FindBySlugHistory retrieves the category that used to have the given slug
+func (r *CategoryRepository) FindBySlugHistory(slug string) (*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE id = (SELECT category_id FROM category_slug_history WHERE slug = $1)
+	`
+
+	var category models.Category
+	err := r.db.Get(&category, query, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category by old slug: %w", err)
+	}
+
+	return &category, nil
+}
+
+// RecordSlugChange remembers a category's previous slug within a transaction so old
+// links keep working. A slug that becomes current again is dropped from the history.
+func (r *CategoryRepository) RecordSlugChange(tx *sqlx.Tx, categoryID int, oldSlug, newSlug string) error {
+	if _, err := tx.Exec(`DELETE FROM category_slug_history WHERE slug = $1`, newSlug); err != nil {
+		return fmt.Errorf("failed to clear slug history: %w", err)
+	}
+
 	query := `
-		INSERT INTO categories (name, slug)
+		INSERT INTO category_slug_history (slug, category_id)
 		VALUES ($1, $2)
-		RETURNING id, created_at, updated_at
+		ON CONFLICT (slug) DO UPDATE
+		SET category_id = EXCLUDED.category_id, created_at = CURRENT_TIMESTAMP
+	`
+	if _, err := tx.Exec(query, oldSlug, categoryID); err != nil {
+		return fmt.Errorf("failed to record slug history: %w", err)
+	}
+
+	return nil
+}
+
+// FindAncestors retrieves the categories on the path from the root down to and
+// including the category with the given path
+func (r *CategoryRepository) FindAncestors(path string) ([]models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE path <> '' AND $1 LIKE path || '%'
+		ORDER BY length(path) ASC
+	`
+
+	var categories []models.Category
+	err := r.db.Select(&categories, query, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category ancestors: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Create inserts a new category. The ID is taken from the sequence up front so the
+// path (parent path + own ID) can be written in the same statement.
+func (r *CategoryRepository) Create(tx *sqlx.Tx, category *models.Category) error {
+	query := `
+		WITH new_category AS (
+			SELECT nextval(pg_get_serial_sequence('categories', 'id')) AS id
+		)
+		INSERT INTO categories (id, name, slug, parent_id, path, title, description, banner_url, banner_id)
+		SELECT
+			nc.id, $1, $2, $3,
+			COALESCE((SELECT path FROM categories WHERE id = $3), '/') || nc.id || '/',
+			$4, $5, $6, $7
+		FROM new_category nc
+		RETURNING id, path, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err := tx.QueryRow(
 		query,
 		category.Name,
 		category.Slug,
-	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+		category.ParentID,
+		category.Title,
+		category.Description,
+		category.BannerURL,
+		category.BannerID,
+	).Scan(&category.ID, &category.Path, &category.CreatedAt, &category.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create category: %w", err)
@@ -89,29 +191,90 @@ func (r *CategoryRepository) Create(category *models.Category) error {
 	return nil
 }
 
-// Update updates an existing category
-func (r *CategoryRepository) Update(category *models.Category) error {
+// LockForMove blocks other category writes until the transaction ends and reports
+// whether parentID is the category itself or sits in its subtree. Holding the lock
+// keeps two concurrent moves from each passing the check and forming a cycle.
+func (r *CategoryRepository) LockForMove(tx *sqlx.Tx, id, parentID int) (bool, error) {
+	if _, err := tx.Exec(`LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return false, fmt.Errorf("failed to lock categories: %w", err)
+	}
+
+	var inSubtree bool
+	err := tx.Get(&inSubtree, `
+		SELECT EXISTS (
+			SELECT 1 FROM categories moved
+			JOIN categories parent ON parent.path LIKE moved.path || '%'
+			WHERE moved.id = $1 AND parent.id = $2
+		)
+	`, id, parentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category move: %w", err)
+	}
+
+	return inSubtree, nil
+}
+
+// Update updates an existing category within a transaction. Moving it to another
+// parent rewrites the paths of the category and its whole subtree.
+func (r *CategoryRepository) Update(tx *sqlx.Tx, category *models.Category) error {
+	var oldName, oldPath string
+	err := tx.QueryRow(`SELECT name, path FROM categories WHERE id = $1`, category.ID).Scan(&oldName, &oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
 	query := `
 		UPDATE categories
 		SET 
 			name = $1,
 			slug = $2,
+			parent_id = $3,
+			path = COALESCE((SELECT path FROM categories WHERE id = $3), '/') || id || '/',
+			title = $4,
+			description = $5,
+			banner_url = $6,
+			banner_id = $7,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-		RETURNING updated_at
+		WHERE id = $8
+		RETURNING path, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		category.Name,
 		category.Slug,
+		category.ParentID,
+		category.Title,
+		category.Description,
+		category.BannerURL,
+		category.BannerID,
 		category.ID,
-	).Scan(&category.UpdatedAt)
-
+	).Scan(&category.Path, &category.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to update category: %w", err)
 	}
 
+	// The category name is part of its products' search document
+	if category.Name != oldName {
+		_, err = tx.Exec(`UPDATE products p SET search_vector = `+productSearchVector+` WHERE p.category_id = $1`, category.ID)
+		if err != nil {
+			return fmt.Errorf("failed to refresh search index: %w", err)
+		}
+	}
+
+	if category.Path == oldPath {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE categories
+		SET path = $1::text || substring(path FROM length($2::text) + 1)
+		WHERE path LIKE $2::text || '%' AND id <> $3
+	`, category.Path, oldPath, category.ID)
+	if err != nil {
+		return fmt.Errorf("failed to move subcategories: %w", err)
+	}
+
 	return nil
 }
 
@@ -133,9 +296,26 @@ func (r *CategoryRepository) Delete(id int) error {
 		return fmt.Errorf("category with id %d not found", id)
 	}
 
+	// Its products are now uncategorized; drop the old name from their search document
+	_, err = r.db.Exec(`UPDATE products p SET search_vector = ` + productSearchVector + ` WHERE p.category_id IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to refresh search index: %w", err)
+	}
+
 	return nil
 }
 
+// CountChildren counts the direct subcategories of a category
+func (r *CategoryRepository) CountChildren(categoryID int) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM categories WHERE parent_id = $1`, categoryID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count subcategories: %w", err)
+	}
+
+	return count, nil
+}
+
 // CountProducts counts how many products belong to a category
 func (r *CategoryRepository) CountProducts(categoryID int) (int, error) {
 	query := `
@@ -152,3 +332,60 @@ func (r *CategoryRepository) CountProducts(categoryID int) (int, error) {
 
 	return count, nil
 }
+
+// FindTranslations retrieves every category's text in locale, keyed by category ID
+func (r *CategoryRepository) FindTranslations(locale string) (map[int]models.CategoryTranslation, error) {
+	var rows []models.CategoryTranslation
+	err := r.db.Select(&rows, `
+		SELECT category_id, locale, name, title, description
+		FROM category_translations
+		WHERE locale = $1
+	`, locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category translations: %w", err)
+	}
+
+	translations := make(map[int]models.CategoryTranslation, len(rows))
+	for _, row := range rows {
+		translations[row.CategoryID] = row
+	}
+	return translations, nil
+}
+
+// FindTranslationsByCategory retrieves a category's text in every translated locale
+func (r *CategoryRepository) FindTranslationsByCategory(categoryID int) ([]models.CategoryTranslation, error) {
+	translations := []models.CategoryTranslation{}
+	err := r.db.Select(&translations, `
+		SELECT category_id, locale, name, title, description
+		FROM category_translations
+		WHERE category_id = $1
+		ORDER BY locale
+	`, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category translations: %w", err)
+	}
+	return translations, nil
+}
+
+// SyncTranslations replaces a category's translations within a transaction.
+// Blank translations are not stored.
+func (r *CategoryRepository) SyncTranslations(tx *sqlx.Tx, categoryID int, translations []models.CategoryTranslation) error {
+	if _, err := tx.Exec(`DELETE FROM category_translations WHERE category_id = $1`, categoryID); err != nil {
+		return fmt.Errorf("failed to delete category translations: %w", err)
+	}
+
+	for _, translation := range translations {
+		if translation.IsBlank() {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO category_translations (category_id, locale, name, title, description)
+			VALUES ($1, $2, $3, $4, $5)
+		`, categoryID, translation.Locale, translation.Name, translation.Title, translation.Description)
+		if err != nil {
+			return fmt.Errorf("failed to save category translation: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// colorColumns lists the columns selected for a palette colour (aliased c)
+const colorColumns = `
+	c.id, c.name, c.aliases, c.hex, c.family, c.created_at, c.updated_at,
+	(SELECT COUNT(*) FROM product_variants pv WHERE pv.color_id = c.id) AS variant_count
+`
+
+// refreshColorSearchVectors recomputes the search document of every product with a variant
+// in one of the given colours, whose names and aliases are part of it
+const refreshColorSearchVectors = `
+	UPDATE products p SET search_vector = ` + productSearchVector + `
+	WHERE p.id IN (SELECT pv.product_id FROM product_variants pv WHERE pv.color_id = ANY($1))
+`
+
+// linkColorVariants points variants without a palette colour at colour $1 when their
+// label matches its name or one of its aliases, like models.Color.Matches
+const linkColorVariants = `
+	UPDATE product_variants pv
+	SET color_id = c.id, updated_at = CURRENT_TIMESTAMP
+	FROM colors c
+	WHERE c.id = $1
+		AND pv.color_id IS NULL
+		AND (lower(trim(pv.color)) = lower(c.name) OR lower(trim(pv.color)) = ANY(c.aliases))
+`
+
+// ColorRepository handles palette colour data access
+type ColorRepository struct {
+	db *sqlx.DB
+}
+
+// NewColorRepository creates a new color repository
+func NewColorRepository(db *sqlx.DB) *ColorRepository {
+	return &ColorRepository{db: db}
+}
+
+// FindAll retrieves the palette ordered by family, then name
+func (r *ColorRepository) FindAll() ([]models.Color, error) {
+	query := `
+		SELECT ` + colorColumns + `
+		FROM colors c
+		ORDER BY c.family ASC, c.name ASC
+	`
+
+	var colors []models.Color
+	if err := r.db.Select(&colors, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch colors: %w", err)
+	}
+
+	return colors, nil
+}
+
+// FindByID retrieves a palette colour by ID
+func (r *ColorRepository) FindByID(id int) (*models.Color, error) {
+	query := `
+		SELECT ` + colorColumns + `
+		FROM colors c
+		WHERE c.id = $1
+	`
+
+	var color models.Color
+	if err := r.db.Get(&color, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch color: %w", err)
+	}
+
+	return &color, nil
+}
+
+// Create inserts a palette colour within a transaction, links the unlinked variants
+// whose label matches it and refreshes the search documents of their products
+func (r *ColorRepository) Create(tx *sqlx.Tx, color *models.Color) error {
+	query := `
+		INSERT INTO colors (name, aliases, hex, family)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRow(query, color.Name, color.Aliases, color.Hex, color.Family).
+		Scan(&color.ID, &color.CreatedAt, &color.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create color: %w", err)
+	}
+
+	return r.linkVariants(tx, color.ID)
+}
+
+// Update updates a palette colour within a transaction, links the unlinked variants
+// whose label now matches it and refreshes the search documents of the products using it
+func (r *ColorRepository) Update(tx *sqlx.Tx, color *models.Color) error {
+	query := `
+		UPDATE colors
+		SET
+			name = $1,
+			aliases = $2,
+			hex = $3,
+			family = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err := tx.QueryRow(query, color.Name, color.Aliases, color.Hex, color.Family, color.ID).
+		Scan(&color.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update color: %w", err)
+	}
+
+	return r.linkVariants(tx, color.ID)
+}
+
+// linkVariants links the matching unlinked variants to a colour within a transaction
+// and refreshes the search documents of every product using it
+func (r *ColorRepository) linkVariants(tx *sqlx.Tx, colorID int) error {
+	if _, err := tx.Exec(linkColorVariants, colorID); err != nil {
+		return fmt.Errorf("failed to link variants to color: %w", err)
+	}
+
+	if _, err := tx.Exec(refreshColorSearchVectors, pq.Array([]int{colorID})); err != nil {
+		return fmt.Errorf("failed to refresh search index: %w", err)
+	}
+
+	return nil
+}
+
+// Merge moves every variant of the source colour to the target within a transaction,
+// adds the source name and aliases to the target's aliases and deletes the source
+func (r *ColorRepository) Merge(tx *sqlx.Tx, sourceID, targetID int) error {
+	_, err := tx.Exec(`
+		UPDATE colors t
+		SET
+			aliases = ARRAY(
+				SELECT DISTINCT a FROM unnest(t.aliases || lower(s.name) || s.aliases) AS a
+				WHERE a <> lower(t.name)
+				ORDER BY a
+			),
+			updated_at = CURRENT_TIMESTAMP
+		FROM colors s
+		WHERE t.id = $1 AND s.id = $2
+	`, targetID, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to merge color aliases: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE product_variants SET color_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE color_id = $2
+	`, targetID, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to move variants: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM colors WHERE id = $1`, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete merged color: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("color with id %d not found", sourceID)
+	}
+
+	// The target's new aliases may match unlinked variants too
+	return r.linkVariants(tx, targetID)
+}
+
+// Delete removes a palette colour within a transaction; its variants keep their labels
+// but lose the link, so the search documents of their products are refreshed
+func (r *ColorRepository) Delete(tx *sqlx.Tx, id int) error {
+	var productIDs []int
+	err := tx.Select(&productIDs, `SELECT DISTINCT product_id FROM product_variants WHERE color_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to fetch products of color: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM colors WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete color: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("color with id %d not found", id)
+	}
+
+	_, err = tx.Exec(`UPDATE products p SET search_vector = `+productSearchVector+` WHERE p.id = ANY($1)`, pq.Array(productIDs))
+	if err != nil {
+		return fmt.Errorf("failed to refresh search index: %w", err)
+	}
+
+	return nil
+}
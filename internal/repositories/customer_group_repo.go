@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// customerGroupColumns lists the columns selected for a customer group (aliased g)
+const customerGroupColumns = `
+	g.id, g.name, g.description, g.discount_percent, g.created_at, g.updated_at,
+	(SELECT COUNT(*) FROM customers c WHERE c.group_id = g.id) AS customer_count
+`
+
+// CustomerGroupRepository handles customer group and group price data access
+type CustomerGroupRepository struct {
+	db *sqlx.DB
+}
+
+// NewCustomerGroupRepository creates a new customer group repository
+func NewCustomerGroupRepository(db *sqlx.DB) *CustomerGroupRepository {
+	return &CustomerGroupRepository{db: db}
+}
+
+// FindAll retrieves every customer group ordered by name
+func (r *CustomerGroupRepository) FindAll() ([]models.CustomerGroup, error) {
+	query := `
+		SELECT ` + customerGroupColumns + `
+		FROM customer_groups g
+		ORDER BY g.name ASC
+	`
+
+	groups := []models.CustomerGroup{}
+	if err := r.db.Select(&groups, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch customer groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// FindByID retrieves a customer group by ID without its explicit prices
+func (r *CustomerGroupRepository) FindByID(id int) (*models.CustomerGroup, error) {
+	query := `
+		SELECT ` + customerGroupColumns + `
+		FROM customer_groups g
+		WHERE g.id = $1
+	`
+
+	var group models.CustomerGroup
+	if err := r.db.Get(&group, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch customer group: %w", err)
+	}
+
+	return &group, nil
+}
+
+// FindPriceList retrieves a group's explicit prices with the product and variant
+// they belong to, ordered by product code
+func (r *CustomerGroupRepository) FindPriceList(groupID int) ([]models.CustomerGroupPrice, error) {
+	query := `
+		SELECT
+			gp.group_id, gp.variant_id, gp.unit_price,
+			p.id AS product_id, p.code AS product_code, p.title AS product_title,
+			pv.color AS variant_color,
+			CASE WHEN pv.price_adjustment > 0 THEN pv.price_adjustment ELSE p.base_price END AS regular_price
+		FROM customer_group_prices gp
+		JOIN product_variants pv ON pv.id = gp.variant_id
+		JOIN products p ON p.id = pv.product_id
+		WHERE gp.group_id = $1
+		ORDER BY p.code ASC, pv.color ASC
+	`
+
+	prices := []models.CustomerGroupPrice{}
+	if err := r.db.Select(&prices, query, groupID); err != nil {
+		return nil, fmt.Errorf("failed to fetch customer group prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+// FindPrices returns the group's explicit unit prices for the given variants, keyed by variant ID
+func (r *CustomerGroupRepository) FindPrices(groupID int, variantIDs []int) (map[int]float64, error) {
+	prices := make(map[int]float64)
+	if len(variantIDs) == 0 {
+		return prices, nil
+	}
+
+	var rows []models.CustomerGroupPrice
+	err := r.db.Select(&rows, `
+		SELECT variant_id, unit_price
+		FROM customer_group_prices
+		WHERE group_id = $1 AND variant_id = ANY($2)
+	`, groupID, pq.Array(variantIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch customer group prices: %w", err)
+	}
+
+	for _, row := range rows {
+		prices[row.VariantID] = row.UnitPrice
+	}
+
+	return prices, nil
+}
+
+// Create inserts a customer group within a transaction
+func (r *CustomerGroupRepository) Create(tx *sqlx.Tx, group *models.CustomerGroup) error {
+	query := `
+		INSERT INTO customer_groups (name, description, discount_percent)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRow(query, group.Name, group.Description, group.DiscountPercent).
+		Scan(&group.ID, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create customer group: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a customer group within a transaction
+func (r *CustomerGroupRepository) Update(tx *sqlx.Tx, group *models.CustomerGroup) error {
+	query := `
+		UPDATE customer_groups
+		SET
+			name = $1,
+			description = $2,
+			discount_percent = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING updated_at
+	`
+
+	err := tx.QueryRow(query, group.Name, group.Description, group.DiscountPercent, group.ID).
+		Scan(&group.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update customer group: %w", err)
+	}
+
+	return nil
+}
+
+// ReplacePrices replaces all explicit prices of a group within a transaction
+func (r *CustomerGroupRepository) ReplacePrices(tx *sqlx.Tx, groupID int, prices []models.CustomerGroupPrice) error {
+	if _, err := tx.Exec(`DELETE FROM customer_group_prices WHERE group_id = $1`, groupID); err != nil {
+		return fmt.Errorf("failed to clear customer group prices: %w", err)
+	}
+
+	for _, price := range prices {
+		_, err := tx.Exec(`
+			INSERT INTO customer_group_prices (group_id, variant_id, unit_price)
+			VALUES ($1, $2, $3)
+		`, groupID, price.VariantID, price.UnitPrice)
+		if err != nil {
+			return fmt.Errorf("failed to create customer group price: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReplacePriceRanges replaces the stored current price ranges of a group within a
+// transaction. Only products the group sees at other prices than the public need a
+// range; price filters fall back to the public one (see currentPrice).
+func (r *CustomerGroupRepository) ReplacePriceRanges(tx *sqlx.Tx, groupID int, ranges []PriceRange) error {
+	if _, err := tx.Exec(`DELETE FROM customer_group_price_ranges WHERE group_id = $1`, groupID); err != nil {
+		return fmt.Errorf("failed to clear customer group price ranges: %w", err)
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(ranges))
+	mins := make([]float64, len(ranges))
+	maxes := make([]float64, len(ranges))
+	for i, priceRange := range ranges {
+		ids[i] = int64(priceRange.ProductID)
+		mins[i] = priceRange.Min
+		maxes[i] = priceRange.Max
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO customer_group_price_ranges (group_id, product_id, min_price, max_price)
+		SELECT $1, r.id, r.min_price, r.max_price
+		FROM unnest($2::int[], $3::numeric[], $4::numeric[]) AS r(id, min_price, max_price)
+	`, groupID, pq.Array(ids), pq.Array(mins), pq.Array(maxes))
+	if err != nil {
+		return fmt.Errorf("failed to create customer group price ranges: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a customer group and its prices; its customers keep their accounts
+// without a group
+func (r *CustomerGroupRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM customer_groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete customer group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("customer group not found")
+	}
+
+	return nil
+}
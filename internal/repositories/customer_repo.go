@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// CustomerRepository handles reseller account data access
+type CustomerRepository struct {
+	db *sqlx.DB
+}
+
+// NewCustomerRepository creates a new customer repository
+func NewCustomerRepository(db *sqlx.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// CustomerFilters contains filtering options for the customer list
+type CustomerFilters struct {
+	Status      string
+	GroupID     int
+	SearchQuery string // Name, business name, phone or city
+	Page        int
+	PageSize    int
+}
+
+// CustomerListResult contains paginated customers
+type CustomerListResult struct {
+	Customers  []models.Customer
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+// customerColumns lists the columns selected for a customer (aliased c, group g)
+const customerColumns = `
+	c.id, c.name, c.business_name, c.phone, c.city, c.password_hash, c.status, c.group_id,
+	c.admin_notes, c.approved_at, c.created_at, c.updated_at,
+	g.name AS group_name
+`
+
+// Create inserts a customer application
+func (r *CustomerRepository) Create(customer *models.Customer) error {
+	query := `
+		INSERT INTO customers (name, business_name, phone, city, password_hash, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, customer.Name, customer.BusinessName, customer.Phone, customer.City,
+		customer.PasswordHash, customer.Status).
+		Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a customer by ID
+func (r *CustomerRepository) FindByID(id int) (*models.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers c
+		LEFT JOIN customer_groups g ON g.id = c.group_id
+		WHERE c.id = $1
+	`
+
+	var customer models.Customer
+	if err := r.db.Get(&customer, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch customer: %w", err)
+	}
+
+	return &customer, nil
+}
+
+// FindByPhone retrieves a customer by the phone number they sign in with
+func (r *CustomerRepository) FindByPhone(phone string) (*models.Customer, error) {
+	query := `
+		SELECT ` + customerColumns + `
+		FROM customers c
+		LEFT JOIN customer_groups g ON g.id = c.group_id
+		WHERE c.phone = $1
+	`
+
+	var customer models.Customer
+	if err := r.db.Get(&customer, query, phone); err != nil {
+		return nil, fmt.Errorf("failed to fetch customer: %w", err)
+	}
+
+	return &customer, nil
+}
+
+// FindAll retrieves customers (newest first) with filtering and pagination
+func (r *CustomerRepository) FindAll(filters CustomerFilters) (*CustomerListResult, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.Status != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("c.status = $%d", argIndex))
+		args = append(args, filters.Status)
+		argIndex++
+	}
+	if filters.GroupID > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf("c.group_id = $%d", argIndex))
+		args = append(args, filters.GroupID)
+		argIndex++
+	}
+	if filters.SearchQuery != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf(
+			"(c.name ILIKE $%d OR c.business_name ILIKE $%d OR c.phone ILIKE $%d OR c.city ILIKE $%d)",
+			argIndex, argIndex, argIndex, argIndex))
+		args = append(args, "%"+filters.SearchQuery+"%")
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+	offset := (filters.Page - 1) * filters.PageSize
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM customers c %s", whereClause)
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count customers: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM customers c
+		LEFT JOIN customer_groups g ON g.id = c.group_id
+		%s
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $%d OFFSET $%d
+	`, customerColumns, whereClause, argIndex, argIndex+1)
+	args = append(args, filters.PageSize, offset)
+
+	customers := []models.Customer{}
+	if err := r.db.Select(&customers, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch customers: %w", err)
+	}
+
+	return &CustomerListResult{
+		Customers:  customers,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+	}, nil
+}
+
+// CountByStatus returns the number of customers in each status
+func (r *CustomerRepository) CountByStatus() (map[models.CustomerStatus]int, error) {
+	var rows []struct {
+		Status models.CustomerStatus `db:"status"`
+		Count  int                   `db:"count"`
+	}
+	if err := r.db.Select(&rows, `SELECT status, COUNT(*) AS count FROM customers GROUP BY status`); err != nil {
+		return nil, fmt.Errorf("failed to count customers by status: %w", err)
+	}
+
+	counts := make(map[models.CustomerStatus]int, len(models.CustomerStatuses))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// UpdateReview saves the admin's decision on an account: status, group, notes and
+// the approval time
+func (r *CustomerRepository) UpdateReview(customer *models.Customer) error {
+	query := `
+		UPDATE customers
+		SET
+			status = $1,
+			group_id = $2,
+			admin_notes = $3,
+			approved_at = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, customer.Status, customer.GroupID, customer.AdminNotes, customer.ApprovedAt, customer.ID).
+		Scan(&customer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	return nil
+}
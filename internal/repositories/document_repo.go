@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// DocumentRepository handles quotation and invoice data access. Documents are
+// immutable, so there are no update or delete methods.
+type DocumentRepository struct {
+	db *sqlx.DB
+}
+
+// NewDocumentRepository creates a new document repository
+func NewDocumentRepository(db *sqlx.DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+// DocumentFilters contains filtering options for the document list
+type DocumentFilters struct {
+	Type        string
+	SearchQuery string // Document number or customer name / phone
+	Page        int
+	PageSize    int
+}
+
+// DocumentListResult contains paginated documents
+type DocumentListResult struct {
+	Documents  []models.Document
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+const documentColumns = `
+	d.id, d.doc_type, d.number, d.year, d.sequence, d.customer_name, d.customer_phone,
+	d.customer_address, d.store_name, d.store_address, d.notes, d.subtotal,
+	d.discount_amount, d.total, d.due_date, d.admin_id, d.issued_at
+`
+
+// NextSequence reserves the next number of a document type within a transaction.
+// Numbers restart each year in the store's zone (WIB), the zone documents show their
+// issue date in (see Document.IssuedLocal). The sequence row stays locked until the
+// transaction ends, so concurrent issues wait for each other and a rollback releases
+// the number.
+func (r *DocumentRepository) NextSequence(tx *sqlx.Tx, docType models.DocumentType) (year, sequence int, err error) {
+	query := `
+		INSERT INTO document_sequences (doc_type, year, last_number)
+		VALUES ($1, EXTRACT(YEAR FROM CURRENT_TIMESTAMP AT TIME ZONE 'Asia/Jakarta')::int, 1)
+		ON CONFLICT (doc_type, year)
+		DO UPDATE SET last_number = document_sequences.last_number + 1
+		RETURNING year, last_number
+	`
+
+	if err := tx.QueryRow(query, docType).Scan(&year, &sequence); err != nil {
+		return 0, 0, fmt.Errorf("failed to reserve document number: %w", err)
+	}
+
+	return year, sequence, nil
+}
+
+// Create inserts a document with its items within a transaction
+func (r *DocumentRepository) Create(tx *sqlx.Tx, doc *models.Document) error {
+	query := `
+		INSERT INTO documents (
+			doc_type, number, year, sequence, customer_name, customer_phone,
+			customer_address, store_name, store_address, notes, subtotal,
+			discount_amount, total, due_date, admin_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, issued_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		doc.Type,
+		doc.Number,
+		doc.Year,
+		doc.Sequence,
+		doc.CustomerName,
+		doc.CustomerPhone,
+		doc.CustomerAddress,
+		doc.StoreName,
+		doc.StoreAddress,
+		doc.Notes,
+		doc.Subtotal,
+		doc.DiscountAmount,
+		doc.Total,
+		doc.DueDate,
+		doc.AdminID,
+	).Scan(&doc.ID, &doc.IssuedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create document: %w", err)
+	}
+
+	for i := range doc.Items {
+		item := &doc.Items[i]
+		item.DocumentID = doc.ID
+		err := tx.QueryRow(`
+			INSERT INTO document_items (
+				document_id, product_id, variant_id, product_code, product_title,
+				variant_color, quantity, unit_price, discount_percent, line_total
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+		`,
+			item.DocumentID,
+			item.ProductID,
+			item.VariantID,
+			item.ProductCode,
+			item.ProductTitle,
+			item.VariantColor,
+			item.Quantity,
+			item.UnitPrice,
+			item.DiscountPercent,
+			item.LineTotal,
+		).Scan(&item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create document item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves a document with its items
+func (r *DocumentRepository) FindByID(id int) (*models.Document, error) {
+	query := fmt.Sprintf(`SELECT %s FROM documents d WHERE d.id = $1`, documentColumns)
+
+	var doc models.Document
+	if err := r.db.Get(&doc, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+
+	doc.Items = []models.DocumentItem{}
+	err := r.db.Select(&doc.Items, `
+		SELECT id, document_id, product_id, variant_id, product_code, product_title,
+			variant_color, quantity, unit_price, discount_percent, line_total
+		FROM document_items
+		WHERE document_id = $1
+		ORDER BY id ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document items: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// FindAll retrieves documents (newest first) with filtering and pagination
+func (r *DocumentRepository) FindAll(filters DocumentFilters) (*DocumentListResult, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.Type != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("d.doc_type = $%d", argIndex))
+		args = append(args, filters.Type)
+		argIndex++
+	}
+	if filters.SearchQuery != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf(
+			"(d.number ILIKE $%d OR d.customer_name ILIKE $%d OR d.customer_phone ILIKE $%d)",
+			argIndex, argIndex, argIndex))
+		args = append(args, "%"+filters.SearchQuery+"%")
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+	offset := (filters.Page - 1) * filters.PageSize
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM documents d %s", whereClause)
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM documents d
+		%s
+		ORDER BY d.issued_at DESC, d.id DESC
+		LIMIT $%d OFFSET $%d
+	`, documentColumns, whereClause, argIndex, argIndex+1)
+	args = append(args, filters.PageSize, offset)
+
+	documents := []models.Document{}
+	if err := r.db.Select(&documents, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch documents: %w", err)
+	}
+
+	return &DocumentListResult{
+		Documents:  documents,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+	}, nil
+}
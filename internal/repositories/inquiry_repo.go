@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// InquiryRepository handles WhatsApp lead data access
+type InquiryRepository struct {
+	db *sqlx.DB
+}
+
+// NewInquiryRepository creates a new inquiry repository
+func NewInquiryRepository(db *sqlx.DB) *InquiryRepository {
+	return &InquiryRepository{db: db}
+}
+
+// InquiryFilters contains filtering options for the inquiry inbox
+type InquiryFilters struct {
+	Status      string
+	ProductID   *int
+	SearchQuery string // Product code or title
+	Page        int
+	PageSize    int
+}
+
+// InquiryListResult contains paginated inquiries
+type InquiryListResult struct {
+	Inquiries  []models.Inquiry
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+const inquiryColumns = `
+	id, product_id, variant_id, product_code, product_title, variant_color,
+	quantity, unit_price, source, referrer, status, notes, created_at, updated_at
+`
+
+// Create inserts an inquiry within a transaction
+func (r *InquiryRepository) Create(tx *sqlx.Tx, inquiry *models.Inquiry) error {
+	query := `
+		INSERT INTO inquiries (
+			product_id, variant_id, product_code, product_title, variant_color,
+			quantity, unit_price, source, referrer
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		inquiry.ProductID,
+		inquiry.VariantID,
+		inquiry.ProductCode,
+		inquiry.ProductTitle,
+		inquiry.VariantColor,
+		inquiry.Quantity,
+		inquiry.UnitPrice,
+		inquiry.Source,
+		inquiry.Referrer,
+	).Scan(&inquiry.ID, &inquiry.Status, &inquiry.CreatedAt, &inquiry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create inquiry: %w", err)
+	}
+
+	return nil
+}
+
+// FindAll retrieves inquiries (newest first) with filtering and pagination
+func (r *InquiryRepository) FindAll(filters InquiryFilters) (*InquiryListResult, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.Status != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("status = $%d", argIndex))
+		args = append(args, filters.Status)
+		argIndex++
+	}
+	if filters.ProductID != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("product_id = $%d", argIndex))
+		args = append(args, *filters.ProductID)
+		argIndex++
+	}
+	if filters.SearchQuery != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("(product_code ILIKE $%d OR product_title ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+filters.SearchQuery+"%")
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+	offset := (filters.Page - 1) * filters.PageSize
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM inquiries %s", whereClause)
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count inquiries: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM inquiries
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, inquiryColumns, whereClause, argIndex, argIndex+1)
+	args = append(args, filters.PageSize, offset)
+
+	inquiries := []models.Inquiry{}
+	if err := r.db.Select(&inquiries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch inquiries: %w", err)
+	}
+
+	return &InquiryListResult{
+		Inquiries:  inquiries,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+	}, nil
+}
+
+// CountByStatus returns the number of inquiries in each status
+func (r *InquiryRepository) CountByStatus() (map[models.InquiryStatus]int, error) {
+	var rows []struct {
+		Status models.InquiryStatus `db:"status"`
+		Count  int                  `db:"count"`
+	}
+	if err := r.db.Select(&rows, `SELECT status, COUNT(*) AS count FROM inquiries GROUP BY status`); err != nil {
+		return nil, fmt.Errorf("failed to count inquiries by status: %w", err)
+	}
+
+	counts := make(map[models.InquiryStatus]int, len(models.InquiryStatuses))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// UpdateStatus sets the follow-up status and notes of an inquiry
+func (r *InquiryRepository) UpdateStatus(id int, status models.InquiryStatus, notes string) error {
+	result, err := r.db.Exec(`
+		UPDATE inquiries SET status = $1, notes = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, notes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update inquiry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("inquiry with id %d not found", id)
+	}
+
+	return nil
+}
+
+// FindConversions returns per-product inquiry counts over the last days,
+// most inquired products first. Inquiries for deleted products are left out.
+func (r *InquiryRepository) FindConversions(days, limit int) ([]models.InquiryConversion, error) {
+	query := `
+		SELECT
+			p.id AS product_id, p.code AS product_code, p.title AS product_title,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE i.status = 'won') AS won,
+			COUNT(*) FILTER (WHERE i.status = 'lost') AS lost
+		FROM inquiries i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.created_at >= CURRENT_TIMESTAMP - make_interval(days => $1)
+		GROUP BY p.id, p.code, p.title
+		ORDER BY total DESC, won DESC, p.code ASC
+		LIMIT $2
+	`
+
+	conversions := []models.InquiryConversion{}
+	if err := r.db.Select(&conversions, query, days, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch inquiry conversions: %w", err)
+	}
+
+	return conversions, nil
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// InventoryRepository handles stock levels and the inventory movement ledger
+type InventoryRepository struct {
+	db *sqlx.DB
+}
+
+// NewInventoryRepository creates a new inventory repository
+func NewInventoryRepository(db *sqlx.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+
+// MovementFilters contains filtering options for the movement ledger
+type MovementFilters struct {
+	VariantID    *int
+	MovementType string
+	Page         int
+	PageSize     int
+}
+
+// MovementListResult contains paginated ledger entries
+type MovementListResult struct {
+	Movements  []models.InventoryMovement
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+// FindStockLevels lists every variant with its current stock, optionally filtered by product code or title
+func (r *InventoryRepository) FindStockLevels(search string) ([]models.VariantStock, error) {
+	query := `
+		SELECT
+			pv.id AS variant_id, p.id AS product_id,
+			p.code AS product_code, p.title AS product_title,
+			pv.color, pv.stock_qty, pv.updated_at
+		FROM product_variants pv
+		JOIN products p ON p.id = pv.product_id
+	`
+	args := []interface{}{}
+	if search != "" {
+		query += ` WHERE p.title ILIKE $1 OR p.code ILIKE $1`
+		args = append(args, "%"+search+"%")
+	}
+	query += ` ORDER BY p.code ASC, pv.color ASC`
+
+	var levels []models.VariantStock
+	if err := r.db.Select(&levels, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch stock levels: %w", err)
+	}
+
+	return levels, nil
+}
+
+// FindStockByVariantID retrieves one variant's stock level
+func (r *InventoryRepository) FindStockByVariantID(variantID int) (*models.VariantStock, error) {
+	query := `
+		SELECT
+			pv.id AS variant_id, p.id AS product_id,
+			p.code AS product_code, p.title AS product_title,
+			pv.color, pv.stock_qty, pv.updated_at
+		FROM product_variants pv
+		JOIN products p ON p.id = pv.product_id
+		WHERE pv.id = $1
+	`
+
+	var level models.VariantStock
+	if err := r.db.Get(&level, query, variantID); err != nil {
+		return nil, fmt.Errorf("failed to fetch variant stock: %w", err)
+	}
+
+	return &level, nil
+}
+
+// LockVariantStock reads a variant's stock with a row lock so concurrent movements serialize.
+// Returns: (productID, stockQty, error)
+func (r *InventoryRepository) LockVariantStock(tx *sqlx.Tx, variantID int) (int, int, error) {
+	query := `SELECT product_id, stock_qty FROM product_variants WHERE id = $1 FOR UPDATE`
+
+	var productID, stockQty int
+	if err := tx.QueryRow(query, variantID).Scan(&productID, &stockQty); err != nil {
+		return 0, 0, fmt.Errorf("failed to lock variant stock: %w", err)
+	}
+
+	return productID, stockQty, nil
+}
+
+// SetVariantStock stores a variant's new stock level within a transaction
+func (r *InventoryRepository) SetVariantStock(tx *sqlx.Tx, variantID, stockQty int) error {
+	query := `
+		UPDATE product_variants
+		SET stock_qty = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+	if _, err := tx.Exec(query, stockQty, variantID); err != nil {
+		return fmt.Errorf("failed to update variant stock: %w", err)
+	}
+	return nil
+}
+
+// CreateMovement appends an entry to the ledger within a transaction, snapshotting
+// the product and colour so the entry still reads right after the variant is deleted
+func (r *InventoryRepository) CreateMovement(tx *sqlx.Tx, movement *models.InventoryMovement) error {
+	query := `
+		INSERT INTO inventory_movements (
+			variant_id, movement_type, quantity, balance_after, reason, admin_id,
+			product_code, product_title, variant_color
+		)
+		SELECT $1, $2, $3, $4, $5, $6, p.code, p.title, pv.color
+		FROM product_variants pv
+		JOIN products p ON p.id = pv.product_id
+		WHERE pv.id = $1
+		RETURNING id, created_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		movement.VariantID,
+		movement.MovementType,
+		movement.Quantity,
+		movement.BalanceAfter,
+		movement.Reason,
+		movement.AdminID,
+	).Scan(&movement.ID, &movement.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create inventory movement: %w", err)
+	}
+
+	return nil
+}
+
+// FindMovements retrieves ledger entries (newest first) with filtering and pagination
+func (r *InventoryRepository) FindMovements(filters MovementFilters) (*MovementListResult, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.VariantID != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.variant_id = $%d", argIndex))
+		args = append(args, *filters.VariantID)
+		argIndex++
+	}
+	if filters.MovementType != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.movement_type = $%d", argIndex))
+		args = append(args, filters.MovementType)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+	offset := (filters.Page - 1) * filters.PageSize
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM inventory_movements m %s", whereClause)
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count inventory movements: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			m.id, COALESCE(m.variant_id, 0) AS variant_id, m.movement_type, m.quantity, m.balance_after,
+			m.reason, m.admin_id, m.created_at,
+			COALESCE(pv.product_id, 0) AS product_id, m.product_code, m.product_title,
+			m.variant_color, a.username AS admin_username
+		FROM inventory_movements m
+		LEFT JOIN product_variants pv ON pv.id = m.variant_id
+		LEFT JOIN admins a ON a.id = m.admin_id
+		%s
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argIndex, argIndex+1)
+	args = append(args, filters.PageSize, offset)
+
+	var movements []models.InventoryMovement
+	if err := r.db.Select(&movements, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory movements: %w", err)
+	}
+
+	return &MovementListResult{
+		Movements:  movements,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+	}, nil
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// OrderRepository handles order, order item and status history data access
+type OrderRepository struct {
+	db *sqlx.DB
+}
+
+// NewOrderRepository creates a new order repository
+func NewOrderRepository(db *sqlx.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// OrderFilters contains filtering options for the order list
+type OrderFilters struct {
+	Status      string
+	SearchQuery string // Customer name or phone, or an order number
+	Page        int
+	PageSize    int
+}
+
+// OrderListResult contains paginated orders
+type OrderListResult struct {
+	Orders     []models.Order
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+const orderColumns = `
+	o.id, o.customer_name, o.customer_phone, o.shipping_address, o.notes, o.status,
+	o.subtotal, o.shipping_cost, o.total, o.admin_id, o.created_at, o.updated_at,
+	(SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi WHERE oi.order_id = o.id) AS item_count
+`
+
+// Create inserts an order with its items within a transaction
+func (r *OrderRepository) Create(tx *sqlx.Tx, order *models.Order) error {
+	query := `
+		INSERT INTO orders (
+			customer_name, customer_phone, shipping_address, notes, status,
+			subtotal, shipping_cost, total, admin_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		order.CustomerName,
+		order.CustomerPhone,
+		order.ShippingAddress,
+		order.Notes,
+		order.Status,
+		order.Subtotal,
+		order.ShippingCost,
+		order.Total,
+		order.AdminID,
+	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+		err := tx.QueryRow(`
+			INSERT INTO order_items (
+				order_id, product_id, variant_id, product_code, product_title,
+				variant_color, quantity, unit_price
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at
+		`,
+			item.OrderID,
+			item.ProductID,
+			item.VariantID,
+			item.ProductCode,
+			item.ProductTitle,
+			item.VariantColor,
+			item.Quantity,
+			item.UnitPrice,
+		).Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves an order with its items and status history
+func (r *OrderRepository) FindByID(id int) (*models.Order, error) {
+	query := fmt.Sprintf(`SELECT %s FROM orders o WHERE o.id = $1`, orderColumns)
+
+	var order models.Order
+	if err := r.db.Get(&order, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch order: %w", err)
+	}
+
+	order.Items = []models.OrderItem{}
+	err := r.db.Select(&order.Items, `
+		SELECT id, order_id, product_id, variant_id, product_code, product_title,
+			variant_color, quantity, unit_price, created_at
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY id ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order items: %w", err)
+	}
+
+	order.History = []models.OrderStatusChange{}
+	err = r.db.Select(&order.History, `
+		SELECT h.id, h.order_id, h.from_status, h.to_status, h.note, h.admin_id, h.created_at,
+			a.username AS admin_username
+		FROM order_status_history h
+		LEFT JOIN admins a ON a.id = h.admin_id
+		WHERE h.order_id = $1
+		ORDER BY h.created_at ASC, h.id ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order history: %w", err)
+	}
+
+	return &order, nil
+}
+
+// FindAll retrieves orders (newest first) with filtering and pagination
+func (r *OrderRepository) FindAll(filters OrderFilters) (*OrderListResult, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.Status != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("o.status = $%d", argIndex))
+		args = append(args, filters.Status)
+		argIndex++
+	}
+	if filters.SearchQuery != "" {
+		condition := fmt.Sprintf("o.customer_name ILIKE $%d OR o.customer_phone ILIKE $%d", argIndex, argIndex)
+		args = append(args, "%"+filters.SearchQuery+"%")
+		argIndex++
+
+		// "ORD-000123" and "123" both find order 123
+		number := strings.TrimPrefix(strings.ToUpper(filters.SearchQuery), "ORD-")
+		if id, err := strconv.Atoi(number); err == nil {
+			condition += fmt.Sprintf(" OR o.id = $%d", argIndex)
+			args = append(args, id)
+			argIndex++
+		}
+		whereConditions = append(whereConditions, "("+condition+")")
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 50
+	}
+	offset := (filters.Page - 1) * filters.PageSize
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM orders o %s", whereClause)
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM orders o
+		%s
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $%d OFFSET $%d
+	`, orderColumns, whereClause, argIndex, argIndex+1)
+	args = append(args, filters.PageSize, offset)
+
+	orders := []models.Order{}
+	if err := r.db.Select(&orders, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	return &OrderListResult{
+		Orders:     orders,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+	}, nil
+}
+
+// CountByStatus returns the number of orders in each status
+func (r *OrderRepository) CountByStatus() (map[models.OrderStatus]int, error) {
+	var rows []struct {
+		Status models.OrderStatus `db:"status"`
+		Count  int                `db:"count"`
+	}
+	if err := r.db.Select(&rows, `SELECT status, COUNT(*) AS count FROM orders GROUP BY status`); err != nil {
+		return nil, fmt.Errorf("failed to count orders by status: %w", err)
+	}
+
+	counts := make(map[models.OrderStatus]int, len(models.OrderStatuses))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// LockStatus reads an order's status within a transaction, locking the order
+// until the transaction ends
+func (r *OrderRepository) LockStatus(tx *sqlx.Tx, id int) (models.OrderStatus, error) {
+	var status models.OrderStatus
+	if err := tx.Get(&status, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return "", fmt.Errorf("failed to lock order: %w", err)
+	}
+	return status, nil
+}
+
+// UpdateStatus sets an order's status within a transaction
+func (r *OrderRepository) UpdateStatus(tx *sqlx.Tx, id int, status models.OrderStatus) error {
+	_, err := tx.Exec(`UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	return nil
+}
+
+// AddStatusChange appends an entry to an order's status history within a transaction
+func (r *OrderRepository) AddStatusChange(tx *sqlx.Tx, change *models.OrderStatusChange) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, note, admin_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := tx.QueryRow(query, change.OrderID, change.FromStatus, change.ToStatus, change.Note, change.AdminID).
+		Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record order status change: %w", err)
+	}
+
+	return nil
+}
@@ -1,14 +1,76 @@
 package repositories
 
 import (
+	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/rizkysr90/aslam-flower/internal/models"
 )
 
+// productSearchVector computes the weighted search document of product p: title and
+// code (A), category name and variant colours with their palette names and aliases (B),
+// description (C). Codes use the simple configuration so "KB-001" is not stemmed.
+const productSearchVector = `
+	setweight(to_tsvector('indonesian', coalesce(p.title, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(p.code, '')), 'A') ||
+	setweight(to_tsvector('indonesian', coalesce((SELECT c.name FROM categories c WHERE c.id = p.category_id), '')), 'B') ||
+	setweight(to_tsvector('indonesian', coalesce((
+		SELECT string_agg(concat_ws(' ', pv.color, pc.name, array_to_string(pc.aliases, ' ')), ' ')
+		FROM product_variants pv
+		LEFT JOIN colors pc ON pc.id = pv.color_id
+		WHERE pv.product_id = p.id
+	), '')), 'B') ||
+	setweight(to_tsvector('indonesian', coalesce(p.description, '')), 'C')
+`
+
+// searchQuery builds the tsquery for the search text in parameter $n, matching both
+// stemmed words and unstemmed codes
+func searchQuery(n int) string {
+	return fmt.Sprintf("(websearch_to_tsquery('indonesian', $%d) || websearch_to_tsquery('simple', $%d))", n, n)
+}
+
+// searchCondition matches products for the search text in parameter $n. When the text
+// is exactly a product code (case-insensitive) only that product matches.
+func searchCondition(n int) string {
+	return fmt.Sprintf(`(
+		lower(p.code) = lower($%d)
+		OR (
+			NOT EXISTS (SELECT 1 FROM products ec WHERE lower(ec.code) = lower($%d))
+			AND p.search_vector @@ %s
+		)
+	)`, n, n, searchQuery(n))
+}
+
+// similarCondition matches products whose title, code or category name is close to the
+// search text in parameter $n by trigram similarity, catching typos full-text search misses
+func similarCondition(n int) string {
+	return fmt.Sprintf(`(
+		p.title %% $%d OR $%d <%% p.title OR p.code %% $%d
+		OR EXISTS (SELECT 1 FROM categories sc WHERE sc.id = p.category_id AND sc.name %% $%d)
+	)`, n, n, n, n)
+}
+
+// similarityRank orders trigram matches for the search text in parameter $n, closest first
+func similarityRank(n int) string {
+	return fmt.Sprintf("greatest(similarity(p.title, $%d), word_similarity($%d, p.title), similarity(p.code, $%d))", n, n, n)
+}
+
+// likeEscaper escapes LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// prefixPatterns returns LIKE patterns matching text at the start of a value and at
+// the start of any later word in it. The start pattern is meant for lower(column) LIKE,
+// served by the text_pattern_ops indexes; the unanchored word pattern for column ILIKE,
+// served by the trigram indexes.
+func prefixPatterns(text string) (start, word string) {
+	escaped := likeEscaper.Replace(strings.ToLower(text))
+	return escaped + "%", "% " + escaped + "%"
+}
+
 // ProductRepository handles product data access
 type ProductRepository struct {
 	db *sqlx.DB
@@ -24,12 +86,17 @@ type ProductFilters struct {
 	CategoryID  *int
 	MinPrice    *float64
 	MaxPrice    *float64
-	IsSale      *bool // Filter by variant is_sale flag (true = SALE, false = SOLD)
-	IsSold      *bool // Filter by product is_sold flag (for availability filtering)
+	PriceGroup  *int   // Customer group whose prices the price filters and sorting use; nil for public prices
+	IsSale      *bool  // Filter by live promotion on the product, its category or one of its variants
+	IsSold      *bool  // Filter by product is_sold flag (for availability filtering)
+	Color       string // Filter by variant colour label (case-insensitive)
+	ColorFamily string // Filter by the family of a variant's palette colour (see models.ColorFamilies)
 	SearchQuery string
-	SortBy      string // "newest", "price_asc", "price_desc", "name_asc"
+	Fuzzy       bool   // Match SearchQuery by trigram similarity instead of full-text search
+	SortBy      string // "relevance", "newest", "price_asc", "price_desc", "name_asc"
 	Page        int
 	PageSize    int
+	WithFacets  bool // Also count facets for the filter sidebar (see FindFacets)
 }
 
 // ProductListResult contains paginated product results
@@ -39,78 +106,183 @@ type ProductListResult struct {
 	Page       int
 	PageSize   int
 	TotalPages int
+	Suggestion string         // "Did you mean" text when a search found little, set by the service
+	Facets     *ProductFacets // Set by the service when filters.WithFacets is true
 }
 
-// FindAll retrieves products with filtering, sorting, and pagination
-func (r *ProductRepository) FindAll(filters ProductFilters) (*ProductListResult, error) {
-	// Build WHERE clause with parameterized queries
-	whereConditions := []string{}
-	args := []interface{}{}
+// ProductFacets counts the products each filter option would return. Every facet is
+// counted with the other active filters applied but not its own, so options stay comparable.
+type ProductFacets struct {
+	Categories map[int]int // Category ID (including its subcategories) -> products
+	Available  int
+	SoldOut    int
+	OnSale     int
+	NotOnSale  int
+	Colors     []ColorFacet // By colour family, in models.ColorFamilies order
+}
+
+// ColorFacet is the number of products with a variant in one colour family
+type ColorFacet struct {
+	Family models.ColorFamily
+	Count  int
+}
+
+// Facets a filter condition can belong to; a facet's own condition is left out when counting its options
+const (
+	facetCategory     = "category"
+	facetAvailability = "availability"
+	facetSale         = "sale"
+	facetColor        = "color"
+)
+
+// filterCondition is one WHERE condition of a product listing
+type filterCondition struct {
+	facet string // Facet the condition filters on, "" when no facet count ignores it
+	sql   string
+}
+
+// buildFilterConditions turns filters into parameterized WHERE conditions on products p.
+// searchArg is the parameter index of the search text, or 0 without a search;
+// priceGroupArg that of filters.PriceGroup, or 0 when no price filter needs it.
+func buildFilterConditions(filters ProductFilters) (conditions []filterCondition, args []interface{}, searchArg, priceGroupArg int) {
 	argIndex := 1
 
-	// Category filter
+	// Category filter - the category and everything below it in the tree
 	if filters.CategoryID != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("p.category_id = $%d", argIndex))
+		conditions = append(conditions, filterCondition{facetCategory, fmt.Sprintf(`p.category_id IN (
+			SELECT sub.id FROM categories sub
+			JOIN categories root ON sub.path LIKE root.path || '%%'
+			WHERE root.id = $%d
+		)`, argIndex)})
 		args = append(args, *filters.CategoryID)
 		argIndex++
 	}
 
-	// Price range filters
+	// Price range filters - match when any variant's current price falls in the range (see UpdateCurrentPrices)
+	if filters.PriceGroup != nil && (filters.MinPrice != nil || filters.MaxPrice != nil) {
+		priceGroupArg = argIndex
+		args = append(args, *filters.PriceGroup)
+		argIndex++
+	}
 	if filters.MinPrice != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("p.base_price >= $%d", argIndex))
+		conditions = append(conditions, filterCondition{"", fmt.Sprintf("%s >= $%d", currentPrice(priceGroupArg, "max_price"), argIndex)})
 		args = append(args, *filters.MinPrice)
 		argIndex++
 	}
 	if filters.MaxPrice != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("p.base_price <= $%d", argIndex))
+		conditions = append(conditions, filterCondition{"", fmt.Sprintf("%s <= $%d", currentPrice(priceGroupArg, "min_price"), argIndex)})
 		args = append(args, *filters.MaxPrice)
 		argIndex++
 	}
 
-	// Sale filter - check if product has at least one variant with matching is_sale flag
+	// Sale filter - product has a promotion live right now (see livePromotionCondition)
 	if filters.IsSale != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf(`
-			EXISTS (
-				SELECT 1 FROM product_variants pv 
-				WHERE pv.product_id = p.id AND pv.is_sale = $%d
-			)
-		`, argIndex))
-		args = append(args, *filters.IsSale)
-		argIndex++
+		condition := fmt.Sprintf("EXISTS (%s)", productPromotionSubquery)
+		if !*filters.IsSale {
+			condition = "NOT " + condition
+		}
+		conditions = append(conditions, filterCondition{facetSale, condition})
 	}
 
-	// Sold filter - filter by product is_sold flag (for availability)
+	// Sold filter - is_sold is derived from variant stock (see RefreshAvailability)
 	if filters.IsSold != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("p.is_sold = $%d", argIndex))
+		conditions = append(conditions, filterCondition{facetAvailability, fmt.Sprintf("p.is_sold = $%d", argIndex)})
 		args = append(args, *filters.IsSold)
 		argIndex++
 	}
 
-	// Search filter (by title or code)
-	if filters.SearchQuery != "" {
-		searchPattern := "%" + filters.SearchQuery + "%"
-		whereConditions = append(whereConditions, fmt.Sprintf("(p.title ILIKE $%d OR p.code ILIKE $%d)", argIndex, argIndex))
-		args = append(args, searchPattern)
+	// Colour filter - product has a variant in that colour
+	if filters.Color != "" {
+		conditions = append(conditions, filterCondition{facetColor, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM product_variants fv WHERE fv.product_id = p.id AND lower(fv.color) = lower($%d))", argIndex)})
+		args = append(args, filters.Color)
+		argIndex++
+	}
+	if filters.ColorFamily != "" {
+		conditions = append(conditions, filterCondition{facetColor, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM product_variants fv
+			JOIN colors fcol ON fcol.id = fv.color_id
+			WHERE fv.product_id = p.id AND fcol.family = $%d
+		)`, argIndex)})
+		args = append(args, filters.ColorFamily)
 		argIndex++
 	}
 
+	// Search filter - full-text over the weighted search document, or an exact code
+	if filters.SearchQuery != "" {
+		condition := searchCondition(argIndex)
+		if filters.Fuzzy {
+			condition = similarCondition(argIndex)
+		}
+		conditions = append(conditions, filterCondition{"", condition})
+		args = append(args, filters.SearchQuery)
+		searchArg = argIndex
+	}
+
+	return conditions, args, searchArg, priceGroupArg
+}
+
+// currentPrice returns the stored lowest ("min_price") or highest ("max_price") current
+// price of product p: the range of the customer group in parameter groupArg where the
+// group is priced differently, otherwise the public range. groupArg 0 means public.
+func currentPrice(groupArg int, bound string) string {
+	if groupArg == 0 {
+		return "p.current_" + bound
+	}
+	return fmt.Sprintf(`COALESCE((
+		SELECT gpr.%[2]s FROM customer_group_price_ranges gpr
+		WHERE gpr.group_id = $%[1]d AND gpr.product_id = p.id
+	), p.current_%[2]s)`, groupArg, bound)
+}
+
+// joinConditions builds a WHERE clause from the conditions that do not belong to the excluded facet
+func joinConditions(conditions []filterCondition, excludeFacet string) string {
+	var parts []string
+	for _, condition := range conditions {
+		if excludeFacet == "" || condition.facet != excludeFacet {
+			parts = append(parts, condition.sql)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(parts, " AND ")
+}
+
+// FindAll retrieves products with filtering, sorting, and pagination
+func (r *ProductRepository) FindAll(filters ProductFilters) (*ProductListResult, error) {
+	conditions, args, searchArg, priceGroupArg := buildFilterConditions(filters)
+
 	// Build WHERE clause
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	whereClause := joinConditions(conditions, "")
+
+	// Price sorting follows the group prices too; without a price filter the
+	// customer group still needs a parameter
+	sortByPrice := filters.SortBy == "price_asc" || filters.SortBy == "price_desc"
+	if sortByPrice && filters.PriceGroup != nil && priceGroupArg == 0 {
+		args = append(args, *filters.PriceGroup)
+		priceGroupArg = len(args)
 	}
+	argIndex := len(args) + 1
 
 	// Build ORDER BY clause
 	orderBy := "p.created_at DESC" // default: newest first
 	switch filters.SortBy {
 	case "price_asc":
-		orderBy = "p.base_price ASC"
+		orderBy = currentPrice(priceGroupArg, "min_price") + " ASC, " + currentPrice(priceGroupArg, "max_price") + " ASC"
 	case "price_desc":
-		orderBy = "p.base_price DESC"
+		orderBy = currentPrice(priceGroupArg, "max_price") + " DESC, " + currentPrice(priceGroupArg, "min_price") + " DESC"
 	case "name_asc":
 		orderBy = "p.title ASC"
 	case "newest":
 		orderBy = "p.created_at DESC"
+	case "relevance":
+		// Without search text there is nothing to rank, so newest first
+		if searchArg > 0 && filters.Fuzzy {
+			orderBy = similarityRank(searchArg) + " DESC, p.created_at DESC"
+		} else if searchArg > 0 {
+			orderBy = fmt.Sprintf("ts_rank(p.search_vector, %s) DESC, p.created_at DESC", searchQuery(searchArg))
+		}
 	}
 
 	// Set defaults for pagination
@@ -122,50 +294,54 @@ func (r *ProductRepository) FindAll(filters ProductFilters) (*ProductListResult,
 	}
 	offset := (filters.Page - 1) * filters.PageSize
 
-	// Count total products matching filters
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products p %s", whereClause)
-	var total int
-	err := r.db.Get(&total, countQuery, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count products: %w", err)
-	}
-
-	// Calculate total pages
-	totalPages := (total + filters.PageSize - 1) / filters.PageSize
-
-	// Build main query with pagination
+	// The total comes with the page (COUNT(*) OVER () is evaluated before LIMIT),
+	// so listing needs no separate count query
 	query := fmt.Sprintf(`
 		SELECT 
-			p.id, p.code, p.title, p.description, 
+			p.id, p.code, p.slug, p.title, p.description, 
 			p.main_photo_url, p.main_photo_id, 
-			p.category_id, p.base_price, p.is_sold,
-			p.created_at, p.updated_at
+			p.category_id, p.base_price, p.min_price, p.max_price, p.is_sold,
+			p.rating_average, p.rating_count,
+			p.created_at, p.updated_at,
+			COUNT(*) OVER () AS total_count
 		FROM products p
 		%s
 		ORDER BY %s
 		LIMIT $%d OFFSET $%d
 	`, whereClause, orderBy, argIndex, argIndex+1)
 
-	args = append(args, filters.PageSize, offset)
-
-	var products []models.Product
-	err = r.db.Select(&products, query, args...)
+	var rows []struct {
+		models.Product
+		TotalCount int `db:"total_count"`
+	}
+	err := r.db.Select(&rows, query, append(args, filters.PageSize, offset)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch products: %w", err)
 	}
 
-	// Load variants for each product
-	for i := range products {
-		variants, err := r.findVariantsByProductID(products[i].ID)
-		if err == nil {
-			if variants == nil {
-				products[i].Variants = []models.ProductVariant{}
-			} else {
-				products[i].Variants = variants
-			}
+	products := make([]models.Product, len(rows))
+	total := 0
+	for i, row := range rows {
+		products[i] = row.Product
+		total = row.TotalCount
+	}
+
+	// A page past the end has no rows to carry the total; count separately so
+	// the pagination can still point back to the last page
+	if len(rows) == 0 && offset > 0 {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products p %s", whereClause)
+		if err := r.db.Get(&total, countQuery, args...); err != nil {
+			return nil, fmt.Errorf("failed to count products: %w", err)
 		}
 	}
 
+	// Calculate total pages
+	totalPages := (total + filters.PageSize - 1) / filters.PageSize
+
+	if err := r.attachVariants(products); err != nil {
+		return nil, err
+	}
+
 	return &ProductListResult{
 		Products:   products,
 		Total:      total,
@@ -175,13 +351,103 @@ func (r *ProductRepository) FindAll(filters ProductFilters) (*ProductListResult,
 	}, nil
 }
 
+// FindFacets counts, in one query, the products each category, availability state,
+// sale state and variant colour would return under the current filters
+func (r *ProductRepository) FindFacets(filters ProductFilters) (*ProductFacets, error) {
+	conditions, args, _, _ := buildFilterConditions(filters)
+
+	query := fmt.Sprintf(`
+		SELECT facet, value, count FROM (
+			SELECT 'category' AS facet, fc.id::text AS value, COUNT(DISTINCT p.id) AS count
+			FROM products p
+			JOIN categories fsub ON fsub.id = p.category_id
+			JOIN categories fc ON fsub.path LIKE fc.path || '%%'
+			%s
+			GROUP BY fc.id
+
+			UNION ALL
+
+			SELECT 'availability', CASE WHEN p.is_sold THEN 'soldout' ELSE 'available' END, COUNT(*)
+			FROM products p
+			%s
+			GROUP BY p.is_sold
+
+			UNION ALL
+
+			SELECT 'sale', fs.on_sale::text, COUNT(*)
+			FROM (SELECT EXISTS (%s) AS on_sale FROM products p %s) fs
+			GROUP BY fs.on_sale
+
+			UNION ALL
+
+			SELECT 'color', fcol.family, COUNT(DISTINCT p.id)
+			FROM products p
+			JOIN product_variants fv ON fv.product_id = p.id
+			JOIN colors fcol ON fcol.id = fv.color_id
+			%s
+			GROUP BY fcol.family
+		) f
+		ORDER BY facet, count DESC, value
+	`,
+		joinConditions(conditions, facetCategory),
+		joinConditions(conditions, facetAvailability),
+		productPromotionSubquery, joinConditions(conditions, facetSale),
+		joinConditions(conditions, facetColor),
+	)
+
+	var rows []struct {
+		Facet string `db:"facet"`
+		Value string `db:"value"`
+		Count int    `db:"count"`
+	}
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to count product facets: %w", err)
+	}
+
+	facets := &ProductFacets{
+		Categories: make(map[int]int),
+		Colors:     []ColorFacet{},
+	}
+	familyCounts := make(map[string]int)
+	for _, row := range rows {
+		switch row.Facet {
+		case facetCategory:
+			if id, err := strconv.Atoi(row.Value); err == nil {
+				facets.Categories[id] = row.Count
+			}
+		case facetAvailability:
+			if row.Value == "soldout" {
+				facets.SoldOut = row.Count
+			} else {
+				facets.Available = row.Count
+			}
+		case facetSale:
+			if row.Value == "true" {
+				facets.OnSale = row.Count
+			} else {
+				facets.NotOnSale = row.Count
+			}
+		case facetColor:
+			familyCounts[row.Value] = row.Count
+		}
+	}
+	for _, family := range models.ColorFamilies {
+		if count, ok := familyCounts[family.Key]; ok {
+			facets.Colors = append(facets.Colors, ColorFacet{Family: family, Count: count})
+		}
+	}
+
+	return facets, nil
+}
+
 // FindByID retrieves a product by ID with its variants
 func (r *ProductRepository) FindByID(id int) (*models.Product, error) {
 	query := `
 		SELECT 
-			id, code, title, description, 
+			id, code, slug, title, description, 
 			main_photo_url, main_photo_id, 
-			category_id, base_price, is_sold,
+			category_id, base_price, min_price, max_price, is_sold,
+			rating_average, rating_count,
 			created_at, updated_at
 		FROM products
 		WHERE id = $1
@@ -205,16 +471,55 @@ func (r *ProductRepository) FindByID(id int) (*models.Product, error) {
 		product.Variants = variants
 	}
 
+	// Load gallery images
+	images, err := r.findImagesByProductID(id)
+	if err != nil {
+		return nil, err
+	}
+	product.Images = images
+
 	return &product, nil
 }
 
+// FindByIDs retrieves the given products with their variants, in no particular order;
+// IDs that do not exist are skipped
+func (r *ProductRepository) FindByIDs(ids []int) ([]models.Product, error) {
+	productIDs := make([]int64, len(ids))
+	for i, id := range ids {
+		productIDs[i] = int64(id)
+	}
+
+	query := `
+		SELECT 
+			id, code, slug, title, description, 
+			main_photo_url, main_photo_id, 
+			category_id, base_price, min_price, max_price, is_sold,
+			rating_average, rating_count,
+			created_at, updated_at
+		FROM products
+		WHERE id = ANY($1)
+	`
+
+	products := []models.Product{}
+	if err := r.db.Select(&products, query, pq.Array(productIDs)); err != nil {
+		return nil, fmt.Errorf("failed to fetch products: %w", err)
+	}
+
+	if err := r.attachVariants(products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
 // FindByCode retrieves a product by code with its variants
 func (r *ProductRepository) FindByCode(code string) (*models.Product, error) {
 	query := `
 		SELECT 
-			id, code, title, description, 
+			id, code, slug, title, description, 
 			main_photo_url, main_photo_id, 
-			category_id, base_price, is_sold,
+			category_id, base_price, min_price, max_price, is_sold,
+			rating_average, rating_count,
 			created_at, updated_at
 		FROM products
 		WHERE code = $1
@@ -241,38 +546,252 @@ func (r *ProductRepository) FindByCode(code string) (*models.Product, error) {
 	return &product, nil
 }
 
-// Search searches products by title or code
+// FindIDBySlug resolves a current product slug to its ID
+func (r *ProductRepository) FindIDBySlug(slug string) (int, error) {
+	var id int
+	if err := r.db.Get(&id, `SELECT id FROM products WHERE slug = $1`, slug); err != nil {
+		return 0, fmt.Errorf("failed to fetch product by slug: %w", err)
+	}
+	return id, nil
+}
+
+// FindIDBySlugHistory resolves a slug the product used before a rename to its ID
+func (r *ProductRepository) FindIDBySlugHistory(slug string) (int, error) {
+	var id int
+	if err := r.db.Get(&id, `SELECT product_id FROM product_slug_history WHERE slug = $1`, slug); err != nil {
+		return 0, fmt.Errorf("failed to fetch product by old slug: %w", err)
+	}
+	return id, nil
+}
+
+// SlugTaken reports whether slug is in use, now or historically, by a product other than excludeID
+func (r *ProductRepository) SlugTaken(slug string, excludeID int) (bool, error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM products WHERE slug = $1 AND id <> $2)
+			OR EXISTS (SELECT 1 FROM product_slug_history WHERE slug = $1 AND product_id <> $2)
+	`
+
+	var taken bool
+	if err := r.db.Get(&taken, query, slug, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check product slug: %w", err)
+	}
+	return taken, nil
+}
+
+// RecordSlugChange remembers a product's previous slug within a transaction so shared
+// links keep working. A slug that becomes current again is dropped from the history.
+func (r *ProductRepository) RecordSlugChange(tx *sqlx.Tx, productID int, oldSlug, newSlug string) error {
+	if _, err := tx.Exec(`DELETE FROM product_slug_history WHERE slug = $1`, newSlug); err != nil {
+		return fmt.Errorf("failed to clear slug history: %w", err)
+	}
+
+	query := `
+		INSERT INTO product_slug_history (slug, product_id)
+		VALUES ($1, $2)
+		ON CONFLICT (slug) DO UPDATE
+		SET product_id = EXCLUDED.product_id, created_at = CURRENT_TIMESTAMP
+	`
+	if _, err := tx.Exec(query, oldSlug, productID); err != nil {
+		return fmt.Errorf("failed to record slug history: %w", err)
+	}
+
+	return nil
+}
+
+// Search finds products by full-text search, best matches first. Text that is exactly
+// a product code returns just that product.
 func (r *ProductRepository) Search(query string) ([]models.Product, error) {
-	searchPattern := "%" + query + "%"
-	sqlQuery := `
+	sqlQuery := fmt.Sprintf(`
 		SELECT 
-			id, code, title, description, 
-			main_photo_url, main_photo_id, 
-			category_id, base_price, is_sold,
-			created_at, updated_at
-		FROM products
-		WHERE title ILIKE $1 OR code ILIKE $1
-		ORDER BY title ASC
+			p.id, p.code, p.slug, p.title, p.description, 
+			p.main_photo_url, p.main_photo_id, 
+			p.category_id, p.base_price, p.min_price, p.max_price, p.is_sold,
+			p.rating_average, p.rating_count,
+			p.created_at, p.updated_at
+		FROM products p
+		WHERE %s
+		ORDER BY ts_rank(p.search_vector, %s) DESC, p.title ASC
 		LIMIT 50
-	`
+	`, searchCondition(1), searchQuery(1))
 
 	var products []models.Product
-	err := r.db.Select(&products, sqlQuery, searchPattern)
+	err := r.db.Select(&products, sqlQuery, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 
+	// Load variants for all results at once (card prices span them)
+	if err := r.attachVariants(products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// SearchSimilar finds products by trigram similarity, closest first. It is the fallback
+// when full-text search finds nothing.
+func (r *ProductRepository) SearchSimilar(query string) ([]models.Product, error) {
+	sqlQuery := fmt.Sprintf(`
+		SELECT 
+			p.id, p.code, p.slug, p.title, p.description, 
+			p.main_photo_url, p.main_photo_id, 
+			p.category_id, p.base_price, p.min_price, p.max_price, p.is_sold,
+			p.rating_average, p.rating_count,
+			p.created_at, p.updated_at
+		FROM products p
+		WHERE %s
+		ORDER BY %s DESC, p.title ASC
+		LIMIT 50
+	`, similarCondition(1), similarityRank(1))
+
+	var products []models.Product
+	if err := r.db.Select(&products, sqlQuery, query); err != nil {
+		return nil, fmt.Errorf("failed to search similar products: %w", err)
+	}
+
+	// Load variants for all results at once (card prices span them)
+	if err := r.attachVariants(products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// FindByPrefix lists products whose code or title starts with text, or that have a
+// title word starting with it. Title and code prefixes rank first.
+func (r *ProductRepository) FindByPrefix(text string, limit int) ([]models.Product, error) {
+	start, word := prefixPatterns(text)
+	query := `
+		SELECT 
+			p.id, p.code, p.slug, p.title, p.description, 
+			p.main_photo_url, p.main_photo_id, 
+			p.category_id, p.base_price, p.min_price, p.max_price, p.is_sold,
+			p.rating_average, p.rating_count,
+			p.created_at, p.updated_at
+		FROM products p
+		WHERE lower(p.title) LIKE $1 OR lower(p.code) LIKE $1 OR p.title ILIKE $2
+		ORDER BY (lower(p.title) LIKE $1 OR lower(p.code) LIKE $1) DESC, p.is_sold ASC, p.title ASC
+		LIMIT $3
+	`
+
+	var products []models.Product
+	if err := r.db.Select(&products, query, start, word, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch product suggestions: %w", err)
+	}
+
+	return products, nil
+}
+
+// SuggestWords replaces each search word with the closest word used in a product title
+// or category name (see RefreshVocabulary). Words with no close match are returned unchanged.
+func (r *ProductRepository) SuggestWords(words []string) ([]string, error) {
+	query := `
+		SELECT COALESCE(best.word, q.word)
+		FROM unnest($1::text[]) WITH ORDINALITY AS q(word, pos)
+		LEFT JOIN LATERAL (
+			SELECT v.word FROM search_vocabulary v
+			WHERE v.word % q.word
+			ORDER BY similarity(v.word, q.word) DESC, v.word ASC
+			LIMIT 1
+		) best ON true
+		ORDER BY q.pos
+	`
+
+	var suggested []string
+	if err := r.db.Select(&suggested, query, pq.Array(words)); err != nil {
+		return nil, fmt.Errorf("failed to suggest search words: %w", err)
+	}
+
+	return suggested, nil
+}
+
+// RefreshVocabulary rebuilds the word list SuggestWords draws from
+func (r *ProductRepository) RefreshVocabulary() error {
+	if _, err := r.db.Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY search_vocabulary`); err != nil {
+		return fmt.Errorf("failed to refresh search vocabulary: %w", err)
+	}
+	return nil
+}
+
+// Count returns the number of products in the catalog
+func (r *ProductRepository) Count() (int, error) {
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM products`); err != nil {
+		return 0, fmt.Errorf("failed to count products: %w", err)
+	}
+	return count, nil
+}
+
+// FindSitemapPage lists limit products from offset, in ID order, with only the
+// ID, slug and last update filled in
+func (r *ProductRepository) FindSitemapPage(limit, offset int) ([]models.Product, error) {
+	query := `
+		SELECT id, slug, updated_at
+		FROM products
+		ORDER BY id ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	var products []models.Product
+	if err := r.db.Select(&products, query, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to fetch sitemap products: %w", err)
+	}
+
 	return products, nil
 }
 
+// Each walks the whole catalog in ID order, batchSize products at a time, with their
+// variants and gallery images, so exports never hold a large catalog in memory
+func (r *ProductRepository) Each(batchSize int, fn func(products []models.Product) error) error {
+	query := `
+		SELECT
+			id, code, slug, title, description,
+			main_photo_url, main_photo_id,
+			category_id, base_price, min_price, max_price, is_sold,
+			rating_average, rating_count,
+			created_at, updated_at
+		FROM products
+		WHERE id > $1
+		ORDER BY id ASC
+		LIMIT $2
+	`
+
+	afterID := 0
+	for {
+		var products []models.Product
+		if err := r.db.Select(&products, query, afterID, batchSize); err != nil {
+			return fmt.Errorf("failed to fetch products: %w", err)
+		}
+		if len(products) == 0 {
+			return nil
+		}
+
+		if err := r.attachVariants(products); err != nil {
+			return err
+		}
+		if err := r.attachImages(products); err != nil {
+			return err
+		}
+		if err := fn(products); err != nil {
+			return err
+		}
+
+		if len(products) < batchSize {
+			return nil
+		}
+		afterID = products[len(products)-1].ID
+	}
+}
+
 // Create inserts a new product
 func (r *ProductRepository) Create(product *models.Product) error {
 	query := `
 		INSERT INTO products (
 			code, title, description, main_photo_url, main_photo_id,
-			category_id, base_price, is_sold
+			category_id, base_price, slug
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
+		RETURNING id, is_sold, created_at, updated_at
 	`
 
 	err := r.db.QueryRow(
@@ -284,8 +803,8 @@ func (r *ProductRepository) Create(product *models.Product) error {
 		product.MainPhotoID,
 		product.CategoryID,
 		product.BasePrice,
-		product.IsSold,
-	).Scan(&product.ID, &product.CreatedAt, &product.UpdatedAt)
+		product.Slug,
+	).Scan(&product.ID, &product.IsSold, &product.CreatedAt, &product.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create product: %w", err)
@@ -294,8 +813,8 @@ func (r *ProductRepository) Create(product *models.Product) error {
 	return nil
 }
 
-// Update updates an existing product
-func (r *ProductRepository) Update(product *models.Product) error {
+// Update updates an existing product within a transaction
+func (r *ProductRepository) Update(tx *sqlx.Tx, product *models.Product) error {
 	query := `
 		UPDATE products
 		SET 
@@ -306,13 +825,13 @@ func (r *ProductRepository) Update(product *models.Product) error {
 			main_photo_id = $5,
 			category_id = $6,
 			base_price = $7,
-			is_sold = $8,
+			slug = $8,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $9
-		RETURNING updated_at
+		RETURNING is_sold, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err := tx.QueryRow(
 		query,
 		product.Code,
 		product.Title,
@@ -321,9 +840,9 @@ func (r *ProductRepository) Update(product *models.Product) error {
 		product.MainPhotoID,
 		product.CategoryID,
 		product.BasePrice,
-		product.IsSold,
+		product.Slug,
 		product.ID,
-	).Scan(&product.UpdatedAt)
+	).Scan(&product.IsSold, &product.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to update product: %w", err)
@@ -353,19 +872,19 @@ func (r *ProductRepository) Delete(id int) error {
 	return nil
 }
 
-// CreateVariants creates product variants within a transaction
+// CreateVariants creates product variants within a transaction.
+// Generated IDs are written back into the slice. Stock always starts at zero;
+// it only changes through inventory movements.
 func (r *ProductRepository) CreateVariants(tx *sqlx.Tx, productID int, variants []models.ProductVariant) error {
 	query := `
 		INSERT INTO product_variants (
-			product_id, color, photo_url, photo_id, price_adjustment, is_sale
+			product_id, color, photo_url, photo_id, price_adjustment, color_id
 		) VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		RETURNING id, stock_qty, created_at, updated_at
 	`
 
-	for _, variant := range variants {
-		var variantID int
-		var createdAt, updatedAt time.Time
-
+	for i := range variants {
+		variant := &variants[i]
 		err := tx.QueryRow(
 			query,
 			productID,
@@ -373,40 +892,321 @@ func (r *ProductRepository) CreateVariants(tx *sqlx.Tx, productID int, variants
 			variant.PhotoURL,
 			variant.PhotoID,
 			variant.PriceAdjustment,
-			variant.IsSale,
-		).Scan(&variantID, &createdAt, &updatedAt)
+			variant.ColorID,
+		).Scan(&variant.ID, &variant.StockQty, &variant.CreatedAt, &variant.UpdatedAt)
 
 		if err != nil {
 			return fmt.Errorf("failed to create variant %s: %w", variant.Color, err)
 		}
+		variant.ProductID = productID
+
+		if err := r.ReplacePriceTiers(tx, variant.ID, variant.PriceTiers); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// DeleteVariantsByProductID deletes all variants for a product
-func (r *ProductRepository) DeleteVariantsByProductID(tx *sqlx.Tx, productID int) error {
-	query := `DELETE FROM product_variants WHERE product_id = $1`
-	_, err := tx.Exec(query, productID)
+// SyncVariants makes the product's variants match the given list within a transaction:
+// variants with an ID are updated in place (keeping their stock and ledger), variants
+// without an ID are inserted, and variants missing from the list are deleted.
+func (r *ProductRepository) SyncVariants(tx *sqlx.Tx, productID int, variants []models.ProductVariant) error {
+	updateQuery := `
+		UPDATE product_variants
+		SET
+			color = $1,
+			photo_url = $2,
+			photo_id = $3,
+			price_adjustment = $4,
+			color_id = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND product_id = $7
+		RETURNING stock_qty, updated_at
+	`
+
+	keepIDs := make([]int64, 0, len(variants))
+	for _, variant := range variants {
+		if variant.ID > 0 {
+			keepIDs = append(keepIDs, int64(variant.ID))
+		}
+	}
+
+	// Check UNIQUE(product_id, color) at commit so two variants can swap colours
+	if _, err := tx.Exec(`SET CONSTRAINTS product_variants_product_id_color_key DEFERRED`); err != nil {
+		return fmt.Errorf("failed to defer variant color check: %w", err)
+	}
+
+	// Delete removed variants first so a re-added color does not hit UNIQUE(product_id, color)
+	_, err := tx.Exec(
+		`DELETE FROM product_variants WHERE product_id = $1 AND NOT (id = ANY($2))`,
+		productID, pq.Array(keepIDs),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to delete variants: %w", err)
+		return fmt.Errorf("failed to delete removed variants: %w", err)
 	}
+
+	var newVariants []int
+	for i := range variants {
+		variant := &variants[i]
+		if variant.ID <= 0 {
+			newVariants = append(newVariants, i)
+			continue
+		}
+		err := tx.QueryRow(
+			updateQuery,
+			variant.Color,
+			variant.PhotoURL,
+			variant.PhotoID,
+			variant.PriceAdjustment,
+			variant.ColorID,
+			variant.ID,
+			productID,
+		).Scan(&variant.StockQty, &variant.UpdatedAt)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("variant %d does not belong to product %d", variant.ID, productID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update variant %s: %w", variant.Color, err)
+		}
+
+		if err := r.ReplacePriceTiers(tx, variant.ID, variant.PriceTiers); err != nil {
+			return err
+		}
+	}
+
+	for _, i := range newVariants {
+		if err := r.CreateVariants(tx, productID, variants[i:i+1]); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// findVariantsByProductID is a helper to load variants for a product
-func (r *ProductRepository) findVariantsByProductID(productID int) ([]models.ProductVariant, error) {
+// ReplacePriceTiers replaces a variant's wholesale price tiers within a transaction
+func (r *ProductRepository) ReplacePriceTiers(tx *sqlx.Tx, variantID int, tiers []models.PriceTier) error {
+	if _, err := tx.Exec(`DELETE FROM variant_price_tiers WHERE variant_id = $1`, variantID); err != nil {
+		return fmt.Errorf("failed to clear price tiers: %w", err)
+	}
+
 	query := `
-		SELECT 
-			id, product_id, color, photo_url, photo_id,
-			price_adjustment, is_sale, created_at, updated_at
-		FROM product_variants
-		WHERE product_id = $1
-		ORDER BY color ASC
+		INSERT INTO variant_price_tiers (variant_id, min_qty, unit_price)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	for i := range tiers {
+		tier := &tiers[i]
+		err := tx.QueryRow(query, variantID, tier.MinQty, tier.UnitPrice).
+			Scan(&tier.ID, &tier.CreatedAt, &tier.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create price tier (min %d): %w", tier.MinQty, err)
+		}
+		tier.VariantID = variantID
+	}
+
+	return nil
+}
+
+// SyncImages makes the product's gallery match the given list within a transaction:
+// images with an ID are updated in place, images without an ID are inserted, and
+// images missing from the list are deleted. VariantID must already be resolved.
+func (r *ProductRepository) SyncImages(tx *sqlx.Tx, productID int, images []models.ProductImage) error {
+	keepIDs := make([]int64, 0, len(images))
+	for _, image := range images {
+		if image.ID > 0 {
+			keepIDs = append(keepIDs, int64(image.ID))
+		}
+	}
+
+	_, err := tx.Exec(
+		`DELETE FROM product_images WHERE product_id = $1 AND NOT (id = ANY($2))`,
+		productID, pq.Array(keepIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete removed images: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE product_images
+		SET
+			variant_id = $1,
+			alt_text = $2,
+			sort_order = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND product_id = $5
+		RETURNING image_url, image_id, created_at, updated_at
+	`
+	insertQuery := `
+		INSERT INTO product_images (
+			product_id, variant_id, image_url, image_id, alt_text, sort_order
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	for i := range images {
+		image := &images[i]
+		if image.ID > 0 {
+			err := tx.QueryRow(
+				updateQuery,
+				image.VariantID,
+				image.AltText,
+				image.SortOrder,
+				image.ID,
+				productID,
+			).Scan(&image.ImageURL, &image.ImageID, &image.CreatedAt, &image.UpdatedAt)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("image %d does not belong to product %d", image.ID, productID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update image %d: %w", image.ID, err)
+			}
+		} else {
+			err := tx.QueryRow(
+				insertQuery,
+				productID,
+				image.VariantID,
+				image.ImageURL,
+				image.ImageID,
+				image.AltText,
+				image.SortOrder,
+			).Scan(&image.ID, &image.CreatedAt, &image.UpdatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to create image: %w", err)
+			}
+		}
+		image.ProductID = productID
+	}
+
+	return nil
+}
+
+// RefreshAvailability recomputes products.is_sold from variant stock within a transaction.
+// A product is sold out when none of its variants has stock left.
+func (r *ProductRepository) RefreshAvailability(tx *sqlx.Tx, productID int) error {
+	query := `
+		UPDATE products p
+		SET is_sold = NOT EXISTS (
+			SELECT 1 FROM product_variants pv
+			WHERE pv.product_id = p.id AND pv.stock_qty > 0
+		)
+		WHERE p.id = $1
+	`
+	if _, err := tx.Exec(query, productID); err != nil {
+		return fmt.Errorf("failed to refresh availability: %w", err)
+	}
+	return nil
+}
+
+// RefreshPriceRange recomputes products.min_price and max_price from the variant prices
+// within a transaction. Call it after the base price or variant prices change.
+func (r *ProductRepository) RefreshPriceRange(tx *sqlx.Tx, productID int) error {
+	query := `
+		UPDATE products p SET
+			min_price = COALESCE(v.min_price, p.base_price),
+			max_price = COALESCE(v.max_price, p.base_price)
+		FROM (
+			SELECT
+				MIN(CASE WHEN pv.price_adjustment > 0 THEN pv.price_adjustment ELSE bp.base_price END) AS min_price,
+				MAX(CASE WHEN pv.price_adjustment > 0 THEN pv.price_adjustment ELSE bp.base_price END) AS max_price
+			FROM products bp
+			LEFT JOIN product_variants pv ON pv.product_id = bp.id
+			WHERE bp.id = $1
+		) v
+		WHERE p.id = $1
+	`
+	if _, err := tx.Exec(query, productID); err != nil {
+		return fmt.Errorf("failed to refresh price range: %w", err)
+	}
+	return nil
+}
+
+// PriceRange is the lowest and highest unit price a product currently sells at
+type PriceRange struct {
+	ProductID int
+	Min       float64
+	Max       float64
+}
+
+// FindForPricing retrieves every product with just the fields pricing reads: the base
+// price, category and variant prices. Feed the result through the service's pricing.
+func (r *ProductRepository) FindForPricing() ([]models.Product, error) {
+	var products []models.Product
+	if err := r.db.Select(&products, `SELECT id, category_id, base_price FROM products ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("failed to fetch products for pricing: %w", err)
+	}
+
+	var variants []models.ProductVariant
+	if err := r.db.Select(&variants, `SELECT id, product_id, price_adjustment FROM product_variants ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("failed to fetch variants for pricing: %w", err)
+	}
+
+	index := make(map[int]int, len(products))
+	for i := range products {
+		index[products[i].ID] = i
+	}
+	for _, variant := range variants {
+		if i, ok := index[variant.ProductID]; ok {
+			products[i].Variants = append(products[i].Variants, variant)
+		}
+	}
+
+	return products, nil
+}
+
+// UpdateCurrentPrices stores products.current_min_price and current_max_price, the
+// public price range after live promotions that price filters and sorting use, within
+// a transaction. Only rows whose range changed are written.
+func (r *ProductRepository) UpdateCurrentPrices(tx *sqlx.Tx, ranges []PriceRange) error {
+	ids := make([]int64, len(ranges))
+	mins := make([]float64, len(ranges))
+	maxes := make([]float64, len(ranges))
+	for i, priceRange := range ranges {
+		ids[i] = int64(priceRange.ProductID)
+		mins[i] = priceRange.Min
+		maxes[i] = priceRange.Max
+	}
+
+	query := `
+		UPDATE products p SET
+			current_min_price = r.min_price,
+			current_max_price = r.max_price
+		FROM unnest($1::int[], $2::numeric[], $3::numeric[]) AS r(id, min_price, max_price)
+		WHERE p.id = r.id
+			AND (p.current_min_price, p.current_max_price) IS DISTINCT FROM (r.min_price, r.max_price)
 	`
+	if _, err := tx.Exec(query, pq.Array(ids), pq.Array(mins), pq.Array(maxes)); err != nil {
+		return fmt.Errorf("failed to update current prices: %w", err)
+	}
+	return nil
+}
+
+// RefreshSearchVector recomputes products.search_vector within a transaction.
+// Call it after the title, code, description, category or variant colours change.
+func (r *ProductRepository) RefreshSearchVector(tx *sqlx.Tx, productID int) error {
+	query := `UPDATE products p SET search_vector = ` + productSearchVector + ` WHERE p.id = $1`
+	if _, err := tx.Exec(query, productID); err != nil {
+		return fmt.Errorf("failed to refresh search index: %w", err)
+	}
+	return nil
+}
+
+// variantQuery selects variants with their palette colour; %s is the WHERE condition
+const variantQuery = `
+	SELECT 
+		pv.id, pv.product_id, pv.color, pv.color_id, pv.photo_url, pv.photo_id,
+		pv.price_adjustment, pv.stock_qty, pv.created_at, pv.updated_at,
+		COALESCE(c.hex, '') AS color_hex, COALESCE(c.family, '') AS color_family
+	FROM product_variants pv
+	LEFT JOIN colors c ON c.id = pv.color_id
+	WHERE %s
+	ORDER BY pv.product_id, pv.color ASC
+`
 
+// findVariantsByProductID is a helper to load variants for a product
+func (r *ProductRepository) findVariantsByProductID(productID int) ([]models.ProductVariant, error) {
 	var variants []models.ProductVariant
-	err := r.db.Select(&variants, query, productID)
+	err := r.db.Select(&variants, fmt.Sprintf(variantQuery, "pv.product_id = $1"), productID)
 	if err != nil {
 		return []models.ProductVariant{}, fmt.Errorf("failed to fetch variants: %w", err)
 	}
@@ -416,5 +1216,199 @@ func (r *ProductRepository) findVariantsByProductID(productID int) ([]models.Pro
 		return []models.ProductVariant{}, nil
 	}
 
+	if err := r.attachPriceTiers(variants); err != nil {
+		return []models.ProductVariant{}, err
+	}
+
 	return variants, nil
 }
+
+// attachVariants loads the variants of a page of products, with their price tiers,
+// in two queries whatever the number of products
+func (r *ProductRepository) attachVariants(products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	index := make(map[int]int, len(products))
+	for i := range products {
+		productIDs[i] = int64(products[i].ID)
+		index[products[i].ID] = i
+		products[i].Variants = []models.ProductVariant{}
+	}
+
+	var variants []models.ProductVariant
+	err := r.db.Select(&variants, fmt.Sprintf(variantQuery, "pv.product_id = ANY($1)"), pq.Array(productIDs))
+	if err != nil {
+		return fmt.Errorf("failed to fetch variants: %w", err)
+	}
+	if len(variants) == 0 {
+		return nil
+	}
+
+	if err := r.attachPriceTiers(variants); err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		product := &products[index[variant.ProductID]]
+		product.Variants = append(product.Variants, variant)
+	}
+
+	return nil
+}
+
+// attachPriceTiers loads the wholesale price tiers for the given variants in one query
+func (r *ProductRepository) attachPriceTiers(variants []models.ProductVariant) error {
+	variantIDs := make([]int64, len(variants))
+	index := make(map[int]int, len(variants))
+	for i, v := range variants {
+		variantIDs[i] = int64(v.ID)
+		index[v.ID] = i
+	}
+
+	query := `
+		SELECT id, variant_id, min_qty, unit_price, created_at, updated_at
+		FROM variant_price_tiers
+		WHERE variant_id = ANY($1)
+		ORDER BY variant_id, min_qty ASC
+	`
+
+	var tiers []models.PriceTier
+	if err := r.db.Select(&tiers, query, pq.Array(variantIDs)); err != nil {
+		return fmt.Errorf("failed to fetch price tiers: %w", err)
+	}
+
+	for _, tier := range tiers {
+		v := &variants[index[tier.VariantID]]
+		v.PriceTiers = append(v.PriceTiers, tier)
+	}
+
+	return nil
+}
+
+// findImagesByProductID loads a product's gallery in display order
+func (r *ProductRepository) findImagesByProductID(productID int) ([]models.ProductImage, error) {
+	query := `
+		SELECT
+			pi.id, pi.product_id, pi.variant_id, pi.image_url, pi.image_id,
+			pi.alt_text, pi.sort_order, pi.created_at, pi.updated_at,
+			COALESCE(pv.color, '') AS variant_color
+		FROM product_images pi
+		LEFT JOIN product_variants pv ON pv.id = pi.variant_id
+		WHERE pi.product_id = $1
+		ORDER BY pi.sort_order ASC, pi.id ASC
+	`
+
+	images := []models.ProductImage{}
+	if err := r.db.Select(&images, query, productID); err != nil {
+		return nil, fmt.Errorf("failed to fetch product images: %w", err)
+	}
+
+	return images, nil
+}
+
+// attachImages loads the galleries of a page of products in one query
+func (r *ProductRepository) attachImages(products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]int64, len(products))
+	index := make(map[int]int, len(products))
+	for i := range products {
+		productIDs[i] = int64(products[i].ID)
+		index[products[i].ID] = i
+		products[i].Images = []models.ProductImage{}
+	}
+
+	query := `
+		SELECT
+			pi.id, pi.product_id, pi.variant_id, pi.image_url, pi.image_id,
+			pi.alt_text, pi.sort_order, pi.created_at, pi.updated_at,
+			COALESCE(pv.color, '') AS variant_color
+		FROM product_images pi
+		LEFT JOIN product_variants pv ON pv.id = pi.variant_id
+		WHERE pi.product_id = ANY($1)
+		ORDER BY pi.product_id, pi.sort_order ASC, pi.id ASC
+	`
+
+	var images []models.ProductImage
+	if err := r.db.Select(&images, query, pq.Array(productIDs)); err != nil {
+		return fmt.Errorf("failed to fetch product images: %w", err)
+	}
+
+	for _, image := range images {
+		product := &products[index[image.ProductID]]
+		product.Images = append(product.Images, image)
+	}
+
+	return nil
+}
+
+// FindTranslations retrieves the given products' text in locale, keyed by product ID
+func (r *ProductRepository) FindTranslations(productIDs []int, locale string) (map[int]models.ProductTranslation, error) {
+	translations := make(map[int]models.ProductTranslation)
+	if len(productIDs) == 0 {
+		return translations, nil
+	}
+
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+
+	var rows []models.ProductTranslation
+	err := r.db.Select(&rows, `
+		SELECT product_id, locale, title, description
+		FROM product_translations
+		WHERE product_id = ANY($1) AND locale = $2
+	`, pq.Array(ids), locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product translations: %w", err)
+	}
+
+	for _, row := range rows {
+		translations[row.ProductID] = row
+	}
+	return translations, nil
+}
+
+// FindTranslationsByProduct retrieves a product's text in every translated locale
+func (r *ProductRepository) FindTranslationsByProduct(productID int) ([]models.ProductTranslation, error) {
+	translations := []models.ProductTranslation{}
+	err := r.db.Select(&translations, `
+		SELECT product_id, locale, title, description
+		FROM product_translations
+		WHERE product_id = $1
+		ORDER BY locale
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product translations: %w", err)
+	}
+	return translations, nil
+}
+
+// SyncTranslations replaces a product's translations within a transaction.
+// Blank translations are not stored.
+func (r *ProductRepository) SyncTranslations(tx *sqlx.Tx, productID int, translations []models.ProductTranslation) error {
+	if _, err := tx.Exec(`DELETE FROM product_translations WHERE product_id = $1`, productID); err != nil {
+		return fmt.Errorf("failed to delete product translations: %w", err)
+	}
+
+	for _, translation := range translations {
+		if translation.IsBlank() {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO product_translations (product_id, locale, title, description)
+			VALUES ($1, $2, $3, $4)
+		`, productID, translation.Locale, translation.Title, translation.Description)
+		if err != nil {
+			return fmt.Errorf("failed to save product translation: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// storeNow is the database clock as a wall-clock time in the store's zone (see
+// models.StoreLocation), independent of the session's TimeZone setting
+const storeNow = `(CURRENT_TIMESTAMP AT TIME ZONE 'Asia/Jakarta')`
+
+// livePromotionCondition matches promotions (aliased pr) that discount prices right now.
+// The database clock is the single source of truth so filters and prices always agree.
+const livePromotionCondition = `pr.is_active AND pr.starts_at <= ` + storeNow + ` AND pr.ends_at > ` + storeNow
+
+// productPromotionSubquery selects live promotions targeting product p directly,
+// through its category or one above it in the tree, or through one of its variants
+const productPromotionSubquery = `
+	SELECT 1 FROM promotion_targets pt
+	JOIN promotions pr ON pr.id = pt.promotion_id
+	WHERE ` + livePromotionCondition + ` AND (
+		pt.product_id = p.id
+		OR pt.category_id IN (
+			SELECT target.id FROM categories target
+			JOIN categories own ON own.path LIKE target.path || '%'
+			WHERE own.id = p.category_id
+		)
+		OR pt.variant_id IN (SELECT pv.id FROM product_variants pv WHERE pv.product_id = p.id)
+	)
+`
+
+// promotionColumns lists promotion columns plus the computed status
+const promotionColumns = `
+	pr.id, pr.name, pr.discount_type, pr.discount_value,
+	pr.starts_at, pr.ends_at, pr.is_active,
+	CASE
+		WHEN NOT pr.is_active THEN 'disabled'
+		WHEN pr.starts_at > ` + storeNow + ` THEN 'scheduled'
+		WHEN pr.ends_at <= ` + storeNow + ` THEN 'ended'
+		ELSE 'live'
+	END AS status,
+	pr.created_at, pr.updated_at
+`
+
+// PromotionRepository handles promotion data access
+type PromotionRepository struct {
+	db *sqlx.DB
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *sqlx.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// PromotionTargetOption is a product (and optionally one of its variants) that a promotion can target
+type PromotionTargetOption struct {
+	ProductID    int     `db:"product_id"`
+	ProductCode  string  `db:"product_code"`
+	ProductTitle string  `db:"product_title"`
+	VariantID    *int    `db:"variant_id"`
+	VariantColor *string `db:"variant_color"`
+}
+
+// FindAll retrieves all promotions with their targets, most recent first
+func (r *PromotionRepository) FindAll() ([]models.Promotion, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM promotions pr
+		ORDER BY pr.starts_at DESC, pr.id DESC
+	`, promotionColumns)
+
+	var promotions []models.Promotion
+	if err := r.db.Select(&promotions, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch promotions: %w", err)
+	}
+
+	if err := r.attachTargets(promotions); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// FindLive retrieves the promotions that are live right now, with their targets
+func (r *PromotionRepository) FindLive() ([]models.Promotion, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM promotions pr
+		WHERE %s
+	`, promotionColumns, livePromotionCondition)
+
+	var promotions []models.Promotion
+	if err := r.db.Select(&promotions, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch live promotions: %w", err)
+	}
+
+	if err := r.attachTargets(promotions); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// NextChange returns when the live promotions next change without anyone editing them,
+// as a wall-clock time in the store's zone: the earliest start or end of an active
+// promotion still ahead. It returns nil when none is scheduled.
+func (r *PromotionRepository) NextChange() (*time.Time, error) {
+	query := `
+		SELECT MIN(at) FROM (
+			SELECT starts_at AS at FROM promotions WHERE is_active
+			UNION ALL
+			SELECT ends_at FROM promotions WHERE is_active
+		) boundaries
+		WHERE at > ` + storeNow
+
+	var next *time.Time
+	if err := r.db.Get(&next, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch next promotion change: %w", err)
+	}
+	return next, nil
+}
+
+// FindByID retrieves a promotion by ID with its targets
+func (r *PromotionRepository) FindByID(id int) (*models.Promotion, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM promotions pr
+		WHERE pr.id = $1
+	`, promotionColumns)
+
+	var promotion models.Promotion
+	if err := r.db.Get(&promotion, query, id); err != nil {
+		return nil, err
+	}
+
+	promotions := []models.Promotion{promotion}
+	if err := r.attachTargets(promotions); err != nil {
+		return nil, err
+	}
+
+	return &promotions[0], nil
+}
+
+// Create inserts a promotion within a transaction
+func (r *PromotionRepository) Create(tx *sqlx.Tx, promotion *models.Promotion) error {
+	query := `
+		INSERT INTO promotions (name, discount_type, discount_value, starts_at, ends_at, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		promotion.Name,
+		promotion.DiscountType,
+		promotion.DiscountValue,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.IsActive,
+	).Scan(&promotion.ID, &promotion.CreatedAt, &promotion.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a promotion within a transaction
+func (r *PromotionRepository) Update(tx *sqlx.Tx, promotion *models.Promotion) error {
+	query := `
+		UPDATE promotions
+		SET
+			name = $1,
+			discount_type = $2,
+			discount_value = $3,
+			starts_at = $4,
+			ends_at = $5,
+			is_active = $6,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING updated_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		promotion.Name,
+		promotion.DiscountType,
+		promotion.DiscountValue,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.IsActive,
+		promotion.ID,
+	).Scan(&promotion.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceTargets replaces a promotion's targets within a transaction
+func (r *PromotionRepository) ReplaceTargets(tx *sqlx.Tx, promotionID int, targets []models.PromotionTarget) error {
+	if _, err := tx.Exec(`DELETE FROM promotion_targets WHERE promotion_id = $1`, promotionID); err != nil {
+		return fmt.Errorf("failed to clear promotion targets: %w", err)
+	}
+
+	query := `
+		INSERT INTO promotion_targets (promotion_id, product_id, variant_id, category_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	for i := range targets {
+		target := &targets[i]
+		err := tx.QueryRow(query, promotionID, target.ProductID, target.VariantID, target.CategoryID).Scan(&target.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create promotion target: %w", err)
+		}
+		target.PromotionID = promotionID
+	}
+
+	return nil
+}
+
+// Delete deletes a promotion (targets cascade)
+func (r *PromotionRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("promotion with id %d not found", id)
+	}
+
+	return nil
+}
+
+// FindTargetOptions lists every product and its variants for the promotion target picker
+func (r *PromotionRepository) FindTargetOptions() ([]PromotionTargetOption, error) {
+	query := `
+		SELECT
+			p.id AS product_id, p.code AS product_code, p.title AS product_title,
+			pv.id AS variant_id, pv.color AS variant_color
+		FROM products p
+		LEFT JOIN product_variants pv ON pv.product_id = p.id
+		ORDER BY p.code ASC, pv.color ASC
+	`
+
+	var options []PromotionTargetOption
+	if err := r.db.Select(&options, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch promotion target options: %w", err)
+	}
+
+	return options, nil
+}
+
+// attachTargets loads the targets for the given promotions in one query
+func (r *PromotionRepository) attachTargets(promotions []models.Promotion) error {
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	promotionIDs := make([]int64, len(promotions))
+	index := make(map[int]int, len(promotions))
+	for i, p := range promotions {
+		promotionIDs[i] = int64(p.ID)
+		index[p.ID] = i
+	}
+
+	query := `
+		SELECT
+			pt.id, pt.promotion_id, pt.product_id, pt.variant_id, pt.category_id,
+			COALESCE(p.code || ' · ' || p.title, vp.code || ' · ' || pv.color, c.name, '') AS label,
+			ARRAY(
+				SELECT sub.id FROM categories sub
+				WHERE c.path IS NOT NULL AND sub.path LIKE c.path || '%'
+			) AS category_subtree
+		FROM promotion_targets pt
+		LEFT JOIN products p ON p.id = pt.product_id
+		LEFT JOIN product_variants pv ON pv.id = pt.variant_id
+		LEFT JOIN products vp ON vp.id = pv.product_id
+		LEFT JOIN categories c ON c.id = pt.category_id
+		WHERE pt.promotion_id = ANY($1)
+		ORDER BY pt.id ASC
+	`
+
+	var targets []models.PromotionTarget
+	if err := r.db.Select(&targets, query, pq.Array(promotionIDs)); err != nil {
+		return fmt.Errorf("failed to fetch promotion targets: %w", err)
+	}
+
+	for _, target := range targets {
+		p := &promotions[index[target.PromotionID]]
+		p.Targets = append(p.Targets, target)
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// ReviewRepository handles product review data access
+type ReviewRepository struct {
+	db *sqlx.DB
+}
+
+// NewReviewRepository creates a new review repository
+func NewReviewRepository(db *sqlx.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// ReviewFilters contains filtering options for the moderation queue
+type ReviewFilters struct {
+	Status    string
+	ProductID int
+	Page      int
+	PageSize  int
+}
+
+// ReviewListResult contains paginated reviews
+type ReviewListResult struct {
+	Reviews    []models.Review
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+// reviewColumns lists the columns selected for a review (aliased r, product p)
+const reviewColumns = `
+	r.id, r.product_id, r.customer_id, r.author_name, r.city, r.rating, r.body, r.status,
+	r.ip_address, r.approved_at, r.created_at, r.updated_at,
+	p.code AS product_code, p.title AS product_title, p.slug AS product_slug
+`
+
+// Create inserts a review with its photos within a transaction
+func (r *ReviewRepository) Create(tx *sqlx.Tx, review *models.Review) error {
+	query := `
+		INSERT INTO product_reviews (product_id, customer_id, author_name, city, rating, body, status, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRow(query, review.ProductID, review.CustomerID, review.AuthorName, review.City,
+		review.Rating, review.Body, review.Status, review.IPAddress).
+		Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	for i := range review.Photos {
+		photo := &review.Photos[i]
+		photo.ReviewID = review.ID
+		photo.SortOrder = i
+		err := tx.QueryRow(`
+			INSERT INTO product_review_photos (review_id, image_url, image_id, sort_order)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, photo.ReviewID, photo.ImageURL, photo.ImageID, photo.SortOrder).Scan(&photo.ID, &photo.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create review photo: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves a review by ID with its photos
+func (r *ReviewRepository) FindByID(id int) (*models.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM product_reviews r
+		JOIN products p ON p.id = r.product_id
+		WHERE r.id = $1
+	`
+
+	var review models.Review
+	if err := r.db.Get(&review, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	}
+
+	reviews := []models.Review{review}
+	if err := r.attachPhotos(reviews); err != nil {
+		return nil, err
+	}
+
+	return &reviews[0], nil
+}
+
+// FindApprovedByProduct retrieves the approved reviews of a product, newest first
+func (r *ReviewRepository) FindApprovedByProduct(productID, limit int) ([]models.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM product_reviews r
+		JOIN products p ON p.id = r.product_id
+		WHERE r.product_id = $1 AND r.status = $2
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $3
+	`
+
+	reviews := []models.Review{}
+	if err := r.db.Select(&reviews, query, productID, models.ReviewApproved, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	if err := r.attachPhotos(reviews); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// FindAll retrieves reviews (oldest first, so the queue is worked in order) with
+// filtering and pagination
+func (r *ReviewRepository) FindAll(filters ReviewFilters) (*ReviewListResult, error) {
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters.Status != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("r.status = $%d", argIndex))
+		args = append(args, filters.Status)
+		argIndex++
+	}
+	if filters.ProductID > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf("r.product_id = $%d", argIndex))
+		args = append(args, filters.ProductID)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 20
+	}
+	offset := (filters.Page - 1) * filters.PageSize
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM product_reviews r %s", whereClause)
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count reviews: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_reviews r
+		JOIN products p ON p.id = r.product_id
+		%s
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $%d OFFSET $%d
+	`, reviewColumns, whereClause, argIndex, argIndex+1)
+	args = append(args, filters.PageSize, offset)
+
+	reviews := []models.Review{}
+	if err := r.db.Select(&reviews, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	if err := r.attachPhotos(reviews); err != nil {
+		return nil, err
+	}
+
+	return &ReviewListResult{
+		Reviews:    reviews,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + filters.PageSize - 1) / filters.PageSize,
+	}, nil
+}
+
+// CountByStatus returns the number of reviews in each status
+func (r *ReviewRepository) CountByStatus() (map[models.ReviewStatus]int, error) {
+	var rows []struct {
+		Status models.ReviewStatus `db:"status"`
+		Count  int                 `db:"count"`
+	}
+	if err := r.db.Select(&rows, `SELECT status, COUNT(*) AS count FROM product_reviews GROUP BY status`); err != nil {
+		return nil, fmt.Errorf("failed to count reviews by status: %w", err)
+	}
+
+	counts := make(map[models.ReviewStatus]int, len(models.ReviewStatuses))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// UpdateStatus moderates a review within a transaction
+func (r *ReviewRepository) UpdateStatus(tx *sqlx.Tx, id int, status models.ReviewStatus, approvedAt *time.Time) error {
+	result, err := tx.Exec(`
+		UPDATE product_reviews
+		SET status = $1, approved_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, status, approvedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("review with id %d not found", id)
+	}
+
+	return nil
+}
+
+// Delete removes a review (with its photos) within a transaction
+func (r *ReviewRepository) Delete(tx *sqlx.Tx, id int) error {
+	if _, err := tx.Exec(`DELETE FROM product_reviews WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete review: %w", err)
+	}
+	return nil
+}
+
+// RefreshProductRating recomputes a product's rating from its approved reviews
+// within a transaction
+func (r *ReviewRepository) RefreshProductRating(tx *sqlx.Tx, productID int) error {
+	_, err := tx.Exec(`
+		UPDATE products SET
+			rating_average = COALESCE((
+				SELECT ROUND(AVG(rating), 2) FROM product_reviews
+				WHERE product_id = $1 AND status = $2
+			), 0),
+			rating_count = (
+				SELECT COUNT(*) FROM product_reviews
+				WHERE product_id = $1 AND status = $2
+			)
+		WHERE id = $1
+	`, productID, models.ReviewApproved)
+	if err != nil {
+		return fmt.Errorf("failed to refresh product rating: %w", err)
+	}
+	return nil
+}
+
+// attachPhotos loads the photos of all reviews in one query
+func (r *ReviewRepository) attachPhotos(reviews []models.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	reviewIDs := make([]int64, len(reviews))
+	for i, review := range reviews {
+		reviewIDs[i] = int64(review.ID)
+	}
+
+	photos := []models.ReviewPhoto{}
+	err := r.db.Select(&photos, `
+		SELECT id, review_id, image_url, image_id, sort_order, created_at
+		FROM product_review_photos
+		WHERE review_id = ANY($1)
+		ORDER BY review_id, sort_order ASC, id ASC
+	`, pq.Array(reviewIDs))
+	if err != nil {
+		return fmt.Errorf("failed to fetch review photos: %w", err)
+	}
+
+	byReview := make(map[int][]models.ReviewPhoto, len(reviews))
+	for _, photo := range photos {
+		byReview[photo.ReviewID] = append(byReview[photo.ReviewID], photo)
+	}
+	for i := range reviews {
+		reviews[i].Photos = byReview[reviews[i].ID]
+	}
+
+	return nil
+}
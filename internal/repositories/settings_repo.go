@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SettingsRepository handles store settings data access
+type SettingsRepository struct {
+	db *sqlx.DB
+}
+
+// NewSettingsRepository creates a new settings repository
+func NewSettingsRepository(db *sqlx.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// FindAll retrieves every stored setting by key
+func (r *SettingsRepository) FindAll() (map[string]string, error) {
+	var rows []struct {
+		Key   string `db:"key"`
+		Value string `db:"value"`
+	}
+	if err := r.db.Select(&rows, `SELECT key, value FROM settings`); err != nil {
+		return nil, fmt.Errorf("failed to fetch settings: %w", err)
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		values[row.Key] = row.Value
+	}
+	return values, nil
+}
+
+// InsertMissing stores the given values for keys that have no setting yet,
+// leaving saved settings alone
+func (r *SettingsRepository) InsertMissing(values map[string]string) error {
+	query := `
+		INSERT INTO settings (key, value)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO NOTHING
+	`
+
+	for key, value := range values {
+		if _, err := r.db.Exec(query, key, value); err != nil {
+			return fmt.Errorf("failed to insert setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Save stores the given values within a transaction, replacing saved ones
+func (r *SettingsRepository) Save(tx *sqlx.Tx, values map[string]string) error {
+	query := `
+		INSERT INTO settings (key, value)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+	`
+
+	for key, value := range values {
+		if _, err := tx.Exec(query, key, value); err != nil {
+			return fmt.Errorf("failed to save setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
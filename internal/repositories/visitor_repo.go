@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// VisitorRepository handles anonymous visitor data access
+type VisitorRepository struct {
+	db *sqlx.DB
+}
+
+// NewVisitorRepository creates a new visitor repository
+func NewVisitorRepository(db *sqlx.DB) *VisitorRepository {
+	return &VisitorRepository{db: db}
+}
+
+// FindByToken retrieves a visitor by their cookie token
+func (r *VisitorRepository) FindByToken(token string) (*models.Visitor, error) {
+	query := `
+		SELECT id, token, created_at, updated_at
+		FROM visitors
+		WHERE token = $1
+	`
+
+	var visitor models.Visitor
+	if err := r.db.Get(&visitor, query, token); err != nil {
+		return nil, fmt.Errorf("failed to fetch visitor: %w", err)
+	}
+
+	return &visitor, nil
+}
+
+// Create inserts a visitor
+func (r *VisitorRepository) Create(visitor *models.Visitor) error {
+	query := `
+		INSERT INTO visitors (token)
+		VALUES ($1)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, visitor.Token).Scan(&visitor.ID, &visitor.CreatedAt, &visitor.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create visitor: %w", err)
+	}
+
+	return nil
+}
+
+// Touch marks a visitor as active so their lists do not expire
+func (r *VisitorRepository) Touch(visitorID int) error {
+	if _, err := r.db.Exec(`UPDATE visitors SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, visitorID); err != nil {
+		return fmt.Errorf("failed to update visitor: %w", err)
+	}
+	return nil
+}
+
+// DeleteStale removes visitors (with their lists) inactive for longer than maxAge
+func (r *VisitorRepository) DeleteStale(maxAge time.Duration) error {
+	query := `DELETE FROM visitors WHERE updated_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`
+	if _, err := r.db.Exec(query, maxAge.Seconds()); err != nil {
+		return fmt.Errorf("failed to delete stale visitors: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+)
+
+// WishlistRepository handles wishlist and recently viewed data access
+type WishlistRepository struct {
+	db *sqlx.DB
+}
+
+// NewWishlistRepository creates a new wishlist repository
+func NewWishlistRepository(db *sqlx.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// ownerColumn returns the column that owns a shopper's rows and its value. The
+// column name is one of two constants, so it is safe to format into queries.
+func ownerColumn(shopper models.Shopper) (string, int) {
+	if shopper.CustomerID > 0 {
+		return "customer_id", shopper.CustomerID
+	}
+	return "visitor_id", shopper.VisitorID
+}
+
+// FindProductIDs retrieves the product IDs on a shopper's wishlist, newest first
+func (r *WishlistRepository) FindProductIDs(shopper models.Shopper) ([]int, error) {
+	column, id := ownerColumn(shopper)
+	query := fmt.Sprintf(`
+		SELECT product_id
+		FROM wishlist_items
+		WHERE %s = $1
+		ORDER BY created_at DESC, id DESC
+	`, column)
+
+	ids := []int{}
+	if err := r.db.Select(&ids, query, id); err != nil {
+		return nil, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Add puts a product on a shopper's wishlist; adding it again is a no-op
+func (r *WishlistRepository) Add(shopper models.Shopper, productID int) error {
+	column, id := ownerColumn(shopper)
+	query := fmt.Sprintf(`
+		INSERT INTO wishlist_items (%[1]s, product_id)
+		VALUES ($1, $2)
+		ON CONFLICT (%[1]s, product_id) WHERE %[1]s IS NOT NULL DO NOTHING
+	`, column)
+
+	if _, err := r.db.Exec(query, id, productID); err != nil {
+		return fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+	return nil
+}
+
+// Remove takes a product off a shopper's wishlist and reports whether it was on it
+func (r *WishlistRepository) Remove(shopper models.Shopper, productID int) (bool, error) {
+	column, id := ownerColumn(shopper)
+	query := fmt.Sprintf(`DELETE FROM wishlist_items WHERE %s = $1 AND product_id = $2`, column)
+
+	result, err := r.db.Exec(query, id, productID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// FindViewedProductIDs retrieves the products a shopper viewed, most recent first
+func (r *WishlistRepository) FindViewedProductIDs(shopper models.Shopper, limit int) ([]int, error) {
+	column, id := ownerColumn(shopper)
+	query := fmt.Sprintf(`
+		SELECT product_id
+		FROM recently_viewed
+		WHERE %s = $1
+		ORDER BY viewed_at DESC, id DESC
+		LIMIT $2
+	`, column)
+
+	ids := []int{}
+	if err := r.db.Select(&ids, query, id, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch recently viewed products: %w", err)
+	}
+
+	return ids, nil
+}
+
+// RecordView marks a product as just viewed by a shopper within a transaction
+func (r *WishlistRepository) RecordView(tx *sqlx.Tx, shopper models.Shopper, productID int) error {
+	column, id := ownerColumn(shopper)
+	query := fmt.Sprintf(`
+		INSERT INTO recently_viewed (%[1]s, product_id)
+		VALUES ($1, $2)
+		ON CONFLICT (%[1]s, product_id) WHERE %[1]s IS NOT NULL
+		DO UPDATE SET viewed_at = CURRENT_TIMESTAMP
+	`, column)
+
+	if _, err := tx.Exec(query, id, productID); err != nil {
+		return fmt.Errorf("failed to record product view: %w", err)
+	}
+	return nil
+}
+
+// TrimViews keeps only the keep most recent views of a shopper within a transaction
+func (r *WishlistRepository) TrimViews(tx *sqlx.Tx, shopper models.Shopper, keep int) error {
+	column, id := ownerColumn(shopper)
+	query := fmt.Sprintf(`
+		DELETE FROM recently_viewed
+		WHERE %[1]s = $1 AND id NOT IN (
+			SELECT id FROM recently_viewed
+			WHERE %[1]s = $1
+			ORDER BY viewed_at DESC, id DESC
+			LIMIT $2
+		)
+	`, column)
+
+	if _, err := tx.Exec(query, id, keep); err != nil {
+		return fmt.Errorf("failed to trim recently viewed products: %w", err)
+	}
+	return nil
+}
+
+// Merge moves a visitor's wishlist and recently viewed products to a customer
+// within a transaction. Products already on the customer's lists are kept once,
+// with the latest view time.
+func (r *WishlistRepository) Merge(tx *sqlx.Tx, visitorID, customerID int) error {
+	_, err := tx.Exec(`
+		INSERT INTO wishlist_items (customer_id, product_id, created_at)
+		SELECT $2, product_id, created_at FROM wishlist_items WHERE visitor_id = $1
+		ON CONFLICT (customer_id, product_id) WHERE customer_id IS NOT NULL DO NOTHING
+	`, visitorID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to merge wishlist: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM wishlist_items WHERE visitor_id = $1`, visitorID); err != nil {
+		return fmt.Errorf("failed to clear visitor wishlist: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO recently_viewed (customer_id, product_id, viewed_at)
+		SELECT $2, product_id, viewed_at FROM recently_viewed WHERE visitor_id = $1
+		ON CONFLICT (customer_id, product_id) WHERE customer_id IS NOT NULL
+		DO UPDATE SET viewed_at = GREATEST(recently_viewed.viewed_at, EXCLUDED.viewed_at)
+	`, visitorID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to merge recently viewed products: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM recently_viewed WHERE visitor_id = $1`, visitorID); err != nil {
+		return fmt.Errorf("failed to clear visitor recently viewed products: %w", err)
+	}
+
+	return nil
+}
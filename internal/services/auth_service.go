@@ -96,7 +96,7 @@ func (s *AuthService) VerifyToken(tokenString string) (*JWTClaims, error) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(s.jwtSecret), nil
-	})
+	}, jwt.WithIssuer(TokenIssuer)) // Customer tokens share the secret but not the issuer
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/utils"
+)
+
+// BasketLifetime is how long a basket is kept after its last change
+const BasketLifetime = 30 * 24 * time.Hour
+
+// Basket errors shown to shoppers; handlers translate them
+var (
+	ErrBasketProductNotFound = errors.New("product not found")
+	ErrBasketVariantRequired = errors.New("choose a variant first")
+	ErrBasketSoldOut         = errors.New("product is sold out")
+	ErrBasketInvalidQuantity = errors.New("invalid quantity")
+	ErrBasketItemNotFound    = errors.New("basket item not found")
+)
+
+// BasketService handles the visitor inquiry basket and its WhatsApp checkout
+type BasketService struct {
+	basketRepo      *repositories.BasketRepository
+	productService  *ProductService
+	db              *sqlx.DB
+	settingsService *SettingsService
+}
+
+// NewBasketService creates a new basket service
+func NewBasketService(basketRepo *repositories.BasketRepository, productService *ProductService, db *sqlx.DB, settingsService *SettingsService) *BasketService {
+	return &BasketService{
+		basketRepo:      basketRepo,
+		productService:  productService,
+		db:              db,
+		settingsService: settingsService,
+	}
+}
+
+// Get retrieves the basket for a cookie token with priced lines. An unknown or
+// empty token gives an empty basket that is not stored.
+func (s *BasketService) Get(ctx context.Context, token string) (*models.Basket, error) {
+	basket, err := s.find(token)
+	if err != nil || basket == nil {
+		return &models.Basket{Lines: []models.BasketLine{}}, err
+	}
+
+	items, err := s.basketRepo.FindItems(basket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	basket.Lines = []models.BasketLine{}
+	if len(items) == 0 {
+		return basket, nil
+	}
+
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := s.productService.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
+		}
+		line := models.BasketLine{BasketItem: item, Product: product}
+		if item.VariantID != nil {
+			line.Variant = findVariant(product, *item.VariantID)
+			if line.Variant == nil {
+				continue
+			}
+		}
+		basket.Lines = append(basket.Lines, line)
+	}
+
+	return basket, nil
+}
+
+// Add puts quantity units of a product (and variant) in the basket, creating the
+// basket when the token is unknown. It returns the token to keep in the cookie.
+func (s *BasketService) Add(ctx context.Context, token string, productID int, variantID *int, quantity int) (string, error) {
+	if quantity < 1 || quantity > models.MaxBasketQuantity {
+		return token, ErrBasketInvalidQuantity
+	}
+
+	products, err := s.productService.GetByIDs(ctx, []int{productID})
+	if err != nil {
+		return token, err
+	}
+	product, ok := products[productID]
+	if !ok {
+		return token, ErrBasketProductNotFound
+	}
+
+	// Products with variants are ordered per variant; a single variant needs no choice
+	if variantID == nil && len(product.Variants) == 1 {
+		variantID = &product.Variants[0].ID
+	}
+	if variantID == nil && len(product.Variants) > 1 {
+		return token, ErrBasketVariantRequired
+	}
+	if variantID != nil {
+		variant := findVariant(product, *variantID)
+		if variant == nil {
+			return token, ErrBasketProductNotFound
+		}
+		if !variant.InStock() {
+			return token, ErrBasketSoldOut
+		}
+	} else if product.IsSold {
+		return token, ErrBasketSoldOut
+	}
+
+	basket, err := s.find(token)
+	if err != nil {
+		return token, err
+	}
+	if basket == nil {
+		if basket, err = s.create(); err != nil {
+			return token, err
+		}
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return basket.Token, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = s.basketRepo.AddItem(tx, basket.ID, product.ID, variantID, quantity)
+	if err == nil {
+		err = s.basketRepo.Touch(tx, basket.ID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return basket.Token, err
+	}
+
+	return basket.Token, nil
+}
+
+// UpdateQuantity sets the quantity of a basket line; zero removes it
+func (s *BasketService) UpdateQuantity(ctx context.Context, token string, itemID, quantity int) error {
+	if quantity < 0 || quantity > models.MaxBasketQuantity {
+		return ErrBasketInvalidQuantity
+	}
+
+	basket, err := s.find(token)
+	if err != nil {
+		return err
+	}
+	if basket == nil {
+		return ErrBasketItemNotFound
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if quantity == 0 {
+		err = s.basketRepo.DeleteItem(tx, basket.ID, itemID)
+	} else {
+		err = s.basketRepo.UpdateItemQuantity(tx, basket.ID, itemID, quantity)
+	}
+	if err == nil {
+		err = s.basketRepo.Touch(tx, basket.ID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	return err
+}
+
+// Remove deletes a line from the basket
+func (s *BasketService) Remove(ctx context.Context, token string, itemID int) error {
+	return s.UpdateQuantity(ctx, token, itemID, 0)
+}
+
+// Clear empties the basket
+func (s *BasketService) Clear(ctx context.Context, token string) error {
+	basket, err := s.find(token)
+	if err != nil || basket == nil {
+		return err
+	}
+	return s.basketRepo.Clear(basket.ID)
+}
+
+// WhatsAppMessage composes the order inquiry for every basket line with its code,
+// quantity and subtotal, followed by the total and the shopper's details. A signed-in
+// reseller's account is named so the store can check their group prices.
+func (s *BasketService) WhatsAppMessage(ctx context.Context, basket *models.Basket, name, note string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Halo %s, saya ingin memesan:\n", s.settingsService.Get().Name)
+
+	for i := range basket.Lines {
+		line := &basket.Lines[i]
+		fmt.Fprintf(&b, "\n%d. %s\n", i+1, line.Label())
+		fmt.Fprintf(&b, "   Kode: %s\n", line.Product.Code)
+		fmt.Fprintf(&b, "   %d x %s = %s", line.Quantity, utils.FormatRupiah(line.UnitPrice()), utils.FormatRupiah(line.Subtotal()))
+		if line.HasGroupPrice() {
+			b.WriteString(" (harga reseller)")
+		}
+		if !line.Available() {
+			b.WriteString(" (stok habis)")
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\nTotal: %s (%d pcs)\n", utils.FormatRupiah(basket.Total()), basket.ItemCount())
+
+	if name = strings.TrimSpace(name); name != "" {
+		fmt.Fprintf(&b, "\nNama: %s", name)
+	}
+	if customer := customerFrom(ctx); customer != nil {
+		fmt.Fprintf(&b, "\nAkun reseller: %s, %s", customer.DisplayName(), customer.Phone)
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		fmt.Fprintf(&b, "\nCatatan: %s", note)
+	}
+	b.WriteString("\n\nApakah semuanya tersedia?")
+
+	return b.String()
+}
+
+// WhatsAppURL returns the wa.me link that opens a chat with the composed message
+func (s *BasketService) WhatsAppURL(ctx context.Context, basket *models.Basket, name, note string) string {
+	return whatsAppURL(s.settingsService.Get().WhatsAppNumber, s.WhatsAppMessage(ctx, basket, name, note))
+}
+
+// find retrieves the basket for a token, or nil when there is none
+func (s *BasketService) find(token string) (*models.Basket, error) {
+	if token == "" {
+		return nil, nil
+	}
+	basket, err := s.basketRepo.FindByToken(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return basket, err
+}
+
+// create stores a new basket with a random token and drops expired ones
+func (s *BasketService) create() (*models.Basket, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate basket token: %w", err)
+	}
+
+	basket := &models.Basket{Token: hex.EncodeToString(raw)}
+	if err := s.basketRepo.Create(basket); err != nil {
+		return nil, err
+	}
+
+	// Best effort: expired baskets are cleaned up as new ones are made
+	_ = s.basketRepo.DeleteStale(BasketLifetime)
+
+	return basket, nil
+}
+
+// findVariant returns the product's variant with the given ID, or nil
+func findVariant(product *models.Product, variantID int) *models.ProductVariant {
+	for i := range product.Variants {
+		if product.Variants[i].ID == variantID {
+			return &product.Variants[i]
+		}
+	}
+	return nil
+}
+
+// whatsAppURL returns the wa.me link that opens a chat with number prefilled with message
+func whatsAppURL(number, message string) string {
+	text := strings.ReplaceAll(url.QueryEscape(message), "+", "%20")
+	return "https://wa.me/" + number + "?text=" + text
+}
@@ -6,28 +6,79 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
 	"github.com/rizkysr90/aslam-flower/internal/models"
 	"github.com/rizkysr90/aslam-flower/internal/repositories"
 	"github.com/rizkysr90/aslam-flower/internal/utils"
 )
 
+// ErrCategoryCycle is returned when a category would move under itself or its subtree
+var ErrCategoryCycle = errors.New("a category cannot be moved under itself or one of its subcategories")
+
 // CategoryService handles category business logic
 type CategoryService struct {
-	categoryRepo *repositories.CategoryRepository
+	categoryRepo      *repositories.CategoryRepository
+	productService    *ProductService
+	cloudinaryService *CloudinaryService
+	db                *sqlx.DB
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService(categoryRepo *repositories.CategoryRepository) *CategoryService {
+func NewCategoryService(categoryRepo *repositories.CategoryRepository, productService *ProductService, cloudinaryService *CloudinaryService, db *sqlx.DB) *CategoryService {
 	return &CategoryService{
-		categoryRepo: categoryRepo,
+		categoryRepo:      categoryRepo,
+		productService:    productService,
+		cloudinaryService: cloudinaryService,
+		db:                db,
 	}
 }
 
 // GetAll retrieves all categories
 func (s *CategoryService) GetAll(ctx context.Context) ([]models.Category, error) {
-	return s.categoryRepo.FindAll()
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return categories, s.applyTranslations(ctx, categories)
+}
+
+// GetTree retrieves all categories nested under their parents, siblings sorted by name
+func (s *CategoryService) GetTree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildCategoryTree(categories), nil
+}
+
+// GetBreadcrumbs retrieves the categories from the root down to the given category
+func (s *CategoryService) GetBreadcrumbs(ctx context.Context, id int) ([]models.Category, error) {
+	category, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.categoryRepo.FindAncestors(category.Path)
+	if err != nil {
+		return nil, err
+	}
+	return ancestors, s.applyTranslations(ctx, ancestors)
+}
+
+// Suggest lists up to limit categories for search-as-you-type
+func (s *CategoryService) Suggest(ctx context.Context, text string, limit int) ([]models.Category, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []models.Category{}, nil
+	}
+	categories, err := s.categoryRepo.FindByPrefix(text, limit)
+	if err != nil {
+		return nil, err
+	}
+	return categories, s.applyTranslations(ctx, categories)
 }
 
 // GetByID retrieves a category by ID
@@ -38,7 +89,7 @@ func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category
 
 	category, err := s.categoryRepo.FindByID(id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("category not found")
 		}
 		return nil, fmt.Errorf("failed to fetch category: %w", err)
@@ -47,101 +98,222 @@ func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category
 	return category, nil
 }
 
-// Create creates a new category
-func (s *CategoryService) Create(ctx context.Context, name string) (*models.Category, error) {
+// GetBySlug retrieves a category by its current or a previous slug. Callers compare
+// the returned Slug with the requested one to redirect old links.
+func (s *CategoryService) GetBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	category, err := s.categoryRepo.FindBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		category, err = s.categoryRepo.FindBySlugHistory(slug)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("category not found")
+		}
+		return nil, fmt.Errorf("failed to fetch category: %w", err)
+	}
+
+	categories := []models.Category{*category}
+	if err := s.applyTranslations(ctx, categories); err != nil {
+		return nil, err
+	}
+	return &categories[0], nil
+}
+
+// GetTranslations retrieves a category's text in the other storefront languages, for the admin form
+func (s *CategoryService) GetTranslations(ctx context.Context, id int) ([]models.CategoryTranslation, error) {
+	return s.categoryRepo.FindTranslationsByCategory(id)
+}
+
+// Create creates a new category, optionally under a parent category.
+// input carries the name, parent and page content; the slug is generated.
+func (s *CategoryService) Create(ctx context.Context, input *models.Category) (*models.Category, error) {
 	// Validate name
-	name = strings.TrimSpace(name)
+	name := strings.TrimSpace(input.Name)
+	parentID := input.ParentID
 	if name == "" {
 		return nil, errors.New("category name is required")
 	}
-	if len(name) < 3 {
+	if utf8.RuneCountInString(name) < 3 {
 		return nil, errors.New("category name must be at least 3 characters")
 	}
-	if len(name) > 100 {
+	if utf8.RuneCountInString(name) > 100 {
 		return nil, errors.New("category name must be at most 100 characters")
 	}
 
+	var parent *models.Category
+	if parentID != nil {
+		var err error
+		if parent, err = s.GetByID(ctx, *parentID); err != nil {
+			return nil, errors.New("parent category not found")
+		}
+	}
+
 	// Generate slug
-	slug := utils.GenerateSlug(name)
-	if slug == "" {
-		return nil, errors.New("invalid category name: cannot generate slug")
+	slug, err := s.generateSlug(name, parent, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateContent(input); err != nil {
+		return nil, err
 	}
 
 	// Create category
 	category := &models.Category{
-		Name: name,
-		Slug: slug,
+		Name:        name,
+		Slug:        slug,
+		ParentID:    parentID,
+		Title:       input.Title,
+		Description: input.Description,
+		BannerURL:   input.BannerURL,
+		BannerID:    input.BannerID,
 	}
 
-	err := s.categoryRepo.Create(category)
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = s.categoryRepo.Create(tx, category)
+	if err == nil && len(input.Translations) > 0 {
+		err = s.categoryRepo.SyncTranslations(tx, category.ID, input.Translations)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
-		// Check for unique constraint violation
-		if pqErr, ok := err.(*pq.Error); ok {
-			if pqErr.Code == "23505" { // unique_violation
-				return nil, errors.New("category name already exists")
-			}
+		// Rollback: delete the uploaded banner
+		if category.BannerID != "" {
+			_ = s.cloudinaryService.DeleteImage(ctx, category.BannerID)
+		}
+		if uniqueErr := categoryUniqueError(err); uniqueErr != nil {
+			return nil, uniqueErr
 		}
 		return nil, fmt.Errorf("failed to create category: %w", err)
 	}
+	category.Translations = input.Translations
 
 	return category, nil
 }
 
-// Update updates an existing category
-func (s *CategoryService) Update(ctx context.Context, id int, name string) (*models.Category, error) {
+// Update updates an existing category; changing ParentID moves it with its whole subtree.
+// A renamed category keeps its old slug in the history so shared links redirect.
+func (s *CategoryService) Update(ctx context.Context, id int, input *models.Category) (*models.Category, error) {
 	// Validate ID
 	if id <= 0 {
 		return nil, errors.New("invalid category ID")
 	}
 
 	// Validate name
-	name = strings.TrimSpace(name)
+	name := strings.TrimSpace(input.Name)
+	parentID := input.ParentID
 	if name == "" {
 		return nil, errors.New("category name is required")
 	}
-	if len(name) < 3 {
+	if utf8.RuneCountInString(name) < 3 {
 		return nil, errors.New("category name must be at least 3 characters")
 	}
-	if len(name) > 100 {
+	if utf8.RuneCountInString(name) > 100 {
 		return nil, errors.New("category name must be at most 100 characters")
 	}
 
 	// Get existing category
 	existing, err := s.categoryRepo.FindByID(id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("category not found")
 		}
 		return nil, fmt.Errorf("failed to fetch category: %w", err)
 	}
 
-	// Generate new slug if name changed
+	// A category cannot move under itself or anything in its own subtree
+	var parent *models.Category
+	if parentID != nil {
+		parent, err = s.GetByID(ctx, *parentID)
+		if err != nil {
+			return nil, errors.New("parent category not found")
+		}
+		if parent.ID == id || parent.IsDescendantOf(existing) {
+			return nil, ErrCategoryCycle
+		}
+	}
+
+	// Generate new slug if name or parent changed
 	slug := existing.Slug
-	if name != existing.Name {
-		slug = utils.GenerateSlug(name)
-		if slug == "" {
-			return nil, errors.New("invalid category name: cannot generate slug")
+	if name != existing.Name || !sameParent(parentID, existing.ParentID) {
+		slug, err = s.generateSlug(name, parent, id)
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	if err := s.validateContent(input); err != nil {
+		return nil, err
+	}
+
 	// Update category
 	category := &models.Category{
-		ID:   id,
-		Name: name,
-		Slug: slug,
+		ID:          id,
+		Name:        name,
+		Slug:        slug,
+		ParentID:    parentID,
+		Title:       input.Title,
+		Description: input.Description,
+		BannerURL:   input.BannerURL,
+		BannerID:    input.BannerID,
 	}
 
-	err = s.categoryRepo.Update(category)
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Re-check the move under the tree lock: the check above may already be stale
+	if parentID != nil && !sameParent(parentID, existing.ParentID) {
+		var cycle bool
+		cycle, err = s.categoryRepo.LockForMove(tx, id, *parentID)
+		if err == nil && cycle {
+			err = ErrCategoryCycle
+		}
+	}
+	if err == nil {
+		err = s.categoryRepo.Update(tx, category)
+	}
+	if err == nil && slug != existing.Slug {
+		err = s.categoryRepo.RecordSlugChange(tx, id, existing.Slug, slug)
+	}
+	if err == nil {
+		err = s.categoryRepo.SyncTranslations(tx, id, input.Translations)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
-		// Check for unique constraint violation
-		if pqErr, ok := err.(*pq.Error); ok {
-			if pqErr.Code == "23505" { // unique_violation
-				return nil, errors.New("category name already exists")
-			}
+		// Rollback: delete a newly uploaded banner
+		if category.BannerID != "" && category.BannerID != existing.BannerID {
+			_ = s.cloudinaryService.DeleteImage(ctx, category.BannerID)
+		}
+		if errors.Is(err, ErrCategoryCycle) {
+			return nil, err
+		}
+		if uniqueErr := categoryUniqueError(err); uniqueErr != nil {
+			return nil, uniqueErr
 		}
 		return nil, fmt.Errorf("failed to update category: %w", err)
 	}
 
+	// Replaced banner: remove the old asset (best effort)
+	if existing.BannerID != "" && existing.BannerID != category.BannerID {
+		_ = s.cloudinaryService.DeleteImage(ctx, existing.BannerID)
+	}
+
+	// Category promotions reach a moved subtree under its new ancestors
+	if !sameParent(parentID, existing.ParentID) {
+		s.productService.RefreshPrices()
+	}
+
 	// Fetch updated category
 	updated, err := s.categoryRepo.FindByID(id)
 	if err != nil {
@@ -159,9 +331,9 @@ func (s *CategoryService) Delete(ctx context.Context, id int) error {
 	}
 
 	// Check if category exists
-	_, err := s.categoryRepo.FindByID(id)
+	category, err := s.categoryRepo.FindByID(id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			return errors.New("category not found")
 		}
 		return fmt.Errorf("failed to fetch category: %w", err)
@@ -177,11 +349,131 @@ func (s *CategoryService) Delete(ctx context.Context, id int) error {
 		return fmt.Errorf("cannot delete category with %d products", count)
 	}
 
+	// Check if category has subcategories
+	children, err := s.categoryRepo.CountChildren(id)
+	if err != nil {
+		return fmt.Errorf("failed to count subcategories: %w", err)
+	}
+
+	if children > 0 {
+		return fmt.Errorf("cannot delete category with %d subcategories", children)
+	}
+
 	// Delete category
 	err = s.categoryRepo.Delete(id)
 	if err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
 
+	// Delete banner from Cloudinary (best effort)
+	if category.BannerID != "" {
+		_ = s.cloudinaryService.DeleteImage(ctx, category.BannerID)
+	}
+
+	return nil
+}
+
+// validateContent trims and validates the category page content
+func (s *CategoryService) validateContent(input *models.Category) error {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Description = strings.TrimSpace(input.Description)
+	if utf8.RuneCountInString(input.Title) > 200 {
+		return errors.New("page title must be at most 200 characters")
+	}
+	if utf8.RuneCountInString(input.Description) > 5000 {
+		return errors.New("description must be at most 5000 characters")
+	}
+	if (input.BannerURL == "") != (input.BannerID == "") {
+		return errors.New("banner needs both URL and public ID")
+	}
+
+	for i := range input.Translations {
+		translation := &input.Translations[i]
+		translation.Name = strings.TrimSpace(translation.Name)
+		translation.Title = strings.TrimSpace(translation.Title)
+		translation.Description = strings.TrimSpace(translation.Description)
+		if utf8.RuneCountInString(translation.Name) > 100 {
+			return errors.New("translated name must be at most 100 characters")
+		}
+		if utf8.RuneCountInString(translation.Title) > 200 {
+			return errors.New("translated page title must be at most 200 characters")
+		}
+		if utf8.RuneCountInString(translation.Description) > 5000 {
+			return errors.New("translated description must be at most 5000 characters")
+		}
+	}
+	return nil
+}
+
+// generateSlug builds the slug for a category named name under parent. Category
+// pages share one flat URL space, so a subcategory whose plain slug is taken by
+// another category ("kertas-korea") gets its parent's slug in front.
+func (s *CategoryService) generateSlug(name string, parent *models.Category, categoryID int) (string, error) {
+	slug := utils.GenerateSlug(name)
+	if slug == "" {
+		return "", errors.New("invalid category name: cannot generate slug")
+	}
+	if parent == nil {
+		return slug, nil
+	}
+
+	taken, err := s.categoryRepo.SlugTaken(slug, categoryID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		slug = parent.Slug + "-" + slug
+	}
+	return slug, nil
+}
+
+// categoryUniqueError explains a unique violation when saving a category, or returns nil
+func categoryUniqueError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" { // unique_violation
+		return nil
+	}
+	if pqErr.Constraint == "categories_slug_key" {
+		return errors.New("another category already uses this URL; choose a different name")
+	}
+	return errors.New("category name already exists under this parent")
+}
+
+// sameParent reports whether two optional parent IDs point at the same parent
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// applyTranslations replaces the name and page content of categories with their
+// translation into the storefront language in ctx, where one was entered
+func (s *CategoryService) applyTranslations(ctx context.Context, categories []models.Category) error {
+	locale := i18n.FromContext(ctx)
+	if locale == i18n.Default || len(categories) == 0 {
+		return nil
+	}
+
+	translations, err := s.categoryRepo.FindTranslations(string(locale))
+	if err != nil {
+		return err
+	}
+
+	for i := range categories {
+		translation, ok := translations[categories[i].ID]
+		if !ok {
+			continue
+		}
+		if translation.Name != "" {
+			categories[i].Name = translation.Name
+		}
+		if translation.Title != "" {
+			categories[i].Title = translation.Title
+		}
+		if translation.Description != "" {
+			categories[i].Description = translation.Description
+		}
+	}
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2"
@@ -24,10 +25,19 @@ const (
 
 	folderProducts = "flower-supply/products"
 	folderVariants = "flower-supply/variants"
+	folderGallery  = "flower-supply/gallery"
+	folderCategory = "flower-supply/categories"
+	folderReviews  = "flower-supply/reviews"
 
 	// Direct-upload transformation strings (must match server UploadProductImage / UploadVariantImage)
 	transformationProduct = "c_limit,w_1200,h_1200,q_auto,f_auto"
 	transformationVariant = "c_limit,w_800,h_800,q_auto,f_auto"
+	transformationGallery = "c_limit,w_1600,h_1600,q_auto,f_auto"
+	transformationBanner  = "c_limit,w_1920,h_800,q_auto,f_auto"
+	transformationReview  = "c_limit,w_1200,h_1200,q_auto,f_auto"
+
+	// reviewAllowedFormats limits visitor uploads to photos
+	reviewAllowedFormats = "jpg,png,webp"
 )
 
 // ClientDirectUploadParams is returned to the browser for signed direct upload to Cloudinary.
@@ -40,6 +50,7 @@ type ClientDirectUploadParams struct {
 	Folder         string `json:"folder"`
 	PublicID       string `json:"publicId"`
 	Transformation string `json:"transformation"`
+	AllowedFormats string `json:"allowedFormats,omitempty"` // Signed too; must be sent as allowed_formats
 }
 
 // CloudinaryService handles Cloudinary image operations
@@ -254,13 +265,9 @@ func (s *CloudinaryService) DeleteImage(ctx context.Context, publicID string) er
 }
 
 // GenerateClientDirectUpload builds signed parameters for browser → Cloudinary direct upload.
-// kind must be "main" (product image) or "variant".
+// kind must be "main" (product image), "variant", "gallery" (additional product images)
+// or "category" (category page banner).
 func (s *CloudinaryService) GenerateClientDirectUpload(kind string) (*ClientDirectUploadParams, error) {
-	cloud := s.cld.Config.Cloud
-	if cloud.APISecret == "" || cloud.APIKey == "" {
-		return nil, errors.New("cloudinary API credentials not configured")
-	}
-
 	var folder, publicID, transform string
 	switch kind {
 	case "main":
@@ -271,14 +278,44 @@ func (s *CloudinaryService) GenerateClientDirectUpload(kind string) (*ClientDire
 		folder = folderVariants
 		publicID = "v_" + strings.ReplaceAll(uuid.New().String(), "-", "")
 		transform = transformationVariant
+	case "gallery":
+		folder = folderGallery
+		publicID = "g_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+		transform = transformationGallery
+	case "category":
+		folder = folderCategory
+		publicID = "c_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+		transform = transformationBanner
 	default:
-		return nil, fmt.Errorf("invalid upload kind: %q (use main or variant)", kind)
+		return nil, fmt.Errorf("invalid upload kind: %q (use main, variant, gallery or category)", kind)
+	}
+
+	return s.signDirectUpload(folder, publicID, transform, "")
+}
+
+// GenerateReviewUpload builds signed parameters for a photo attached to a review on
+// the public site. It is the restricted variant of GenerateClientDirectUpload for
+// visitors: folder, public ID and transformation are fixed and only photo formats
+// are accepted, so the signature cannot place anything among the catalog images.
+func (s *CloudinaryService) GenerateReviewUpload() (*ClientDirectUploadParams, error) {
+	publicID := "r_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	return s.signDirectUpload(folderReviews, publicID, transformationReview, reviewAllowedFormats)
+}
+
+// signDirectUpload signs the upload parameters the browser sends to Cloudinary
+func (s *CloudinaryService) signDirectUpload(folder, publicID, transform, allowedFormats string) (*ClientDirectUploadParams, error) {
+	cloud := s.cld.Config.Cloud
+	if cloud.APISecret == "" || cloud.APIKey == "" {
+		return nil, errors.New("cloudinary API credentials not configured")
 	}
 
 	params := url.Values{}
 	params.Set("folder", folder)
 	params.Set("public_id", publicID)
 	params.Set("transformation", transform)
+	if allowedFormats != "" {
+		params.Set("allowed_formats", allowedFormats)
+	}
 
 	signatureHex, err := api.SignParameters(params, cloud.APISecret)
 	if err != nil {
@@ -295,10 +332,13 @@ func (s *CloudinaryService) GenerateClientDirectUpload(kind string) (*ClientDire
 		Folder:         folder,
 		PublicID:       publicID,
 		Transformation: transform,
+		AllowedFormats: allowedFormats,
 	}, nil
 }
 
-// ValidateClientUploadResult checks that URLs and public IDs from a direct upload belong to this account and folder.
+// ValidateClientUploadResult checks that URLs and public IDs from a direct upload belong to
+// this account and folder, and to each other, so a client cannot pair an allowed public ID
+// with the URL of any other image in the account.
 func (s *CloudinaryService) ValidateClientUploadResult(kind, secureURL, publicID string) error {
 	if secureURL == "" || publicID == "" {
 		return errors.New("missing image URL or public ID")
@@ -307,6 +347,9 @@ func (s *CloudinaryService) ValidateClientUploadResult(kind, secureURL, publicID
 	if !strings.HasPrefix(secureURL, prefix) {
 		return errors.New("image URL does not belong to this Cloudinary account")
 	}
+	if urlPublicID, ok := uploadedPublicID(strings.TrimPrefix(secureURL, prefix)); !ok || urlPublicID != publicID {
+		return errors.New("image URL does not match its public ID")
+	}
 	switch kind {
 	case "main":
 		if !strings.HasPrefix(publicID, folderProducts+"/") {
@@ -316,12 +359,44 @@ func (s *CloudinaryService) ValidateClientUploadResult(kind, secureURL, publicID
 		if !strings.HasPrefix(publicID, folderVariants+"/") {
 			return errors.New("invalid variant image public ID")
 		}
+	case "gallery":
+		if !strings.HasPrefix(publicID, folderGallery+"/") {
+			return errors.New("invalid gallery image public ID")
+		}
+	case "category":
+		if !strings.HasPrefix(publicID, folderCategory+"/") {
+			return errors.New("invalid category banner public ID")
+		}
+	case "review":
+		if !strings.HasPrefix(publicID, folderReviews+"/") {
+			return errors.New("invalid review photo public ID")
+		}
 	default:
 		return fmt.Errorf("invalid kind %q", kind)
 	}
 	return nil
 }
 
+// uploadedPublicID extracts the public ID from the path of an uploaded image's URL after
+// the cloud name: "image/upload/v1712345678/flower-supply/reviews/abc.jpg" gives
+// "flower-supply/reviews/abc"
+func uploadedPublicID(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "image/upload/")
+	if !ok {
+		return "", false
+	}
+	if version, after, found := strings.Cut(rest, "/"); found && len(version) > 1 && version[0] == 'v' {
+		if _, err := strconv.ParseUint(version[1:], 10, 64); err == nil {
+			rest = after
+		}
+	}
+
+	if dot := strings.LastIndex(rest, "."); dot > strings.LastIndex(rest, "/") {
+		rest = rest[:dot]
+	}
+	return rest, rest != ""
+}
+
 // validateFileContent validates file type from file data
 // Checks file type using magic numbers (not just extension)
 func (s *CloudinaryService) validateFileContent(fileData []byte) error {
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// colorHexPattern matches a lowercase "#rrggbb" hex code
+var colorHexPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// ColorService handles palette colour business logic
+type ColorService struct {
+	colorRepo *repositories.ColorRepository
+	db        *sqlx.DB
+}
+
+// NewColorService creates a new color service
+func NewColorService(colorRepo *repositories.ColorRepository, db *sqlx.DB) *ColorService {
+	return &ColorService{
+		colorRepo: colorRepo,
+		db:        db,
+	}
+}
+
+// GetAll retrieves the palette with the number of variants using each colour
+func (s *ColorService) GetAll(ctx context.Context) ([]models.Color, error) {
+	return s.colorRepo.FindAll()
+}
+
+// GetByID retrieves a palette colour by ID
+func (s *ColorService) GetByID(ctx context.Context, id int) (*models.Color, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid color ID")
+	}
+
+	color, err := s.colorRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("color not found")
+		}
+		return nil, fmt.Errorf("failed to fetch color: %w", err)
+	}
+
+	return color, nil
+}
+
+// Create validates and inserts a palette colour
+func (s *ColorService) Create(ctx context.Context, color *models.Color) error {
+	if err := s.validateColor(color); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Existing variants labelled with the new name or an alias pick it up
+	err = s.colorRepo.Create(tx, color)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return errors.New("color name already exists")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Update validates and updates a palette colour
+func (s *ColorService) Update(ctx context.Context, id int, color *models.Color) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	color.ID = id
+
+	if err := s.validateColor(color); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = s.colorRepo.Update(tx, color)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return errors.New("color name already exists")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Merge folds a duplicate colour into another: its variants move to the target
+// and its name becomes one of the target's aliases, so future labels still match
+func (s *ColorService) Merge(ctx context.Context, sourceID, targetID int) (*models.Color, error) {
+	if sourceID == targetID {
+		return nil, errors.New("choose two different colors to merge")
+	}
+	if _, err := s.GetByID(ctx, sourceID); err != nil {
+		return nil, err
+	}
+	target, err := s.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.colorRepo.Merge(tx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return target, nil
+}
+
+// Delete removes a palette colour; variants using it keep their labels
+func (s *ColorService) Delete(ctx context.Context, id int) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.colorRepo.Delete(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// validateColor trims and normalises a palette colour before it is saved
+func (s *ColorService) validateColor(color *models.Color) error {
+	color.Name = strings.TrimSpace(color.Name)
+	if utf8.RuneCountInString(color.Name) < 2 {
+		return errors.New("color name must be at least 2 characters")
+	}
+	if utf8.RuneCountInString(color.Name) > 50 {
+		return errors.New("color name must be at most 50 characters")
+	}
+
+	color.Hex = strings.ToLower(strings.TrimSpace(color.Hex))
+	if !strings.HasPrefix(color.Hex, "#") {
+		color.Hex = "#" + color.Hex
+	}
+	if !colorHexPattern.MatchString(color.Hex) {
+		return errors.New("hex code must look like #a1b2c3")
+	}
+
+	if _, ok := models.FindColorFamily(color.Family); !ok {
+		return errors.New("invalid color family")
+	}
+
+	// Aliases are matched lowercase; the name itself never needs to be an alias
+	seen := map[string]bool{strings.ToLower(color.Name): true}
+	aliases := pq.StringArray{}
+	for _, alias := range color.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if alias == "" || seen[alias] {
+			continue
+		}
+		if utf8.RuneCountInString(alias) > 50 {
+			return fmt.Errorf("alias %q must be at most 50 characters", alias)
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	color.Aliases = aliases
+
+	return nil
+}
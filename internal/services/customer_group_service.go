@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// CustomerGroupService handles customer groups and their prices
+type CustomerGroupService struct {
+	groupRepo      *repositories.CustomerGroupRepository
+	productService *ProductService
+	db             *sqlx.DB
+}
+
+// NewCustomerGroupService creates a new customer group service
+func NewCustomerGroupService(groupRepo *repositories.CustomerGroupRepository, productService *ProductService, db *sqlx.DB) *CustomerGroupService {
+	return &CustomerGroupService{
+		groupRepo:      groupRepo,
+		productService: productService,
+		db:             db,
+	}
+}
+
+// GetAll retrieves every customer group with its number of customers
+func (s *CustomerGroupService) GetAll(ctx context.Context) ([]models.CustomerGroup, error) {
+	return s.groupRepo.FindAll()
+}
+
+// GetByID retrieves a customer group with its explicit prices
+func (s *CustomerGroupService) GetByID(ctx context.Context, id int) (*models.CustomerGroup, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid customer group ID")
+	}
+
+	group, err := s.groupRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("customer group not found")
+		}
+		return nil, fmt.Errorf("failed to fetch customer group: %w", err)
+	}
+
+	if group.Prices, err = s.groupRepo.FindPriceList(id); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// Create validates and inserts a customer group with its prices
+func (s *CustomerGroupService) Create(ctx context.Context, group *models.CustomerGroup) error {
+	if err := s.validateGroup(group); err != nil {
+		return err
+	}
+
+	return s.save(group, s.groupRepo.Create)
+}
+
+// Update validates and updates a customer group, replacing its prices
+func (s *CustomerGroupService) Update(ctx context.Context, id int, group *models.CustomerGroup) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	group.ID = id
+
+	if err := s.validateGroup(group); err != nil {
+		return err
+	}
+
+	return s.save(group, s.groupRepo.Update)
+}
+
+// Delete removes a customer group. Its customers keep their accounts and see
+// public prices until they are moved to another group.
+func (s *CustomerGroupService) Delete(ctx context.Context, id int) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.groupRepo.Delete(id)
+}
+
+// save writes the group row with write and replaces its prices in one transaction
+func (s *CustomerGroupService) save(group *models.CustomerGroup, write func(*sqlx.Tx, *models.CustomerGroup) error) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = write(tx, group)
+	if err == nil {
+		err = s.groupRepo.ReplacePrices(tx, group.ID, group.Prices)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "23505": // unique_violation
+				return errors.New("customer group name already exists")
+			case "23503": // foreign_key_violation
+				return errors.New("product variant not found")
+			}
+		}
+		return err
+	}
+
+	// Price filters and sorting follow the group's new prices
+	s.productService.RefreshPrices()
+	return nil
+}
+
+// validateGroup validates customer group fields and prices
+func (s *CustomerGroupService) validateGroup(group *models.CustomerGroup) error {
+	group.Name = strings.TrimSpace(group.Name)
+	group.Description = strings.TrimSpace(group.Description)
+
+	nameLength := utf8.RuneCountInString(group.Name)
+	if nameLength < 2 || nameLength > 100 {
+		return errors.New("group name must be between 2 and 100 characters")
+	}
+	if group.DiscountPercent < 0 || group.DiscountPercent > 100 {
+		return errors.New("discount must be between 0 and 100 percent")
+	}
+
+	seen := make(map[int]bool, len(group.Prices))
+	for _, price := range group.Prices {
+		if price.VariantID <= 0 {
+			return errors.New("invalid product variant in price list")
+		}
+		if seen[price.VariantID] {
+			return errors.New("each variant can only have one price")
+		}
+		seen[price.VariantID] = true
+		if price.UnitPrice < 0 {
+			return errors.New("price cannot be negative")
+		}
+	}
+
+	return nil
+}
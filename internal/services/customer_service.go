@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+const (
+	// CustomerTokenExpiry is how long a reseller stays signed in
+	CustomerTokenExpiry = 30 * 24 * time.Hour
+	// CustomerTokenIssuer tells customer tokens apart from admin tokens signed with the same secret
+	CustomerTokenIssuer = "flower-catalog-customer"
+	// minCustomerPassword is the shortest password accepted on registration
+	minCustomerPassword = 8
+)
+
+// Customer account errors shown to visitors; handlers translate them
+var (
+	ErrCustomerInvalidName        = errors.New("name must be between 2 and 100 characters")
+	ErrCustomerDetailsTooLong     = errors.New("business name and city must not exceed 100 characters")
+	ErrCustomerInvalidPhone       = errors.New("invalid phone number")
+	ErrCustomerWeakPassword       = errors.New("password is too short")
+	ErrCustomerPhoneTaken         = errors.New("phone number already registered")
+	ErrCustomerInvalidCredentials = errors.New("invalid credentials")
+	ErrCustomerNotApproved        = errors.New("account is not approved")
+	ErrCustomerSessionInvalid     = errors.New("customer session is invalid")
+)
+
+// CustomerClaims represents customer JWT token claims
+type CustomerClaims struct {
+	CustomerID int `json:"customer_id"`
+	jwt.RegisteredClaims
+}
+
+// CustomerRegistration is a reseller application submitted on the public site
+type CustomerRegistration struct {
+	Name         string
+	BusinessName string
+	Phone        string
+	City         string
+	Password     string
+}
+
+// CustomerReview is an admin decision on a customer account
+type CustomerReview struct {
+	Status     models.CustomerStatus
+	GroupID    *int
+	AdminNotes string
+}
+
+// customerKey is the request context key of the signed-in customer
+type customerKey struct{}
+
+// WithCustomer returns a context for a signed-in customer: catalog reads are priced
+// for their group and WhatsApp messages name their account
+func WithCustomer(ctx context.Context, customer *models.Customer) context.Context {
+	return context.WithValue(ctx, customerKey{}, customer)
+}
+
+// customerFrom returns the signed-in customer carried by the context, or nil
+func customerFrom(ctx context.Context) *models.Customer {
+	if ctx == nil {
+		return nil
+	}
+	customer, _ := ctx.Value(customerKey{}).(*models.Customer)
+	return customer
+}
+
+// customerGroupFrom returns the customer group the context is priced for, or nil
+func customerGroupFrom(ctx context.Context) *models.CustomerGroup {
+	if customer := customerFrom(ctx); customer != nil {
+		return customer.Group
+	}
+	return nil
+}
+
+// CustomerService handles reseller accounts, their sign-in and admin review
+type CustomerService struct {
+	customerRepo *repositories.CustomerRepository
+	groupRepo    *repositories.CustomerGroupRepository
+	authService  *AuthService
+	jwtSecret    string
+}
+
+// NewCustomerService creates a new customer service
+func NewCustomerService(customerRepo *repositories.CustomerRepository, groupRepo *repositories.CustomerGroupRepository, authService *AuthService, jwtSecret string) *CustomerService {
+	return &CustomerService{
+		customerRepo: customerRepo,
+		groupRepo:    groupRepo,
+		authService:  authService,
+		jwtSecret:    jwtSecret,
+	}
+}
+
+// Register stores a reseller application; the account waits for admin approval
+func (s *CustomerService) Register(ctx context.Context, input CustomerRegistration) (*models.Customer, error) {
+	customer := &models.Customer{
+		Name:         strings.TrimSpace(input.Name),
+		BusinessName: strings.TrimSpace(input.BusinessName),
+		Phone:        normalizePhone(input.Phone),
+		City:         strings.TrimSpace(input.City),
+		Status:       models.CustomerPending,
+	}
+
+	nameLength := utf8.RuneCountInString(customer.Name)
+	if nameLength < 2 || nameLength > 100 {
+		return nil, ErrCustomerInvalidName
+	}
+	if utf8.RuneCountInString(customer.BusinessName) > 100 || utf8.RuneCountInString(customer.City) > 100 {
+		return nil, ErrCustomerDetailsTooLong
+	}
+	if len(customer.Phone) < 10 || len(customer.Phone) > 15 {
+		return nil, ErrCustomerInvalidPhone
+	}
+	if utf8.RuneCountInString(input.Password) < minCustomerPassword {
+		return nil, ErrCustomerWeakPassword
+	}
+
+	hash, err := s.authService.HashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+	customer.PasswordHash = hash
+
+	if err := s.customerRepo.Create(customer); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return nil, ErrCustomerPhoneTaken
+		}
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// Login checks a customer's phone number and password and returns the customer
+// with a signed token. Only approved accounts may sign in.
+func (s *CustomerService) Login(ctx context.Context, phone, password string) (*models.Customer, string, error) {
+	customer, err := s.customerRepo.FindByPhone(normalizePhone(phone))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", ErrCustomerInvalidCredentials
+		}
+		return nil, "", err
+	}
+
+	if !s.authService.VerifyPassword(password, customer.PasswordHash) {
+		return nil, "", ErrCustomerInvalidCredentials
+	}
+	if customer.Status != models.CustomerApproved {
+		return nil, "", ErrCustomerNotApproved
+	}
+
+	token, err := s.generateToken(customer.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return customer, token, nil
+}
+
+// Authenticate returns the approved customer a token belongs to, with their group.
+// It returns ErrCustomerSessionInvalid for bad tokens and for accounts deleted or
+// no longer approved since the token was issued.
+func (s *CustomerService) Authenticate(ctx context.Context, tokenString string) (*models.Customer, error) {
+	claims, err := s.verifyToken(tokenString)
+	if err != nil {
+		return nil, ErrCustomerSessionInvalid
+	}
+
+	customer, err := s.customerRepo.FindByID(claims.CustomerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomerSessionInvalid
+		}
+		return nil, err
+	}
+	if customer.Status != models.CustomerApproved {
+		return nil, ErrCustomerSessionInvalid
+	}
+
+	if customer.GroupID != nil {
+		if customer.Group, err = s.groupRepo.FindByID(*customer.GroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	return customer, nil
+}
+
+// GetAll retrieves customers with filtering and pagination
+func (s *CustomerService) GetAll(ctx context.Context, filters repositories.CustomerFilters) (*repositories.CustomerListResult, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 20
+	}
+	if filters.PageSize > 100 {
+		filters.PageSize = 100
+	}
+	if filters.Status != "" && !models.CustomerStatus(filters.Status).IsValid() {
+		filters.Status = ""
+	}
+
+	return s.customerRepo.FindAll(filters)
+}
+
+// CountByStatus returns the number of customers in each status
+func (s *CustomerService) CountByStatus(ctx context.Context) (map[models.CustomerStatus]int, error) {
+	return s.customerRepo.CountByStatus()
+}
+
+// GetByID retrieves a customer by ID
+func (s *CustomerService) GetByID(ctx context.Context, id int) (*models.Customer, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid customer ID")
+	}
+
+	customer, err := s.customerRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, fmt.Errorf("failed to fetch customer: %w", err)
+	}
+
+	return customer, nil
+}
+
+// Review saves an admin decision on an account. Approved accounts need a group,
+// since the group is what gives them their prices.
+func (s *CustomerService) Review(ctx context.Context, id int, review CustomerReview) (*models.Customer, error) {
+	customer, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !review.Status.IsValid() {
+		return nil, errors.New("invalid customer status")
+	}
+	if review.GroupID != nil {
+		if _, err := s.groupRepo.FindByID(*review.GroupID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errors.New("customer group not found")
+			}
+			return nil, err
+		}
+	}
+	if review.Status == models.CustomerApproved && review.GroupID == nil {
+		return nil, errors.New("choose a customer group before approving")
+	}
+
+	customer.Status = review.Status
+	customer.GroupID = review.GroupID
+	customer.AdminNotes = strings.TrimSpace(review.AdminNotes)
+	if customer.Status == models.CustomerApproved && customer.ApprovedAt == nil {
+		now := time.Now()
+		customer.ApprovedAt = &now
+	}
+
+	if err := s.customerRepo.UpdateReview(customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// generateToken signs a customer token
+func (s *CustomerService) generateToken(customerID int) (string, error) {
+	now := time.Now()
+	claims := CustomerClaims{
+		CustomerID: customerID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(CustomerTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    CustomerTokenIssuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// verifyToken verifies and parses a customer token
+func (s *CustomerService) verifyToken(tokenString string) (*CustomerClaims, error) {
+	if tokenString == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &CustomerClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.jwtSecret), nil
+	}, jwt.WithIssuer(CustomerTokenIssuer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*CustomerClaims)
+	if !ok || !token.Valid || claims.CustomerID <= 0 {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// normalizePhone keeps the digits of a phone number in international form, so
+// "0812-3456-789" and "+62 812 3456 789" both become "628123456789"
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+
+	digits := b.String()
+	switch {
+	case strings.HasPrefix(digits, "0"):
+		digits = "62" + digits[1:]
+	case strings.HasPrefix(digits, "8"):
+		digits = "62" + digits
+	}
+	return digits
+}
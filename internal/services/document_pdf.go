@@ -0,0 +1,153 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/utils"
+)
+
+// Page layout of printed documents, in points
+const (
+	pdfMargin     = 40.0
+	pdfRowHeight  = 18.0
+	pdfBottomEdge = utils.PDFPageHeight - 60
+)
+
+// Right edges of the item table's columns
+const (
+	pdfColNo       = pdfMargin + 18
+	pdfColProduct  = pdfMargin + 26
+	pdfColQty      = 330.0
+	pdfColPrice    = 415.0
+	pdfColDiscount = 465.0
+	pdfColTotal    = utils.PDFPageWidth - pdfMargin
+)
+
+var (
+	pdfRegular = utils.PDFFont{Size: 9}
+	pdfBold    = utils.PDFFont{Size: 9, Bold: true}
+	pdfMuted   = utils.PDFFont{Size: 9, Gray: 0.4}
+)
+
+// RenderPDF lays out an issued document as an A4 PDF with the same content as
+// its print view
+func (s *DocumentService) RenderPDF(doc *models.Document) []byte {
+	pdf := utils.NewPDF()
+	pdf.AddPage()
+	right := utils.PDFPageWidth - pdfMargin
+
+	// Store details on the left, document title and number on the right
+	pdf.Text(pdfMargin, 56, doc.StoreName, utils.PDFFont{Size: 16, Bold: true})
+	y := 72.0
+	for _, line := range wrapLines(doc.StoreAddress, pdfMuted, 280) {
+		pdf.Text(pdfMargin, y, line, pdfMuted)
+		y += 12
+	}
+
+	pdf.TextRight(right, 56, strings.ToUpper(doc.Type.Label()), utils.PDFFont{Size: 16, Bold: true, Gray: 0.25})
+	pdf.TextRight(right, 72, doc.Number, utils.PDFFont{Size: 10, Bold: true})
+	pdf.TextRight(right, 86, "Tanggal: "+doc.IssuedLocal().Format("02 Jan 2006"), pdfRegular)
+	if doc.DueDate != nil {
+		pdf.TextRight(right, 98, doc.Type.DueDateLabel()+": "+doc.DueDate.Format("02 Jan 2006"), pdfRegular)
+	}
+
+	y = max(y, 110) + 8
+	pdf.Line(pdfMargin, y, right, y, 0.75)
+
+	// Customer
+	y += 20
+	pdf.Text(pdfMargin, y, "Kepada", pdfMuted)
+	y += 14
+	pdf.Text(pdfMargin, y, doc.CustomerName, utils.PDFFont{Size: 11, Bold: true})
+	if doc.CustomerPhone != "" {
+		y += 13
+		pdf.Text(pdfMargin, y, doc.CustomerPhone, pdfRegular)
+	}
+	for _, line := range wrapLines(doc.CustomerAddress, pdfRegular, 280) {
+		y += 12
+		pdf.Text(pdfMargin, y, line, pdfRegular)
+	}
+
+	// Items, continued on new pages when they do not fit
+	y += 24
+	y = pdfTableHeader(pdf, y)
+	for i := range doc.Items {
+		if y+pdfRowHeight > pdfBottomEdge {
+			pdf.AddPage()
+			y = pdfTableHeader(pdf, pdfMargin)
+		}
+		item := &doc.Items[i]
+		label := item.ProductCode + " · " + item.Label()
+		pdf.TextRight(pdfColNo, y+12, strconv.Itoa(i+1), pdfRegular)
+		pdf.Text(pdfColProduct, y+12, pdfRegular.Fit(label, pdfColQty-pdfColProduct-36), pdfRegular)
+		pdf.TextRight(pdfColQty, y+12, strconv.Itoa(item.Quantity), pdfRegular)
+		pdf.TextRight(pdfColPrice, y+12, utils.FormatRupiah(item.UnitPrice), pdfRegular)
+		if item.DiscountPercent > 0 {
+			pdf.TextRight(pdfColDiscount, y+12, strconv.FormatFloat(item.DiscountPercent, 'f', -1, 64)+"%", pdfRegular)
+		} else {
+			pdf.TextRight(pdfColDiscount, y+12, "-", pdfMuted)
+		}
+		pdf.TextRight(pdfColTotal, y+12, utils.FormatRupiah(item.LineTotal), pdfRegular)
+		y += pdfRowHeight
+		pdf.Line(pdfMargin, y, right, y, 0.25)
+	}
+
+	// Totals
+	if y+80 > pdfBottomEdge {
+		pdf.AddPage()
+		y = pdfMargin
+	}
+	y += 18
+	pdf.TextRight(pdfColDiscount, y, "Subtotal", pdfRegular)
+	pdf.TextRight(pdfColTotal, y, utils.FormatRupiah(doc.Subtotal), pdfRegular)
+	if doc.DiscountAmount > 0 {
+		y += 14
+		pdf.TextRight(pdfColDiscount, y, "Diskon", pdfRegular)
+		pdf.TextRight(pdfColTotal, y, "-"+utils.FormatRupiah(doc.DiscountAmount), pdfRegular)
+	}
+	y += 8
+	pdf.Line(pdfColPrice-40, y, right, y, 0.75)
+	y += 16
+	pdf.TextRight(pdfColDiscount, y, "Total", utils.PDFFont{Size: 11, Bold: true})
+	pdf.TextRight(pdfColTotal, y, utils.FormatRupiah(doc.Total), utils.PDFFont{Size: 11, Bold: true})
+
+	// Notes
+	if notes := wrapLines(doc.Notes, pdfRegular, right-pdfMargin); len(notes) > 0 {
+		y += 30
+		pdf.Text(pdfMargin, y, "Catatan", pdfMuted)
+		for _, line := range notes {
+			y += 12
+			if y > pdfBottomEdge {
+				pdf.AddPage()
+				y = pdfMargin
+			}
+			pdf.Text(pdfMargin, y, line, pdfRegular)
+		}
+	}
+
+	return pdf.Bytes()
+}
+
+// pdfTableHeader draws the item table's header row at y and returns the y of the
+// first row below it
+func pdfTableHeader(pdf *utils.PDF, y float64) float64 {
+	pdf.FillRect(pdfMargin, y, utils.PDFPageWidth-2*pdfMargin, pdfRowHeight, 0.93)
+	pdf.TextRight(pdfColNo, y+12, "No", pdfBold)
+	pdf.Text(pdfColProduct, y+12, "Produk", pdfBold)
+	pdf.TextRight(pdfColQty, y+12, "Qty", pdfBold)
+	pdf.TextRight(pdfColPrice, y+12, "Harga", pdfBold)
+	pdf.TextRight(pdfColDiscount, y+12, "Disc", pdfBold)
+	pdf.TextRight(pdfColTotal, y+12, "Jumlah", pdfBold)
+	return y + pdfRowHeight
+}
+
+// wrapLines splits multi-line text into lines that fit maxWidth, dropping blank lines
+func wrapLines(text string, font utils.PDFFont, maxWidth float64) []string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, font.Wrap(line, maxWidth)...)
+	}
+	return lines
+}
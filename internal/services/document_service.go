@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// DocumentItemInput is one line picked for a quotation or invoice
+type DocumentItemInput struct {
+	ProductID       int
+	VariantID       *int
+	Quantity        int
+	DiscountPercent float64
+	Label           string // Shown again when the form is re-rendered
+}
+
+// DocumentInput contains the data to issue a quotation or invoice
+type DocumentInput struct {
+	Type            models.DocumentType
+	CustomerName    string
+	CustomerPhone   string
+	CustomerAddress string
+	Notes           string
+	DiscountAmount  float64
+	DueDate         string // YYYY-MM-DD, optional
+	Items           []DocumentItemInput
+	AdminID         *int
+}
+
+// DocumentService issues quotations and invoices with gap-free numbering
+type DocumentService struct {
+	documentRepo    *repositories.DocumentRepository
+	productService  *ProductService
+	db              *sqlx.DB
+	settingsService *SettingsService
+}
+
+// NewDocumentService creates a new document service
+func NewDocumentService(documentRepo *repositories.DocumentRepository, productService *ProductService, db *sqlx.DB, settingsService *SettingsService) *DocumentService {
+	return &DocumentService{
+		documentRepo:    documentRepo,
+		productService:  productService,
+		db:              db,
+		settingsService: settingsService,
+	}
+}
+
+// GetAll retrieves documents with filtering and pagination
+func (s *DocumentService) GetAll(ctx context.Context, filters repositories.DocumentFilters) (*repositories.DocumentListResult, error) {
+	return s.documentRepo.FindAll(filters)
+}
+
+// GetByID retrieves a document with its items
+func (s *DocumentService) GetByID(ctx context.Context, id int) (*models.Document, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid document ID")
+	}
+	return s.documentRepo.FindByID(id)
+}
+
+// DraftFrom returns the input for a new document of docType that repeats the
+// customer and lines of an issued one, e.g. to invoice an accepted quotation.
+// Prices are looked up again when the new document is issued.
+func (s *DocumentService) DraftFrom(ctx context.Context, id int, docType models.DocumentType) (*DocumentInput, error) {
+	doc, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &DocumentInput{
+		Type:            docType,
+		CustomerName:    doc.CustomerName,
+		CustomerPhone:   doc.CustomerPhone,
+		CustomerAddress: doc.CustomerAddress,
+		Notes:           doc.Notes,
+		DiscountAmount:  doc.DiscountAmount,
+	}
+	for i := range doc.Items {
+		item := &doc.Items[i]
+		if item.ProductID == nil {
+			continue // Product deleted since the document was issued
+		}
+		input.Items = append(input.Items, DocumentItemInput{
+			ProductID:       *item.ProductID,
+			VariantID:       item.VariantID,
+			Quantity:        item.Quantity,
+			DiscountPercent: item.DiscountPercent,
+			Label:           item.ProductCode + " · " + item.Label(),
+		})
+	}
+
+	return input, nil
+}
+
+// Issue validates the input, snapshots the lines at current catalog prices and
+// stores the document under the next number of its type for the current year
+func (s *DocumentService) Issue(ctx context.Context, input DocumentInput) (*models.Document, error) {
+	dueDate, err := s.validateDocument(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.priceItems(ctx, input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents keep the store details they were issued with
+	store := s.settingsService.Get()
+	doc := &models.Document{
+		Type:            input.Type,
+		CustomerName:    input.CustomerName,
+		CustomerPhone:   input.CustomerPhone,
+		CustomerAddress: input.CustomerAddress,
+		StoreName:       store.Name,
+		StoreAddress:    store.Address,
+		Notes:           input.Notes,
+		DiscountAmount:  input.DiscountAmount,
+		DueDate:         dueDate,
+		AdminID:         input.AdminID,
+		Items:           items,
+	}
+	for i := range items {
+		doc.Subtotal += items[i].LineTotal
+	}
+	if doc.DiscountAmount > doc.Subtotal {
+		return nil, errors.New("discount cannot exceed the subtotal")
+	}
+	doc.Total = doc.Subtotal - doc.DiscountAmount
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	doc.Year, doc.Sequence, err = s.documentRepo.NextSequence(tx, doc.Type)
+	if err != nil {
+		return nil, err
+	}
+	doc.Number = models.DocumentNumber(doc.Type, doc.Year, doc.Sequence)
+
+	if err := s.documentRepo.Create(tx, doc); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return doc, nil
+}
+
+// priceItems resolves each input line against the catalog and snapshots its
+// product, variant and unit price (live promotions and wholesale tiers included),
+// then applies the line discount. Lines are kept as entered, since the same
+// variant may be offered twice with different discounts.
+func (s *DocumentService) priceItems(ctx context.Context, inputs []DocumentItemInput) ([]models.DocumentItem, error) {
+	productIDs := make([]int, 0, len(inputs))
+	for _, input := range inputs {
+		productIDs = append(productIDs, input.ProductID)
+	}
+	products, err := s.productService.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.DocumentItem, 0, len(inputs))
+	for _, input := range inputs {
+		product, ok := products[input.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("product #%d is no longer in the catalog", input.ProductID)
+		}
+
+		var variant *models.ProductVariant
+		switch {
+		case input.VariantID != nil:
+			if variant = findVariant(product, *input.VariantID); variant == nil {
+				return nil, fmt.Errorf("variant of %s is no longer in the catalog", product.Code)
+			}
+		case len(product.Variants) == 1:
+			variant = &product.Variants[0]
+		case len(product.Variants) > 1:
+			return nil, fmt.Errorf("choose a variant for %s", product.Code)
+		}
+
+		line := models.BasketLine{
+			BasketItem: models.BasketItem{ProductID: product.ID, Quantity: input.Quantity},
+			Product:    product,
+			Variant:    variant,
+		}
+		productID := product.ID
+		item := models.DocumentItem{
+			ProductID:       &productID,
+			ProductCode:     product.Code,
+			ProductTitle:    product.Title,
+			Quantity:        input.Quantity,
+			UnitPrice:       line.UnitPrice(),
+			DiscountPercent: input.DiscountPercent,
+		}
+		if variant != nil {
+			variantID := variant.ID
+			item.VariantID = &variantID
+			item.VariantColor = variant.Color
+		}
+		item.LineTotal = math.Round(item.GrossAmount() * (100 - item.DiscountPercent) / 100)
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// validateDocument trims and checks the input and parses the due date
+func (s *DocumentService) validateDocument(input *DocumentInput) (*time.Time, error) {
+	input.CustomerName = strings.TrimSpace(input.CustomerName)
+	input.CustomerPhone = strings.TrimSpace(input.CustomerPhone)
+	input.CustomerAddress = strings.TrimSpace(input.CustomerAddress)
+	input.Notes = strings.TrimSpace(input.Notes)
+	input.DueDate = strings.TrimSpace(input.DueDate)
+
+	switch input.Type {
+	case models.DocumentQuotation, models.DocumentInvoice:
+	default:
+		return nil, errors.New("invalid document type")
+	}
+
+	if len(input.CustomerName) < 2 {
+		return nil, errors.New("customer name must be at least 2 characters")
+	}
+	if len(input.CustomerName) > 100 {
+		return nil, errors.New("customer name must not exceed 100 characters")
+	}
+	if len(input.CustomerPhone) > 30 {
+		return nil, errors.New("customer phone must not exceed 30 characters")
+	}
+	if input.DiscountAmount < 0 {
+		return nil, errors.New("discount cannot be negative")
+	}
+
+	var dueDate *time.Time
+	if input.DueDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", input.DueDate, models.StoreLocation)
+		if err != nil {
+			return nil, errors.New("invalid date format (use YYYY-MM-DD)")
+		}
+		year, month, day := time.Now().In(models.StoreLocation).Date()
+		if date.Before(time.Date(year, month, day, 0, 0, 0, 0, models.StoreLocation)) {
+			return nil, errors.New("date cannot be in the past")
+		}
+		dueDate = &date
+	}
+
+	if len(input.Items) == 0 {
+		return nil, errors.New("add at least one product to the document")
+	}
+	for _, item := range input.Items {
+		if item.ProductID <= 0 {
+			return nil, errors.New("invalid product in document")
+		}
+		if item.Quantity < 1 || item.Quantity > models.MaxBasketQuantity {
+			return nil, fmt.Errorf("quantity must be between 1 and %d", models.MaxBasketQuantity)
+		}
+		if item.DiscountPercent < 0 || item.DiscountPercent > 100 {
+			return nil, errors.New("line discount must be between 0 and 100 percent")
+		}
+	}
+
+	return dueDate, nil
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// feedBatchSize is the number of products read from the database at a time
+const feedBatchSize = 200
+
+// Marketplace limits on feed text, in characters
+const (
+	feedTitleLimit       = 150
+	feedDescriptionLimit = 5000
+	feedExtraImageLimit  = 10
+)
+
+// FeedItem is one sellable item in a marketplace feed: a product variant, or a
+// product without variants. Variants of one product share an ItemGroupID, which
+// the feeds only list for products with several variants.
+type FeedItem struct {
+	ID               string
+	ItemGroupID      string
+	Title            string
+	Description      string
+	Link             string
+	ImageLink        string
+	ExtraImageLinks  []string
+	Price            float64 // Regular price in IDR
+	SalePrice        float64 // Price under a live promotion; 0 when there is none
+	SaleStart        time.Time
+	SaleEnd          time.Time
+	Availability     string // "in stock" or "out of stock"
+	Brand            string
+	Color            string
+	ProductType      string // Category breadcrumb, "Kertas > Kertas Korea"
+	MultipleVariants bool
+}
+
+// FeedService exports the catalog for Google Merchant Center and Meta Commerce
+// Manager. Feeds are written while the catalog is read, batch by batch.
+type FeedService struct {
+	productRepo     *repositories.ProductRepository
+	categoryRepo    *repositories.CategoryRepository
+	productService  *ProductService
+	settingsService *SettingsService
+}
+
+// NewFeedService creates a new feed service
+func NewFeedService(productRepo *repositories.ProductRepository, categoryRepo *repositories.CategoryRepository, productService *ProductService, settingsService *SettingsService) *FeedService {
+	return &FeedService{
+		productRepo:     productRepo,
+		categoryRepo:    categoryRepo,
+		productService:  productService,
+		settingsService: settingsService,
+	}
+}
+
+// Each calls fn for every item in the catalog at public prices, with links on baseURL
+func (s *FeedService) Each(baseURL string, fn func(item FeedItem) error) error {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return err
+	}
+	productTypes := categoryBreadcrumbs(categories)
+	brand := s.settingsService.Get().Name
+
+	return s.productRepo.Each(feedBatchSize, func(products []models.Product) error {
+		if err := s.productService.applyPromotions(products); err != nil {
+			return err
+		}
+
+		for i := range products {
+			product := &products[i]
+			for _, item := range feedItems(product, baseURL, brand) {
+				if product.CategoryID != nil {
+					item.ProductType = productTypes[*product.CategoryID]
+				}
+				if err := fn(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// feedItems lists the feed items of one product: one per variant, or the product itself
+func feedItems(product *models.Product, baseURL, brand string) []FeedItem {
+	base := FeedItem{
+		ID:          product.Code,
+		ItemGroupID: product.Code,
+		Title:       product.Title,
+		Description: product.Description,
+		Link:        baseURL + product.URL(),
+		ImageLink:   product.MainPhotoURL,
+		Price:       product.BasePrice,
+		Brand:       brand,
+	}
+	if strings.TrimSpace(base.Description) == "" {
+		base.Description = product.Title
+	}
+	for _, image := range product.Images {
+		if len(base.ExtraImageLinks) == feedExtraImageLimit {
+			break
+		}
+		base.ExtraImageLinks = append(base.ExtraImageLinks, image.ImageURL)
+	}
+
+	if len(product.Variants) == 0 {
+		item := base
+		item.Availability = feedAvailability(!product.IsSold)
+		applyFeedPromotion(&item, product.Promotion, product.BasePrice)
+		return []FeedItem{item}
+	}
+
+	items := make([]FeedItem, 0, len(product.Variants))
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		item := base
+		item.ID = product.Code + "-" + strconv.Itoa(variant.ID)
+		item.Color = variant.Color
+		item.Price = variant.RegularPrice(product.BasePrice)
+		item.Availability = feedAvailability(variant.StockQty > 0)
+		item.MultipleVariants = len(product.Variants) > 1
+		if item.MultipleVariants && variant.Color != "" {
+			item.Title = product.Title + " - " + variant.Color
+		}
+		if variant.PhotoURL != "" {
+			item.ImageLink = variant.PhotoURL
+		}
+		applyFeedPromotion(&item, variant.Promotion, item.Price)
+		items = append(items, item)
+	}
+	return items
+}
+
+// WriteGoogle writes the catalog as a Google Merchant Center RSS 2.0 feed
+func (s *FeedService) WriteGoogle(w io.Writer, baseURL string) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	storeName := s.settingsService.Get().Name
+	enc := xml.NewEncoder(w)
+	rss := xml.StartElement{
+		Name: xml.Name{Local: "rss"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "version"}, Value: "2.0"},
+			{Name: xml.Name{Local: "xmlns:g"}, Value: "http://base.google.com/ns/1.0"},
+		},
+	}
+	channel := xml.StartElement{Name: xml.Name{Local: "channel"}}
+	if err := enc.EncodeToken(rss); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(channel); err != nil {
+		return err
+	}
+	for _, field := range [][2]string{
+		{"title", storeName},
+		{"link", baseURL + "/"},
+		{"description", storeName + " product catalog"},
+	} {
+		if err := enc.EncodeElement(field[1], xml.StartElement{Name: xml.Name{Local: field[0]}}); err != nil {
+			return err
+		}
+	}
+
+	err := s.Each(baseURL, func(item FeedItem) error {
+		return enc.Encode(newGoogleFeedItem(item))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := enc.EncodeToken(channel.End()); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(rss.End()); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// googleFeedItem is an <item> of the Google Merchant Center feed
+type googleFeedItem struct {
+	XMLName                xml.Name `xml:"item"`
+	ID                     string   `xml:"g:id"`
+	Title                  string   `xml:"g:title"`
+	Description            string   `xml:"g:description"`
+	Link                   string   `xml:"g:link"`
+	ImageLink              string   `xml:"g:image_link,omitempty"`
+	AdditionalImageLinks   []string `xml:"g:additional_image_link,omitempty"`
+	Availability           string   `xml:"g:availability"`
+	Price                  string   `xml:"g:price"`
+	SalePrice              string   `xml:"g:sale_price,omitempty"`
+	SalePriceEffectiveDate string   `xml:"g:sale_price_effective_date,omitempty"`
+	Brand                  string   `xml:"g:brand"`
+	Condition              string   `xml:"g:condition"`
+	IdentifierExists       string   `xml:"g:identifier_exists"`
+	ItemGroupID            string   `xml:"g:item_group_id,omitempty"`
+	Color                  string   `xml:"g:color,omitempty"`
+	ProductType            string   `xml:"g:product_type,omitempty"`
+}
+
+func newGoogleFeedItem(item FeedItem) googleFeedItem {
+	feedItem := googleFeedItem{
+		ID:                     item.ID,
+		Title:                  truncateRunes(item.Title, feedTitleLimit),
+		Description:            truncateRunes(item.Description, feedDescriptionLimit),
+		Link:                   item.Link,
+		ImageLink:              item.ImageLink,
+		AdditionalImageLinks:   item.ExtraImageLinks,
+		Availability:           item.Availability,
+		Price:                  feedPrice(item.Price),
+		SalePriceEffectiveDate: feedSaleDates(item),
+		Brand:                  item.Brand,
+		Condition:              "new",
+		IdentifierExists:       "no", // Own-brand supplies have no GTIN or MPN
+		Color:                  item.Color,
+		ProductType:            item.ProductType,
+	}
+	if item.SalePrice > 0 {
+		feedItem.SalePrice = feedPrice(item.SalePrice)
+	}
+	if item.MultipleVariants {
+		feedItem.ItemGroupID = item.ItemGroupID
+	}
+	return feedItem
+}
+
+// metaFeedColumns is the header row of the Meta Commerce Manager feed
+var metaFeedColumns = []string{
+	"id", "title", "description", "availability", "condition", "price", "link",
+	"image_link", "brand", "item_group_id", "sale_price", "sale_price_effective_date",
+	"color", "product_type", "additional_image_link",
+}
+
+// WriteMeta writes the catalog as a Meta Commerce Manager CSV feed
+func (s *FeedService) WriteMeta(w io.Writer, baseURL string) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(metaFeedColumns); err != nil {
+		return err
+	}
+
+	err := s.Each(baseURL, func(item FeedItem) error {
+		var salePrice, itemGroupID string
+		if item.SalePrice > 0 {
+			salePrice = feedPrice(item.SalePrice)
+		}
+		if item.MultipleVariants {
+			itemGroupID = item.ItemGroupID
+		}
+		return out.Write([]string{
+			item.ID,
+			truncateRunes(item.Title, feedTitleLimit),
+			truncateRunes(item.Description, feedDescriptionLimit),
+			item.Availability,
+			"new",
+			feedPrice(item.Price),
+			item.Link,
+			item.ImageLink,
+			item.Brand,
+			itemGroupID,
+			salePrice,
+			feedSaleDates(item),
+			item.Color,
+			item.ProductType,
+			strings.Join(item.ExtraImageLinks, ","),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// applyFeedPromotion sets the sale price and period of an item under a live promotion
+func applyFeedPromotion(item *FeedItem, promo *models.Promotion, price float64) {
+	if promo == nil {
+		return
+	}
+	if sale := promo.Apply(price); sale < price {
+		item.SalePrice = sale
+		item.SaleStart = promo.StartsAt
+		item.SaleEnd = promo.EndsAt
+	}
+}
+
+// feedAvailability returns the availability value both marketplaces accept
+func feedAvailability(inStock bool) string {
+	if inStock {
+		return "in stock"
+	}
+	return "out of stock"
+}
+
+// feedPrice formats an IDR amount as "15000 IDR"
+func feedPrice(price float64) string {
+	return fmt.Sprintf("%.0f IDR", price)
+}
+
+// feedSaleDates returns the ISO 8601 period of a sale price, or "" without one
+func feedSaleDates(item FeedItem) string {
+	if item.SalePrice <= 0 || item.SaleStart.IsZero() || item.SaleEnd.IsZero() {
+		return ""
+	}
+	return storeTime(item.SaleStart).Format(time.RFC3339) + "/" + storeTime(item.SaleEnd).Format(time.RFC3339)
+}
+
+// storeTime tags a promotion's wall-clock time with the store's zone, the same zone
+// the storefront applies it in. The database returns TIMESTAMP columns tagged as UTC.
+func storeTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), models.StoreLocation)
+}
+
+// categoryBreadcrumbs returns each category's path from the top level, keyed by ID
+func categoryBreadcrumbs(categories []models.Category) map[int]string {
+	byID := make(map[int]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	breadcrumbs := make(map[int]string, len(categories))
+	for _, category := range categories {
+		var names []string
+		for _, part := range strings.Split(strings.Trim(category.Path, "/"), "/") {
+			id, err := strconv.Atoi(part)
+			if err != nil {
+				continue
+			}
+			if ancestor, ok := byID[id]; ok {
+				names = append(names, ancestor.Name)
+			}
+		}
+		breadcrumbs[category.ID] = strings.Join(names, " > ")
+	}
+	return breadcrumbs
+}
+
+// truncateRunes shortens s to at most limit characters
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/utils"
+)
+
+// ErrInquiryProductNotFound is returned when an inquiry names an unknown product
+var ErrInquiryProductNotFound = errors.New("product not found")
+
+// maxReferrerLength caps the stored referrer URL
+const maxReferrerLength = 500
+
+// ProductInquiryInput is a "Chat via WhatsApp" click on a product page
+type ProductInquiryInput struct {
+	ProductID int
+	VariantID *int
+	Quantity  int
+	Referrer  string
+}
+
+// InquiryService records WhatsApp inquiries as leads and manages their follow-up
+type InquiryService struct {
+	inquiryRepo     *repositories.InquiryRepository
+	productService  *ProductService
+	db              *sqlx.DB
+	settingsService *SettingsService
+}
+
+// NewInquiryService creates a new inquiry service
+func NewInquiryService(inquiryRepo *repositories.InquiryRepository, productService *ProductService, db *sqlx.DB, settingsService *SettingsService) *InquiryService {
+	return &InquiryService{
+		inquiryRepo:     inquiryRepo,
+		productService:  productService,
+		db:              db,
+		settingsService: settingsService,
+	}
+}
+
+// RecordProductInquiry stores a lead for the product, variant and quantity the
+// visitor chose and returns the wa.me link with the matching message. The link
+// is set whenever the product exists, even if the lead could not be stored.
+func (s *InquiryService) RecordProductInquiry(ctx context.Context, input ProductInquiryInput) (string, error) {
+	products, err := s.productService.GetByIDs(ctx, []int{input.ProductID})
+	if err != nil {
+		return "", err
+	}
+	product, ok := products[input.ProductID]
+	if !ok {
+		return "", ErrInquiryProductNotFound
+	}
+
+	quantity := input.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+	if quantity > models.MaxBasketQuantity {
+		quantity = models.MaxBasketQuantity
+	}
+
+	// An unknown variant is ignored rather than failing the chat; a single variant needs no choice
+	var variant *models.ProductVariant
+	if input.VariantID != nil {
+		variant = findVariant(product, *input.VariantID)
+	}
+	if variant == nil && len(product.Variants) == 1 {
+		variant = &product.Variants[0]
+	}
+
+	line := models.BasketLine{
+		BasketItem: models.BasketItem{ProductID: product.ID, Quantity: quantity},
+		Product:    product,
+		Variant:    variant,
+	}
+	inquiry := newInquiry(&line, models.InquirySourceProduct, input.Referrer)
+
+	waURL := whatsAppURL(s.settingsService.Get().WhatsAppNumber, productInquiryMessage(&line, customerFrom(ctx)))
+	return waURL, s.create([]*models.Inquiry{inquiry})
+}
+
+// RecordBasket stores one lead per basket line when the basket is checked out
+func (s *InquiryService) RecordBasket(ctx context.Context, basket *models.Basket, referrer string) error {
+	inquiries := make([]*models.Inquiry, 0, len(basket.Lines))
+	for i := range basket.Lines {
+		inquiries = append(inquiries, newInquiry(&basket.Lines[i], models.InquirySourceBasket, referrer))
+	}
+	return s.create(inquiries)
+}
+
+// GetAll retrieves inquiries with filtering and pagination
+func (s *InquiryService) GetAll(ctx context.Context, filters repositories.InquiryFilters) (*repositories.InquiryListResult, error) {
+	return s.inquiryRepo.FindAll(filters)
+}
+
+// CountByStatus returns the number of inquiries in each status
+func (s *InquiryService) CountByStatus(ctx context.Context) (map[models.InquiryStatus]int, error) {
+	return s.inquiryRepo.CountByStatus()
+}
+
+// GetConversions returns per-product inquiry and won counts over the last days
+func (s *InquiryService) GetConversions(ctx context.Context, days, limit int) ([]models.InquiryConversion, error) {
+	return s.inquiryRepo.FindConversions(days, limit)
+}
+
+// UpdateStatus records the follow-up status and notes of an inquiry
+func (s *InquiryService) UpdateStatus(ctx context.Context, id int, status models.InquiryStatus, notes string) error {
+	switch status {
+	case models.InquiryNew, models.InquiryContacted, models.InquiryWon, models.InquiryLost:
+	default:
+		return fmt.Errorf("invalid inquiry status %q", status)
+	}
+
+	notes = strings.TrimSpace(notes)
+	if len(notes) > 1000 {
+		return errors.New("notes must not exceed 1000 characters")
+	}
+
+	return s.inquiryRepo.UpdateStatus(id, status, notes)
+}
+
+// create stores inquiries in one transaction
+func (s *InquiryService) create(inquiries []*models.Inquiry) error {
+	if len(inquiries) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, inquiry := range inquiries {
+		if err := s.inquiryRepo.Create(tx, inquiry); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// newInquiry snapshots a priced line as a lead
+func newInquiry(line *models.BasketLine, source models.InquirySource, referrer string) *models.Inquiry {
+	productID := line.Product.ID
+	inquiry := &models.Inquiry{
+		ProductID:    &productID,
+		ProductCode:  line.Product.Code,
+		ProductTitle: line.Product.Title,
+		Quantity:     line.Quantity,
+		UnitPrice:    line.UnitPrice(),
+		Source:       source,
+		Referrer:     truncateReferrer(referrer),
+	}
+	if line.Variant != nil {
+		variantID := line.Variant.ID
+		inquiry.VariantID = &variantID
+		inquiry.VariantColor = line.Variant.Color
+	}
+	return inquiry
+}
+
+// productInquiryMessage composes the product page WhatsApp message for a line,
+// naming the wholesale tier or promotion that sets its price
+func productInquiryMessage(line *models.BasketLine, customer *models.Customer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Halo, saya tertarik dengan %s (Kode: %s).", line.Label(), line.Product.Code)
+	fmt.Fprintf(&b, " Jumlah: %d pcs @ %s", line.Quantity, utils.FormatRupiah(line.UnitPrice()))
+
+	promotion := line.Product.Promotion
+	if line.Variant != nil {
+		promotion = line.Variant.Promotion
+		if tier := line.Variant.TierFor(line.Quantity); tier != nil && tier.UnitPrice < line.Variant.FinalPrice(line.Product.BasePrice) {
+			fmt.Fprintf(&b, " (harga grosir min. %d pcs)", tier.MinQty)
+			promotion = nil
+		}
+	}
+	if promotion != nil {
+		fmt.Fprintf(&b, " (%s)", promotion.Name)
+	}
+	if line.HasGroupPrice() {
+		b.WriteString(" (harga reseller)")
+	}
+	b.WriteString(".")
+
+	if customer != nil {
+		fmt.Fprintf(&b, " Akun reseller: %s, %s.", customer.DisplayName(), customer.Phone)
+	}
+	b.WriteString(" Apakah masih tersedia?")
+	return b.String()
+}
+
+// truncateReferrer keeps referrers within maxReferrerLength bytes on a rune boundary
+func truncateReferrer(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if len(referrer) <= maxReferrerLength {
+		return referrer
+	}
+	return strings.ToValidUTF8(referrer[:maxReferrerLength], "")
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// MovementInput contains the data needed to record a stock movement
+type MovementInput struct {
+	VariantID    int
+	MovementType models.MovementType
+	Quantity     int // Always positive, except for adjustments where the sign is the direction
+	Reason       string
+	AdminID      *int
+}
+
+// InventoryService handles stock movements and keeps product availability in sync
+type InventoryService struct {
+	inventoryRepo *repositories.InventoryRepository
+	productRepo   *repositories.ProductRepository
+	db            *sqlx.DB
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService(inventoryRepo *repositories.InventoryRepository, productRepo *repositories.ProductRepository, db *sqlx.DB) *InventoryService {
+	return &InventoryService{
+		inventoryRepo: inventoryRepo,
+		productRepo:   productRepo,
+		db:            db,
+	}
+}
+
+// GetStockLevels lists variants with their current stock
+func (s *InventoryService) GetStockLevels(ctx context.Context, search string) ([]models.VariantStock, error) {
+	return s.inventoryRepo.FindStockLevels(strings.TrimSpace(search))
+}
+
+// GetVariantStock retrieves one variant's stock level
+func (s *InventoryService) GetVariantStock(ctx context.Context, variantID int) (*models.VariantStock, error) {
+	if variantID <= 0 {
+		return nil, errors.New("invalid variant ID")
+	}
+
+	level, err := s.inventoryRepo.FindStockByVariantID(variantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+
+	return level, nil
+}
+
+// GetMovements retrieves the movement ledger
+func (s *InventoryService) GetMovements(ctx context.Context, filters repositories.MovementFilters) (*repositories.MovementListResult, error) {
+	if filters.PageSize > 100 {
+		filters.PageSize = 100
+	}
+	return s.inventoryRepo.FindMovements(filters)
+}
+
+// RecordMovement appends a ledger entry and applies it to the variant's stock.
+// The variant row is locked for the duration of the transaction so concurrent
+// movements cannot oversell.
+func (s *InventoryService) RecordMovement(ctx context.Context, input MovementInput) (*models.InventoryMovement, error) {
+	delta, err := s.validateMovement(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	productID, stockQty, err := s.inventoryRepo.LockVariantStock(tx, input.VariantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+
+	balance := stockQty + delta
+	if balance < 0 {
+		return nil, fmt.Errorf("insufficient stock: %d available, movement needs %d", stockQty, -delta)
+	}
+
+	movement := &models.InventoryMovement{
+		VariantID:    input.VariantID,
+		MovementType: input.MovementType,
+		Quantity:     delta,
+		BalanceAfter: balance,
+		Reason:       input.Reason,
+		AdminID:      input.AdminID,
+	}
+	if err := s.inventoryRepo.CreateMovement(tx, movement); err != nil {
+		return nil, err
+	}
+	if err := s.inventoryRepo.SetVariantStock(tx, input.VariantID, balance); err != nil {
+		return nil, err
+	}
+	if err := s.productRepo.RefreshAvailability(tx, productID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return movement, nil
+}
+
+// validateMovement checks the input and returns the signed stock delta
+func (s *InventoryService) validateMovement(input *MovementInput) (int, error) {
+	if input.VariantID <= 0 {
+		return 0, errors.New("variant is required")
+	}
+
+	input.Reason = strings.TrimSpace(input.Reason)
+	if len(input.Reason) < 3 {
+		return 0, errors.New("reason must be at least 3 characters")
+	}
+	if len(input.Reason) > 255 {
+		return 0, errors.New("reason must not exceed 255 characters")
+	}
+
+	if input.Quantity == 0 {
+		return 0, errors.New("quantity must not be zero")
+	}
+
+	switch input.MovementType {
+	case models.MovementStockIn, models.MovementReturn:
+		if input.Quantity < 0 {
+			return 0, errors.New("quantity must be positive")
+		}
+		return input.Quantity, nil
+	case models.MovementSale, models.MovementDamage:
+		if input.Quantity < 0 {
+			return 0, errors.New("quantity must be positive")
+		}
+		return -input.Quantity, nil
+	case models.MovementAdjustment:
+		return input.Quantity, nil
+	}
+
+	return 0, fmt.Errorf("invalid movement type %q", input.MovementType)
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// OrderItemInput is one line picked from the catalog for a new order
+type OrderItemInput struct {
+	ProductID int
+	VariantID *int
+	Quantity  int
+	Label     string // Shown again when the form is re-rendered
+}
+
+// OrderInput contains the data to create an order
+type OrderInput struct {
+	CustomerName    string
+	CustomerPhone   string
+	ShippingAddress string
+	Notes           string
+	ShippingCost    float64
+	Items           []OrderItemInput
+	AdminID         *int
+}
+
+// OrderService handles confirmed orders and their status workflow
+type OrderService struct {
+	orderRepo      *repositories.OrderRepository
+	productService *ProductService
+	db             *sqlx.DB
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(orderRepo *repositories.OrderRepository, productService *ProductService, db *sqlx.DB) *OrderService {
+	return &OrderService{
+		orderRepo:      orderRepo,
+		productService: productService,
+		db:             db,
+	}
+}
+
+// GetAll retrieves orders with filtering and pagination
+func (s *OrderService) GetAll(ctx context.Context, filters repositories.OrderFilters) (*repositories.OrderListResult, error) {
+	return s.orderRepo.FindAll(filters)
+}
+
+// GetByID retrieves an order with its items and status history
+func (s *OrderService) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid order ID")
+	}
+	return s.orderRepo.FindByID(id)
+}
+
+// CountByStatus returns the number of orders in each status
+func (s *OrderService) CountByStatus(ctx context.Context) (map[models.OrderStatus]int, error) {
+	return s.orderRepo.CountByStatus()
+}
+
+// Create validates the input, snapshots each line's product, variant colour and
+// current price, and stores the order as pending payment
+func (s *OrderService) Create(ctx context.Context, input OrderInput) (*models.Order, error) {
+	if err := s.validateOrder(&input); err != nil {
+		return nil, err
+	}
+
+	items, err := s.priceItems(ctx, input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.Order{
+		CustomerName:    input.CustomerName,
+		CustomerPhone:   input.CustomerPhone,
+		ShippingAddress: input.ShippingAddress,
+		Notes:           input.Notes,
+		Status:          models.OrderPendingPayment,
+		ShippingCost:    input.ShippingCost,
+		AdminID:         input.AdminID,
+		Items:           items,
+	}
+	for i := range items {
+		order.Subtotal += items[i].Subtotal()
+	}
+	order.Total = order.Subtotal + order.ShippingCost
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.orderRepo.Create(tx, order); err != nil {
+		return nil, err
+	}
+
+	created := &models.OrderStatusChange{
+		OrderID:  order.ID,
+		ToStatus: order.Status,
+		Note:     "Pesanan dibuat",
+		AdminID:  input.AdminID,
+	}
+	if err := s.orderRepo.AddStatusChange(tx, created); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return order, nil
+}
+
+// ChangeStatus moves an order along the workflow and records the change in its history
+func (s *OrderService) ChangeStatus(ctx context.Context, id int, status models.OrderStatus, note string, adminID *int) error {
+	note = strings.TrimSpace(note)
+	if len(note) > 500 {
+		return errors.New("note must not exceed 500 characters")
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := s.orderRepo.LockStatus(tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("order not found")
+		}
+		return err
+	}
+
+	if !current.CanTransitionTo(status) {
+		return fmt.Errorf("cannot change status from %q to %q", current.Label(), status.Label())
+	}
+
+	if err := s.orderRepo.UpdateStatus(tx, id, status); err != nil {
+		return err
+	}
+
+	change := &models.OrderStatusChange{
+		OrderID:    id,
+		FromStatus: &current,
+		ToStatus:   status,
+		Note:       note,
+		AdminID:    adminID,
+	}
+	if err := s.orderRepo.AddStatusChange(tx, change); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// priceItems resolves each input line against the catalog and snapshots its
+// product, variant and unit price (live promotions and wholesale tiers included).
+// Lines for the same product and variant are merged.
+func (s *OrderService) priceItems(ctx context.Context, inputs []OrderItemInput) ([]models.OrderItem, error) {
+	productIDs := make([]int, 0, len(inputs))
+	for _, input := range inputs {
+		productIDs = append(productIDs, input.ProductID)
+	}
+	products, err := s.productService.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type lineKey struct{ productID, variantID int }
+	lines := []models.BasketLine{}
+	index := map[lineKey]int{}
+
+	for _, input := range inputs {
+		product, ok := products[input.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("product #%d is no longer in the catalog", input.ProductID)
+		}
+
+		var variant *models.ProductVariant
+		switch {
+		case input.VariantID != nil:
+			if variant = findVariant(product, *input.VariantID); variant == nil {
+				return nil, fmt.Errorf("variant of %s is no longer in the catalog", product.Code)
+			}
+		case len(product.Variants) == 1:
+			variant = &product.Variants[0]
+		case len(product.Variants) > 1:
+			return nil, fmt.Errorf("choose a variant for %s", product.Code)
+		}
+
+		key := lineKey{productID: product.ID}
+		if variant != nil {
+			key.variantID = variant.ID
+		}
+		if i, ok := index[key]; ok {
+			lines[i].Quantity += input.Quantity
+			continue
+		}
+		index[key] = len(lines)
+		lines = append(lines, models.BasketLine{
+			BasketItem: models.BasketItem{ProductID: product.ID, Quantity: input.Quantity},
+			Product:    product,
+			Variant:    variant,
+		})
+	}
+
+	items := make([]models.OrderItem, 0, len(lines))
+	for i := range lines {
+		line := &lines[i]
+		productID := line.Product.ID
+		item := models.OrderItem{
+			ProductID:    &productID,
+			ProductCode:  line.Product.Code,
+			ProductTitle: line.Product.Title,
+			Quantity:     line.Quantity,
+			UnitPrice:    line.UnitPrice(),
+		}
+		if line.Variant != nil {
+			variantID := line.Variant.ID
+			item.VariantID = &variantID
+			item.VariantColor = line.Variant.Color
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// validateOrder trims and checks the customer details and lines
+func (s *OrderService) validateOrder(input *OrderInput) error {
+	input.CustomerName = strings.TrimSpace(input.CustomerName)
+	input.CustomerPhone = strings.TrimSpace(input.CustomerPhone)
+	input.ShippingAddress = strings.TrimSpace(input.ShippingAddress)
+	input.Notes = strings.TrimSpace(input.Notes)
+
+	if len(input.CustomerName) < 2 {
+		return errors.New("customer name must be at least 2 characters")
+	}
+	if len(input.CustomerName) > 100 {
+		return errors.New("customer name must not exceed 100 characters")
+	}
+	if len(input.CustomerPhone) > 30 {
+		return errors.New("customer phone must not exceed 30 characters")
+	}
+	if input.ShippingCost < 0 {
+		return errors.New("shipping cost cannot be negative")
+	}
+
+	if len(input.Items) == 0 {
+		return errors.New("add at least one product to the order")
+	}
+	for _, item := range input.Items {
+		if item.ProductID <= 0 {
+			return errors.New("invalid product in order")
+		}
+		if item.Quantity < 1 || item.Quantity > models.MaxBasketQuantity {
+			return fmt.Errorf("quantity must be between 1 and %d", models.MaxBasketQuantity)
+		}
+	}
+
+	return nil
+}
@@ -2,26 +2,67 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"mime/multipart"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/i18n"
 	"github.com/rizkysr90/aslam-flower/internal/models"
 	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/utils"
 )
 
+// maxProductImages caps the gallery size per product
+const maxProductImages = 20
+
+// weakSearchResults is the result count below which a search offers a "did you mean" suggestion
+const weakSearchResults = 3
+
+// vocabularyMaxAge is how stale the "did you mean" word list may get before a
+// search refreshes it in the background
+const vocabularyMaxAge = 15 * time.Minute
+
+// pricesMaxAge is how long the stored current prices are trusted when no promotion
+// starts or ends sooner; it bounds staleness from edits made on another instance
+const pricesMaxAge = 15 * time.Minute
+
+// SearchResult is the outcome of a quick product search
+type SearchResult struct {
+	Products   []models.Product
+	Suggestion string // Corrected search text, empty when the results are good enough
+}
+
 // ProductService handles product business logic
 type ProductService struct {
 	productRepo       *repositories.ProductRepository
+	promotionRepo     *repositories.PromotionRepository
+	colorRepo         *repositories.ColorRepository
+	groupRepo         *repositories.CustomerGroupRepository
 	cloudinaryService *CloudinaryService
 	db                *sqlx.DB
+
+	vocabularyMu         sync.Mutex
+	vocabularyRefreshing bool
+	vocabularyRefreshed  time.Time
+
+	pricesMu         sync.Mutex
+	pricesValidUntil time.Time
 }
 
 // NewProductService creates a new product service
-func NewProductService(productRepo *repositories.ProductRepository, cloudinaryService *CloudinaryService, db *sqlx.DB) *ProductService {
+func NewProductService(productRepo *repositories.ProductRepository, promotionRepo *repositories.PromotionRepository, colorRepo *repositories.ColorRepository, groupRepo *repositories.CustomerGroupRepository, cloudinaryService *CloudinaryService, db *sqlx.DB) *ProductService {
 	return &ProductService{
 		productRepo:       productRepo,
+		promotionRepo:     promotionRepo,
+		colorRepo:         colorRepo,
+		groupRepo:         groupRepo,
 		cloudinaryService: cloudinaryService,
 		db:                db,
 	}
@@ -42,6 +83,7 @@ func (s *ProductService) GetAll(ctx context.Context, filters repositories.Produc
 
 	// Validate sort option
 	validSorts := map[string]bool{
+		"relevance":  true,
 		"newest":     true,
 		"price_asc":  true,
 		"price_desc": true,
@@ -51,11 +93,43 @@ func (s *ProductService) GetAll(ctx context.Context, filters repositories.Produc
 		filters.SortBy = "newest" // Default to newest
 	}
 
+	// Price filters and sorting read the stored current prices this visitor is shown
+	s.ensurePrices()
+	if group := customerGroupFrom(ctx); group != nil {
+		filters.PriceGroup = &group.ID
+	}
+
 	result, err := s.productRepo.FindAll(filters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch products: %w", err)
 	}
 
+	// Typos defeat full-text search; fall back to trigram similarity
+	if filters.SearchQuery != "" && result.Total == 0 && !filters.Fuzzy {
+		filters.Fuzzy = true
+		result, err = s.productRepo.FindAll(filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch products: %w", err)
+		}
+	}
+
+	// Facets use the same (possibly fuzzy) search as the listing
+	if filters.WithFacets {
+		if result.Facets, err = s.productRepo.FindFacets(filters); err != nil {
+			return nil, err
+		}
+	}
+
+	if filters.SearchQuery != "" && (filters.Fuzzy || result.Total < weakSearchResults) {
+		if result.Suggestion, err = s.suggest(filters.SearchQuery); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.applyRequest(ctx, result.Products); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
@@ -70,7 +144,46 @@ func (s *ProductService) GetByID(ctx context.Context, id int) (*models.Product,
 		return nil, fmt.Errorf("failed to fetch product: %w", err)
 	}
 
-	return product, nil
+	products := []models.Product{*product}
+	if err := s.applyRequest(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
+}
+
+// GetByIDs retrieves the given products with variants and live promotions, keyed by ID
+func (s *ProductService) GetByIDs(ctx context.Context, ids []int) (map[int]*models.Product, error) {
+	products, err := s.productRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(ctx, products); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+	return byID, nil
+}
+
+// GetBySlug retrieves a product by its current slug, or by a slug it used before a rename.
+// Callers compare the returned product's Slug with the requested one to redirect old links.
+func (s *ProductService) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	id, err := s.productRepo.FindIDBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		id, err = s.productRepo.FindIDBySlugHistory(slug)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
 }
 
 // Create creates a new product with photo upload
@@ -79,6 +192,9 @@ func (s *ProductService) Create(ctx context.Context, product *models.Product, ma
 	if err := s.validateProduct(product); err != nil {
 		return err
 	}
+	if err := s.resolveVariantColors(product); err != nil {
+		return err
+	}
 
 	// Check if product code already exists
 	existing, _ := s.productRepo.FindByCode(product.Code)
@@ -86,6 +202,12 @@ func (s *ProductService) Create(ctx context.Context, product *models.Product, ma
 		return fmt.Errorf("product with code %s already exists", product.Code)
 	}
 
+	slug, err := s.uniqueSlug(product.Title, 0)
+	if err != nil {
+		return err
+	}
+	product.Slug = slug
+
 	// Start transaction
 	tx, err := s.db.Beginx()
 	if err != nil {
@@ -144,15 +266,51 @@ func (s *ProductService) Create(ctx context.Context, product *models.Product, ma
 		}
 	}
 
+	// New variants start without stock, so availability follows from the (empty) ledger
+	// and the search document and price range are derived from the variants
+	err = s.productRepo.RefreshAvailability(tx, product.ID)
+	if err == nil {
+		err = s.productRepo.RefreshSearchVector(tx, product.ID)
+	}
+	if err == nil {
+		err = s.productRepo.RefreshPriceRange(tx, product.ID)
+	}
+	if err != nil {
+		if photoID != "" {
+			_ = s.cloudinaryService.DeleteImage(ctx, photoID)
+		}
+		_ = s.productRepo.Delete(product.ID)
+		return err
+	}
+
+	// Gallery images were uploaded directly by the browser; link them once variants have IDs
+	if err = s.resolveImageVariants(product); err == nil {
+		err = s.productRepo.SyncImages(tx, product.ID, product.Images)
+	}
+	if err == nil {
+		err = s.productRepo.SyncTranslations(tx, product.ID, product.Translations)
+	}
+	if err != nil {
+		if photoID != "" {
+			_ = s.cloudinaryService.DeleteImage(ctx, photoID)
+		}
+		s.deleteGalleryImages(ctx, product.Images, nil)
+		_ = s.productRepo.Delete(product.ID)
+		return err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		// Rollback: delete uploaded photo
 		if photoID != "" {
 			_ = s.cloudinaryService.DeleteImage(ctx, photoID)
 		}
+		s.deleteGalleryImages(ctx, product.Images, nil)
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.RefreshPrices()
+
 	return nil
 }
 
@@ -172,6 +330,9 @@ func (s *ProductService) Update(ctx context.Context, id int, product *models.Pro
 	if err := s.validateProduct(product); err != nil {
 		return err
 	}
+	if err := s.resolveVariantColors(product); err != nil {
+		return err
+	}
 
 	// Check if code is being changed and if new code already exists
 	if product.Code != existing.Code {
@@ -184,6 +345,16 @@ func (s *ProductService) Update(ctx context.Context, id int, product *models.Pro
 	// Set ID for update
 	product.ID = id
 
+	// The slug follows the title; the old one is kept in the history so shared links still resolve
+	product.Slug = existing.Slug
+	if product.Title != existing.Title {
+		slug, err := s.uniqueSlug(product.Title, id)
+		if err != nil {
+			return err
+		}
+		product.Slug = slug
+	}
+
 	// Handle photo update (server multipart upload or client direct upload via hidden fields)
 	oldPhotoID := existing.MainPhotoID
 	if newPhoto != nil {
@@ -218,7 +389,10 @@ func (s *ProductService) Update(ctx context.Context, id int, product *models.Pro
 	defer tx.Rollback()
 
 	// Update product in database
-	err = s.productRepo.Update(product)
+	err = s.productRepo.Update(tx, product)
+	if err == nil && product.Slug != existing.Slug {
+		err = s.productRepo.RecordSlugChange(tx, id, existing.Slug, product.Slug)
+	}
 	if err != nil {
 		// Rollback: if we uploaded a new photo, delete it
 		if newPhoto != nil && product.MainPhotoID != "" {
@@ -227,80 +401,56 @@ func (s *ProductService) Update(ctx context.Context, id int, product *models.Pro
 		return fmt.Errorf("failed to update product: %w", err)
 	}
 
-	// Handle variants update
-	if len(product.Variants) > 0 {
-		// Get existing variants to delete their photos from Cloudinary
-		existingVariants := existing.Variants
-		if existingVariants == nil {
-			existingVariants = []models.ProductVariant{}
-		}
-
-		// Create a set of PhotoIDs that are being preserved in new variants
-		preservedPhotoIDs := make(map[string]bool)
-		for _, newVariant := range product.Variants {
-			if newVariant.PhotoID != "" {
-				preservedPhotoIDs[newVariant.PhotoID] = true
-			}
-		}
-
-		// Delete old variants
-		err = s.productRepo.DeleteVariantsByProductID(tx, id)
-		if err != nil {
+	// Handle variants update: variants are synced in place so stock and ledger survive edits
+	for _, variant := range product.Variants {
+		if variant.Color == "" {
 			// Rollback: if we uploaded a new photo, delete it
 			if newPhoto != nil && product.MainPhotoID != "" {
 				_ = s.cloudinaryService.DeleteImage(ctx, product.MainPhotoID)
 			}
-			return fmt.Errorf("failed to delete old variants: %w", err)
+			return errors.New("variant color is required")
 		}
+	}
 
-		// Delete old variant photos from Cloudinary only if they're not being preserved
-		for _, oldVariant := range existingVariants {
-			if oldVariant.PhotoID != "" && !preservedPhotoIDs[oldVariant.PhotoID] {
-				_ = s.cloudinaryService.DeleteImage(ctx, oldVariant.PhotoID)
-			}
+	// Create a set of PhotoIDs that are being preserved in new variants
+	preservedPhotoIDs := make(map[string]bool)
+	for _, newVariant := range product.Variants {
+		if newVariant.PhotoID != "" {
+			preservedPhotoIDs[newVariant.PhotoID] = true
 		}
+	}
 
-		// Validate and create new variants
-		for _, variant := range product.Variants {
-			if variant.Color == "" {
-				// Rollback: if we uploaded a new photo, delete it
-				if newPhoto != nil && product.MainPhotoID != "" {
-					_ = s.cloudinaryService.DeleteImage(ctx, product.MainPhotoID)
-				}
-				return errors.New("variant color is required")
-			}
+	err = s.productRepo.SyncVariants(tx, id, product.Variants)
+	if err != nil {
+		// Rollback: if we uploaded a new photo, delete it
+		if newPhoto != nil && product.MainPhotoID != "" {
+			_ = s.cloudinaryService.DeleteImage(ctx, product.MainPhotoID)
 		}
+		return fmt.Errorf("failed to update variants: %w", err)
+	}
 
-		err = s.productRepo.CreateVariants(tx, id, product.Variants)
-		if err != nil {
-			// Rollback: if we uploaded a new photo, delete it
-			if newPhoto != nil && product.MainPhotoID != "" {
-				_ = s.cloudinaryService.DeleteImage(ctx, product.MainPhotoID)
-			}
-			// Rollback: delete uploaded variant photos
-			for _, variant := range product.Variants {
-				if variant.PhotoID != "" {
-					_ = s.cloudinaryService.DeleteImage(ctx, variant.PhotoID)
-				}
-			}
-			return fmt.Errorf("failed to create variants: %w", err)
-		}
-	} else {
-		// If no variants provided, delete all existing variants
-		existingVariants := existing.Variants
-		if existingVariants == nil {
-			existingVariants = []models.ProductVariant{}
-		}
-		err = s.productRepo.DeleteVariantsByProductID(tx, id)
-		if err != nil {
-			return fmt.Errorf("failed to delete variants: %w", err)
-		}
-		// Delete old variant photos from Cloudinary (best effort)
-		for _, oldVariant := range existingVariants {
-			if oldVariant.PhotoID != "" {
-				_ = s.cloudinaryService.DeleteImage(ctx, oldVariant.PhotoID)
-			}
-		}
+	// Removing a variant can leave the product without stock
+	if err = s.productRepo.RefreshAvailability(tx, id); err != nil {
+		return err
+	}
+	// Titles, colours and prices may have changed too
+	if err = s.productRepo.RefreshSearchVector(tx, id); err != nil {
+		return err
+	}
+	if err = s.productRepo.RefreshPriceRange(tx, id); err != nil {
+		return err
+	}
+
+	// Sync the gallery; images linked to a removed variant lose the link
+	if err = s.resolveImageVariants(product); err == nil {
+		err = s.productRepo.SyncImages(tx, id, product.Images)
+	}
+	if err == nil {
+		err = s.productRepo.SyncTranslations(tx, id, product.Translations)
+	}
+	if err != nil {
+		s.deleteGalleryImages(ctx, product.Images, existing.Images)
+		return err
 	}
 
 	// Commit transaction
@@ -315,9 +465,22 @@ func (s *ProductService) Update(ctx context.Context, id int, product *models.Pro
 				_ = s.cloudinaryService.DeleteImage(ctx, variant.PhotoID)
 			}
 		}
+		s.deleteGalleryImages(ctx, product.Images, existing.Images)
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.RefreshPrices()
+
+	// Delete gallery images that were removed from the product (best effort)
+	s.deleteGalleryImages(ctx, existing.Images, product.Images)
+
+	// Delete old variant photos from Cloudinary only if they're not being preserved (best effort)
+	for _, oldVariant := range existing.Variants {
+		if oldVariant.PhotoID != "" && !preservedPhotoIDs[oldVariant.PhotoID] {
+			_ = s.cloudinaryService.DeleteImage(ctx, oldVariant.PhotoID)
+		}
+	}
+
 	return nil
 }
 
@@ -351,13 +514,16 @@ func (s *ProductService) Delete(ctx context.Context, id int) error {
 		}
 	}
 
+	// Delete gallery images
+	s.deleteGalleryImages(ctx, product.Images, nil)
+
 	return nil
 }
 
 // Search searches products by query
-func (s *ProductService) Search(ctx context.Context, query string) ([]models.Product, error) {
+func (s *ProductService) Search(ctx context.Context, query string) (*SearchResult, error) {
 	if query == "" {
-		return []models.Product{}, nil
+		return &SearchResult{Products: []models.Product{}}, nil
 	}
 
 	products, err := s.productRepo.Search(query)
@@ -365,9 +531,388 @@ func (s *ProductService) Search(ctx context.Context, query string) ([]models.Pro
 		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 
+	// Typos defeat full-text search; fall back to trigram similarity
+	fuzzy := len(products) == 0
+	if fuzzy {
+		products, err = s.productRepo.SearchSimilar(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search products: %w", err)
+		}
+	}
+
+	if err := s.applyRequest(ctx, products); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{Products: products}
+	if fuzzy || len(products) < weakSearchResults {
+		if result.Suggestion, err = s.suggest(query); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Suggest lists up to limit products for search-as-you-type, with live prices
+func (s *ProductService) Suggest(ctx context.Context, text string, limit int) ([]models.Product, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []models.Product{}, nil
+	}
+
+	products, err := s.productRepo.FindByPrefix(text, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(ctx, products); err != nil {
+		return nil, err
+	}
+
 	return products, nil
 }
 
+// suggest corrects each word of a search towards the words used in product titles and
+// category names. It returns "" when nothing would change.
+func (s *ProductService) suggest(query string) (string, error) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	s.refreshVocabulary()
+	suggested, err := s.productRepo.SuggestWords(words)
+	if err != nil {
+		return "", err
+	}
+
+	suggestion := strings.Join(suggested, " ")
+	if suggestion == strings.Join(words, " ") {
+		return "", nil
+	}
+	return suggestion, nil
+}
+
+// refreshVocabulary rebuilds the suggestion word list in the background once it is
+// older than vocabularyMaxAge. Searches meanwhile use the previous list.
+func (s *ProductService) refreshVocabulary() {
+	s.vocabularyMu.Lock()
+	defer s.vocabularyMu.Unlock()
+	if s.vocabularyRefreshing || time.Since(s.vocabularyRefreshed) < vocabularyMaxAge {
+		return
+	}
+	s.vocabularyRefreshing = true
+
+	go func() {
+		if err := s.productRepo.RefreshVocabulary(); err != nil {
+			log.Printf("ERROR: Search suggestions keep the previous word list: %v", err)
+		}
+
+		// A failed refresh also waits out vocabularyMaxAge before the next attempt
+		s.vocabularyMu.Lock()
+		defer s.vocabularyMu.Unlock()
+		s.vocabularyRefreshing = false
+		s.vocabularyRefreshed = time.Now()
+	}()
+}
+
+// RefreshPrices recomputes the stored current price range of every product, publicly
+// and per customer group, with the same pricing the cards use. Call it after prices,
+// promotions, customer groups or the category tree change. It is best effort: a
+// failure is logged and the next listing retries.
+func (s *ProductService) RefreshPrices() {
+	s.pricesMu.Lock()
+	defer s.pricesMu.Unlock()
+	s.refreshPrices()
+}
+
+// ensurePrices refreshes the stored current prices when a promotion has started or
+// ended since the last refresh, or when they are older than pricesMaxAge
+func (s *ProductService) ensurePrices() {
+	s.pricesMu.Lock()
+	defer s.pricesMu.Unlock()
+	if time.Now().Before(s.pricesValidUntil) {
+		return
+	}
+	s.refreshPrices()
+}
+
+// refreshPrices does the work of RefreshPrices; the caller holds pricesMu
+func (s *ProductService) refreshPrices() {
+	validUntil, err := s.storeCurrentPrices()
+	if err != nil {
+		log.Printf("ERROR: Price filters keep the previous prices: %v", err)
+		validUntil = time.Now().Add(time.Minute)
+	}
+	s.pricesValidUntil = validUntil
+}
+
+// storeCurrentPrices prices every product, publicly and for each customer group, and
+// stores the ranges, returning how long they stay valid
+func (s *ProductService) storeCurrentPrices() (time.Time, error) {
+	// Read the next change first so a promotion starting meanwhile triggers a new refresh
+	validUntil := time.Now().Add(pricesMaxAge)
+	next, err := s.promotionRepo.NextChange()
+	if err != nil {
+		return validUntil, err
+	}
+	if next != nil && storeTime(*next).Before(validUntil) {
+		validUntil = storeTime(*next)
+	}
+
+	products, err := s.productRepo.FindForPricing()
+	if err != nil {
+		return validUntil, err
+	}
+	if err := s.applyPromotions(products); err != nil {
+		return validUntil, err
+	}
+
+	groups, err := s.groupRepo.FindAll()
+	if err != nil {
+		return validUntil, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return validUntil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ranges := make([]repositories.PriceRange, len(products))
+	for i := range products {
+		low, high := products[i].PriceRange()
+		ranges[i] = repositories.PriceRange{ProductID: products[i].ID, Min: low, Max: high}
+	}
+	if err := s.productRepo.UpdateCurrentPrices(tx, ranges); err != nil {
+		return validUntil, err
+	}
+
+	// Groups only store the products they see at other prices than the public
+	for i := range groups {
+		priced := make([]models.Product, len(products))
+		for j := range products {
+			priced[j] = products[j]
+			priced[j].Variants = append([]models.ProductVariant(nil), products[j].Variants...)
+		}
+		if err := s.applyGroup(&groups[i], priced); err != nil {
+			return validUntil, err
+		}
+
+		var groupRanges []repositories.PriceRange
+		for j := range priced {
+			low, high := priced[j].PriceRange()
+			if low != ranges[j].Min || high != ranges[j].Max {
+				groupRanges = append(groupRanges, repositories.PriceRange{ProductID: priced[j].ID, Min: low, Max: high})
+			}
+		}
+		if err := s.groupRepo.ReplacePriceRanges(tx, groups[i].ID, groupRanges); err != nil {
+			return validUntil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return validUntil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return validUntil, nil
+}
+
+// applyRequest prices and translates products for the request in ctx
+func (s *ProductService) applyRequest(ctx context.Context, products []models.Product) error {
+	if err := s.applyPricing(ctx, products); err != nil {
+		return err
+	}
+	return s.applyTranslations(ctx, products)
+}
+
+// applyPricing prices products for the request: live promotions for everyone, then
+// the customer group prices of a signed-in reseller carried by ctx
+func (s *ProductService) applyPricing(ctx context.Context, products []models.Product) error {
+	if err := s.applyPromotions(products); err != nil {
+		return err
+	}
+	return s.applyGroupPrices(ctx, products)
+}
+
+// applyPromotions attaches the best live promotion to each product and variant.
+// Product and category targets discount every variant; variant targets only that variant.
+func (s *ProductService) applyPromotions(products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	live, err := s.promotionRepo.FindLive()
+	if err != nil {
+		return fmt.Errorf("failed to load promotions: %w", err)
+	}
+	if len(live) == 0 {
+		return nil
+	}
+
+	byProduct := make(map[int][]*models.Promotion)
+	byVariant := make(map[int][]*models.Promotion)
+	byCategory := make(map[int][]*models.Promotion)
+	for i := range live {
+		promo := &live[i]
+		for _, target := range promo.Targets {
+			switch {
+			case target.ProductID != nil:
+				byProduct[*target.ProductID] = append(byProduct[*target.ProductID], promo)
+			case target.VariantID != nil:
+				byVariant[*target.VariantID] = append(byVariant[*target.VariantID], promo)
+			case target.CategoryID != nil:
+				for _, categoryID := range target.CategorySubtree {
+					byCategory[int(categoryID)] = append(byCategory[int(categoryID)], promo)
+				}
+			}
+		}
+	}
+
+	for i := range products {
+		product := &products[i]
+		candidates := append([]*models.Promotion{}, byProduct[product.ID]...)
+		if product.CategoryID != nil {
+			candidates = append(candidates, byCategory[*product.CategoryID]...)
+		}
+		product.Promotion = models.BestPromotion(product.BasePrice, candidates)
+
+		for j := range product.Variants {
+			variant := &product.Variants[j]
+			variantCandidates := append(append([]*models.Promotion{}, candidates...), byVariant[variant.ID]...)
+			variant.Promotion = models.BestPromotion(variant.RegularPrice(product.BasePrice), variantCandidates)
+		}
+	}
+
+	return nil
+}
+
+// applyGroupPrices gives each product and variant the price of the customer group in
+// ctx: its explicit variant price, otherwise the group discount off the regular price.
+// A group price only shows when it beats the public price; it then replaces the
+// promotion and hides wholesale tiers that are no cheaper.
+func (s *ProductService) applyGroupPrices(ctx context.Context, products []models.Product) error {
+	group := customerGroupFrom(ctx)
+	if group == nil {
+		return nil
+	}
+	return s.applyGroup(group, products)
+}
+
+// applyGroup prices products for one customer group (see applyGroupPrices)
+func (s *ProductService) applyGroup(group *models.CustomerGroup, products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	variantIDs := []int{}
+	for i := range products {
+		for j := range products[i].Variants {
+			variantIDs = append(variantIDs, products[i].Variants[j].ID)
+		}
+	}
+	explicit, err := s.groupRepo.FindPrices(group.ID, variantIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		product := &products[i]
+		if group.DiscountPercent > 0 {
+			if price := group.Apply(product.BasePrice); price < product.FinalPrice() {
+				product.GroupPrice = &price
+				product.Promotion = nil
+			}
+		}
+
+		for j := range product.Variants {
+			variant := &product.Variants[j]
+			price, ok := explicit[variant.ID]
+			if !ok {
+				if group.DiscountPercent == 0 {
+					continue
+				}
+				price = group.Apply(variant.RegularPrice(product.BasePrice))
+			}
+			if price >= variant.FinalPrice(product.BasePrice) {
+				continue
+			}
+
+			variant.GroupPrice = &price
+			variant.Promotion = nil
+			tiers := []models.PriceTier{}
+			for _, tier := range variant.PriceTiers {
+				if tier.UnitPrice < price {
+					tiers = append(tiers, tier)
+				}
+			}
+			variant.PriceTiers = tiers
+		}
+	}
+
+	return nil
+}
+
+// applyTranslations replaces the title and description of products with their
+// translation into the storefront language in ctx, where one was entered
+func (s *ProductService) applyTranslations(ctx context.Context, products []models.Product) error {
+	locale := i18n.FromContext(ctx)
+	if locale == i18n.Default || len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	translations, err := s.productRepo.FindTranslations(ids, string(locale))
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		translation, ok := translations[products[i].ID]
+		if !ok {
+			continue
+		}
+		if translation.Title != "" {
+			products[i].Title = translation.Title
+		}
+		if translation.Description != "" {
+			products[i].Description = translation.Description
+		}
+	}
+
+	return nil
+}
+
+// GetTranslations retrieves a product's text in the other storefront languages, for the admin form
+func (s *ProductService) GetTranslations(ctx context.Context, id int) ([]models.ProductTranslation, error) {
+	return s.productRepo.FindTranslationsByProduct(id)
+}
+
+// uniqueSlug derives a slug from title that no other product uses or used before,
+// appending -2, -3, ... on collision
+func (s *ProductService) uniqueSlug(title string, excludeID int) (string, error) {
+	base := utils.GenerateSlug(title)
+	if base == "" {
+		base = "produk"
+	}
+
+	slug := base
+	for n := 2; ; n++ {
+		taken, err := s.productRepo.SlugTaken(slug, excludeID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
 // validateProduct validates product data (no validation on code — freetext)
 func (s *ProductService) validateProduct(product *models.Product) error {
 	// Validate title
@@ -389,5 +934,141 @@ func (s *ProductService) validateProduct(product *models.Product) error {
 		return errors.New("base price must not exceed 99,999,999.99")
 	}
 
+	// Validate wholesale price tiers
+	for i := range product.Variants {
+		if err := s.validatePriceTiers(product, &product.Variants[i]); err != nil {
+			return err
+		}
+	}
+
+	// Validate translations; blank ones are dropped when saved
+	for _, translation := range product.Translations {
+		if len(translation.Title) > 200 {
+			return errors.New("translated title must not exceed 200 characters")
+		}
+	}
+
+	// Validate gallery
+	if len(product.Images) > maxProductImages {
+		return fmt.Errorf("a product can have at most %d gallery images", maxProductImages)
+	}
+	for _, image := range product.Images {
+		if image.ImageURL == "" || image.ImageID == "" {
+			return errors.New("gallery image is missing its upload")
+		}
+		if len(image.AltText) > 200 {
+			return errors.New("image alt text must not exceed 200 characters")
+		}
+	}
+
+	return nil
+}
+
+// resolveVariantColors links each variant to a palette colour: a chosen colour must
+// exist, otherwise the label is matched against palette names and aliases.
+// Unmatched labels stay unlinked and show as text on the product page.
+func (s *ProductService) resolveVariantColors(product *models.Product) error {
+	if len(product.Variants) == 0 {
+		return nil
+	}
+
+	colors, err := s.colorRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		if variant.ColorID != nil {
+			found := false
+			for _, color := range colors {
+				if color.ID == *variant.ColorID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("variant %s: palette color not found", variant.Color)
+			}
+			continue
+		}
+		for _, color := range colors {
+			if color.Matches(variant.Color) {
+				id := color.ID
+				variant.ColorID = &id
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveImageVariants sorts the gallery, renumbers sort_order from zero and
+// links each image to the variant named by its VariantColor
+func (s *ProductService) resolveImageVariants(product *models.Product) error {
+	sort.SliceStable(product.Images, func(i, j int) bool {
+		return product.Images[i].SortOrder < product.Images[j].SortOrder
+	})
+
+	variantIDs := make(map[string]int, len(product.Variants))
+	for _, variant := range product.Variants {
+		variantIDs[variant.Color] = variant.ID
+	}
+
+	for i := range product.Images {
+		image := &product.Images[i]
+		image.SortOrder = i
+		image.VariantID = nil
+		if image.VariantColor == "" {
+			continue
+		}
+		id, ok := variantIDs[image.VariantColor]
+		if !ok {
+			return fmt.Errorf("gallery image is linked to unknown variant %s", image.VariantColor)
+		}
+		image.VariantID = &id
+	}
+
+	return nil
+}
+
+// deleteGalleryImages removes the Cloudinary assets of images that are not in keep (best effort)
+func (s *ProductService) deleteGalleryImages(ctx context.Context, images, keep []models.ProductImage) {
+	kept := make(map[string]bool, len(keep))
+	for _, image := range keep {
+		kept[image.ImageID] = true
+	}
+	for _, image := range images {
+		if image.ImageID != "" && !kept[image.ImageID] {
+			_ = s.cloudinaryService.DeleteImage(ctx, image.ImageID)
+		}
+	}
+}
+
+// validatePriceTiers sorts a variant's price tiers by minimum quantity and checks
+// that every tier is cheaper per unit than the one before it
+func (s *ProductService) validatePriceTiers(product *models.Product, variant *models.ProductVariant) error {
+	sort.Slice(variant.PriceTiers, func(i, j int) bool {
+		return variant.PriceTiers[i].MinQty < variant.PriceTiers[j].MinQty
+	})
+
+	previousPrice := variant.RegularPrice(product.BasePrice)
+	for i, tier := range variant.PriceTiers {
+		if tier.MinQty < 2 {
+			return fmt.Errorf("variant %s: tier minimum quantity must be at least 2", variant.Color)
+		}
+		if i > 0 && tier.MinQty == variant.PriceTiers[i-1].MinQty {
+			return fmt.Errorf("variant %s: duplicate tier for %d pcs", variant.Color, tier.MinQty)
+		}
+		if tier.UnitPrice < 0.01 || tier.UnitPrice > 99999999.99 {
+			return fmt.Errorf("variant %s: tier price for %d+ pcs is out of range", variant.Color, tier.MinQty)
+		}
+		if tier.UnitPrice >= previousPrice {
+			return fmt.Errorf("variant %s: tier price for %d+ pcs must be lower than the price for fewer pcs", variant.Color, tier.MinQty)
+		}
+		previousPrice = tier.UnitPrice
+	}
+
 	return nil
 }
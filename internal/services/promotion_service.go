@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// PromotionService handles promotion business logic
+type PromotionService struct {
+	promotionRepo  *repositories.PromotionRepository
+	productService *ProductService
+	db             *sqlx.DB
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(promotionRepo *repositories.PromotionRepository, productService *ProductService, db *sqlx.DB) *PromotionService {
+	return &PromotionService{
+		promotionRepo:  promotionRepo,
+		productService: productService,
+		db:             db,
+	}
+}
+
+// GetAll retrieves all promotions with their targets
+func (s *PromotionService) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return s.promotionRepo.FindAll()
+}
+
+// GetByID retrieves a promotion by ID
+func (s *PromotionService) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid promotion ID")
+	}
+
+	promotion, err := s.promotionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, fmt.Errorf("failed to fetch promotion: %w", err)
+	}
+
+	return promotion, nil
+}
+
+// GetTargetOptions lists the products and variants a promotion can target
+func (s *PromotionService) GetTargetOptions(ctx context.Context) ([]repositories.PromotionTargetOption, error) {
+	return s.promotionRepo.FindTargetOptions()
+}
+
+// Create creates a promotion with its targets
+func (s *PromotionService) Create(ctx context.Context, promotion *models.Promotion) error {
+	if err := s.validatePromotion(promotion); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.promotionRepo.Create(tx, promotion); err != nil {
+		return err
+	}
+	if err := s.promotionRepo.ReplaceTargets(tx, promotion.ID, promotion.Targets); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.productService.RefreshPrices()
+	return nil
+}
+
+// Update updates a promotion and replaces its targets
+func (s *PromotionService) Update(ctx context.Context, id int, promotion *models.Promotion) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	promotion.ID = id
+	if err := s.validatePromotion(promotion); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.promotionRepo.Update(tx, promotion); err != nil {
+		return err
+	}
+	if err := s.promotionRepo.ReplaceTargets(tx, id, promotion.Targets); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.productService.RefreshPrices()
+	return nil
+}
+
+// Delete deletes a promotion
+func (s *PromotionService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return errors.New("invalid promotion ID")
+	}
+	if err := s.promotionRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.productService.RefreshPrices()
+	return nil
+}
+
+// validatePromotion validates promotion data
+func (s *PromotionService) validatePromotion(promotion *models.Promotion) error {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	if len(promotion.Name) < 3 {
+		return errors.New("promotion name must be at least 3 characters")
+	}
+	if len(promotion.Name) > 100 {
+		return errors.New("promotion name must not exceed 100 characters")
+	}
+
+	switch promotion.DiscountType {
+	case models.DiscountPercent:
+		if promotion.DiscountValue <= 0 || promotion.DiscountValue > 100 {
+			return errors.New("percent discount must be between 0 and 100")
+		}
+	case models.DiscountFixed:
+		if promotion.DiscountValue < 0.01 || promotion.DiscountValue > 99999999.99 {
+			return errors.New("fixed discount must be between 0.01 and 99,999,999.99")
+		}
+	default:
+		return errors.New("discount type must be percent or fixed")
+	}
+
+	if promotion.StartsAt.IsZero() || promotion.EndsAt.IsZero() {
+		return errors.New("promotion start and end time are required")
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		return errors.New("promotion must end after it starts")
+	}
+
+	if len(promotion.Targets) == 0 {
+		return errors.New("promotion must target at least one product, variant or category")
+	}
+
+	return nil
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+const (
+	// minReviewBody and maxReviewBody bound the length of a review text
+	minReviewBody = 10
+	maxReviewBody = 2000
+)
+
+// Review errors shown to visitors; handlers translate them
+var (
+	ErrReviewInvalidName     = errors.New("name must be between 2 and 100 characters")
+	ErrReviewCityTooLong     = errors.New("city must not exceed 100 characters")
+	ErrReviewInvalidRating   = errors.New("rating must be between 1 and 5")
+	ErrReviewInvalidBody     = errors.New("review must be between 10 and 2000 characters")
+	ErrReviewTooManyPhotos   = errors.New("too many review photos")
+	ErrReviewInvalidPhoto    = errors.New("invalid review photo")
+	ErrReviewProductNotFound = errors.New("product not found")
+	ErrReviewInvalidStatus   = errors.New("invalid review status")
+	ErrReviewNotFound        = errors.New("review not found")
+)
+
+// ReviewSubmission is a review sent from the product page
+type ReviewSubmission struct {
+	ProductID  int
+	AuthorName string
+	City       string
+	Rating     int
+	Body       string
+	PhotoURLs  []string // Secure URLs returned by the direct upload
+	PhotoIDs   []string // Public IDs, in the same order as PhotoURLs
+}
+
+// ReviewService handles product reviews: submissions from the public site, the
+// admin moderation queue and the product ratings computed from approved reviews
+type ReviewService struct {
+	reviewRepo        *repositories.ReviewRepository
+	cloudinaryService *CloudinaryService
+	db                *sqlx.DB
+}
+
+// NewReviewService creates a new review service
+func NewReviewService(reviewRepo *repositories.ReviewRepository, cloudinaryService *CloudinaryService, db *sqlx.DB) *ReviewService {
+	return &ReviewService{
+		reviewRepo:        reviewRepo,
+		cloudinaryService: cloudinaryService,
+		db:                db,
+	}
+}
+
+// Submit stores a review in the moderation queue. Reviews written while signed in
+// as a reseller are linked to the account.
+func (s *ReviewService) Submit(ctx context.Context, input ReviewSubmission, ipAddress string) (*models.Review, error) {
+	review := &models.Review{
+		ProductID:  input.ProductID,
+		AuthorName: strings.TrimSpace(input.AuthorName),
+		City:       strings.TrimSpace(input.City),
+		Rating:     input.Rating,
+		Body:       strings.TrimSpace(input.Body),
+		Status:     models.ReviewPending,
+		IPAddress:  ipAddress,
+	}
+	if customer := customerFrom(ctx); customer != nil {
+		review.CustomerID = &customer.ID
+	}
+
+	nameLength := utf8.RuneCountInString(review.AuthorName)
+	if nameLength < 2 || nameLength > 100 {
+		return nil, ErrReviewInvalidName
+	}
+	if utf8.RuneCountInString(review.City) > 100 {
+		return nil, ErrReviewCityTooLong
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return nil, ErrReviewInvalidRating
+	}
+	bodyLength := utf8.RuneCountInString(review.Body)
+	if bodyLength < minReviewBody || bodyLength > maxReviewBody {
+		return nil, ErrReviewInvalidBody
+	}
+
+	photos, err := s.reviewPhotos(input.PhotoURLs, input.PhotoIDs)
+	if err != nil {
+		return nil, err
+	}
+	review.Photos = photos
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.reviewRepo.Create(tx, review); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+			return nil, ErrReviewProductNotFound
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return review, nil
+}
+
+// GetApproved retrieves the newest approved reviews of a product
+func (s *ReviewService) GetApproved(ctx context.Context, productID, limit int) ([]models.Review, error) {
+	return s.reviewRepo.FindApprovedByProduct(productID, limit)
+}
+
+// GetAll retrieves reviews for the moderation queue with filtering and pagination
+func (s *ReviewService) GetAll(ctx context.Context, filters repositories.ReviewFilters) (*repositories.ReviewListResult, error) {
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.PageSize < 1 {
+		filters.PageSize = 20
+	}
+	if filters.PageSize > 100 {
+		filters.PageSize = 100
+	}
+	if filters.Status != "" && !models.ReviewStatus(filters.Status).IsValid() {
+		filters.Status = ""
+	}
+
+	return s.reviewRepo.FindAll(filters)
+}
+
+// CountByStatus returns the number of reviews in each status
+func (s *ReviewService) CountByStatus(ctx context.Context) (map[models.ReviewStatus]int, error) {
+	return s.reviewRepo.CountByStatus()
+}
+
+// GetByID retrieves a review by ID
+func (s *ReviewService) GetByID(ctx context.Context, id int) (*models.Review, error) {
+	if id <= 0 {
+		return nil, ErrReviewNotFound
+	}
+
+	review, err := s.reviewRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	}
+
+	return review, nil
+}
+
+// Moderate sets a review's status and refreshes the product rating, which only
+// counts approved reviews
+func (s *ReviewService) Moderate(ctx context.Context, id int, status models.ReviewStatus) (*models.Review, error) {
+	if !status.IsValid() {
+		return nil, ErrReviewInvalidStatus
+	}
+
+	review, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	approvedAt := review.ApprovedAt
+	if status == models.ReviewApproved && approvedAt == nil {
+		now := time.Now()
+		approvedAt = &now
+	}
+	if status != models.ReviewApproved {
+		approvedAt = nil
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.reviewRepo.UpdateStatus(tx, id, status, approvedAt); err != nil {
+		return nil, err
+	}
+	if err := s.reviewRepo.RefreshProductRating(tx, review.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	review.Status = status
+	review.ApprovedAt = approvedAt
+	return review, nil
+}
+
+// Delete removes a review, refreshes the product rating and deletes its photos
+// from Cloudinary
+func (s *ReviewService) Delete(ctx context.Context, id int) error {
+	review, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.reviewRepo.Delete(tx, id); err != nil {
+		return err
+	}
+	if err := s.reviewRepo.RefreshProductRating(tx, review.ProductID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Best effort: an orphaned image only costs storage
+	for _, photo := range review.Photos {
+		_ = s.cloudinaryService.DeleteImage(ctx, photo.ImageID)
+	}
+
+	return nil
+}
+
+// reviewPhotos checks the direct-upload results sent with a review
+func (s *ReviewService) reviewPhotos(urls, ids []string) ([]models.ReviewPhoto, error) {
+	if len(urls) != len(ids) {
+		return nil, ErrReviewInvalidPhoto
+	}
+	if len(urls) > models.MaxReviewPhotos {
+		return nil, ErrReviewTooManyPhotos
+	}
+
+	photos := make([]models.ReviewPhoto, 0, len(urls))
+	for i := range urls {
+		imageURL := strings.TrimSpace(urls[i])
+		imageID := strings.TrimSpace(ids[i])
+		if err := s.cloudinaryService.ValidateClientUploadResult("review", imageURL, imageID); err != nil {
+			return nil, ErrReviewInvalidPhoto
+		}
+		photos = append(photos, models.ReviewPhoto{ImageURL: imageURL, ImageID: imageID})
+	}
+
+	return photos, nil
+}
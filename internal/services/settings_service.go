@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// settingsRetryDelay is how long the defaults stand in after the settings failed
+// to load, so a database outage does not cost every page a query
+const settingsRetryDelay = 30 * time.Second
+
+// SettingsService holds the store settings. They are read on every page, so the
+// service keeps them in memory and reloads them after a save.
+type SettingsService struct {
+	settingsRepo *repositories.SettingsRepository
+	db           *sqlx.DB
+	defaults     models.StoreSettings
+
+	mu          sync.RWMutex
+	cached      *models.StoreSettings
+	cachedUntil time.Time // Zero keeps the cached settings until the next save
+	generation  int       // Bumped on save, so a load that raced the save is not cached
+}
+
+// NewSettingsService creates a new settings service. defaults, from the
+// environment, fill in settings that were never saved.
+func NewSettingsService(settingsRepo *repositories.SettingsRepository, db *sqlx.DB, defaults models.StoreSettings) *SettingsService {
+	return &SettingsService{
+		settingsRepo: settingsRepo,
+		db:           db,
+		defaults:     defaults,
+	}
+}
+
+// Seed stores the defaults for settings that have no row yet. Run at boot, it
+// makes the environment values the first saved settings and ignores them after.
+func (s *SettingsService) Seed() error {
+	return s.settingsRepo.InsertMissing(s.defaults.Values())
+}
+
+// Get returns the store settings. Should the database fail, the defaults stand
+// in for settingsRetryDelay so pages still render.
+func (s *SettingsService) Get() models.StoreSettings {
+	s.mu.RLock()
+	cached, until, generation := s.cached, s.cachedUntil, s.generation
+	s.mu.RUnlock()
+	if cached != nil && (until.IsZero() || time.Now().Before(until)) {
+		return *cached
+	}
+
+	// Loaded outside the lock: a slow database must not hold up the other readers
+	settings := s.defaults
+	until = time.Time{}
+	values, err := s.settingsRepo.FindAll()
+	if err != nil {
+		log.Printf("ERROR: Failed to load store settings: %v", err)
+		until = time.Now().Add(settingsRetryDelay)
+	} else {
+		settings = models.StoreSettingsFrom(values)
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cached = &settings
+		s.cachedUntil = until
+	}
+	s.mu.Unlock()
+	return settings
+}
+
+// Update validates and saves the store settings, then drops the cached copy
+func (s *SettingsService) Update(ctx context.Context, settings *models.StoreSettings) error {
+	if err := s.validate(settings); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.settingsRepo.Save(tx, settings.Values()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit settings: %w", err)
+	}
+
+	s.mu.Lock()
+	s.cached = nil
+	s.generation++
+	s.mu.Unlock()
+	return nil
+}
+
+// validate trims the settings and checks them; the WhatsApp number is required,
+// as every order ends in a chat, and stored in international format so wa.me links work
+func (s *SettingsService) validate(settings *models.StoreSettings) error {
+	settings.Name = strings.TrimSpace(settings.Name)
+	settings.Address = strings.TrimSpace(settings.Address)
+	settings.ShopeeLink = strings.TrimSpace(settings.ShopeeLink)
+	settings.TiktokLink = strings.TrimSpace(settings.TiktokLink)
+	settings.InstagramLink = strings.TrimSpace(settings.InstagramLink)
+
+	if n := utf8.RuneCountInString(settings.Name); n < 2 || n > 100 {
+		return errors.New("store name must be between 2 and 100 characters")
+	}
+	if utf8.RuneCountInString(settings.Address) > 500 {
+		return errors.New("store address must not exceed 500 characters")
+	}
+
+	if strings.TrimSpace(settings.WhatsAppNumber) == "" {
+		return errors.New("WhatsApp number is required")
+	}
+	settings.WhatsAppNumber = normalizePhone(settings.WhatsAppNumber)
+	if len(settings.WhatsAppNumber) < 10 || len(settings.WhatsAppNumber) > 15 {
+		return errors.New("invalid WhatsApp number")
+	}
+
+	for _, link := range []struct{ name, url string }{
+		{"Shopee", settings.ShopeeLink},
+		{"TikTok", settings.TiktokLink},
+		{"Instagram", settings.InstagramLink},
+	} {
+		if link.url == "" {
+			continue
+		}
+		parsed, err := url.Parse(link.url)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s link must be a full http(s) URL", link.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+)
+
+// SitemapPageSize is the number of product URLs in one sitemap file. Search engines
+// accept up to 50,000; each URL also lists its pages in the other languages.
+const SitemapPageSize = 10000
+
+// ErrSitemapPageNotFound is returned for a product sitemap page past the catalog's end
+var ErrSitemapPageNotFound = errors.New("sitemap page not found")
+
+// SitemapEntry is one storefront page listed in a sitemap
+type SitemapEntry struct {
+	Path      string    // Unprefixed storefront path
+	UpdatedAt time.Time // Zero when unknown
+}
+
+// SitemapService lists the storefront pages for search engines
+type SitemapService struct {
+	productRepo  *repositories.ProductRepository
+	categoryRepo *repositories.CategoryRepository
+}
+
+// NewSitemapService creates a new sitemap service
+func NewSitemapService(productRepo *repositories.ProductRepository, categoryRepo *repositories.CategoryRepository) *SitemapService {
+	return &SitemapService{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// ProductPages returns the number of product sitemap pages
+func (s *SitemapService) ProductPages() (int, error) {
+	count, err := s.productRepo.Count()
+	if err != nil {
+		return 0, err
+	}
+	return (count + SitemapPageSize - 1) / SitemapPageSize, nil
+}
+
+// Pages lists the landing page and every category page
+func (s *SitemapService) Pages() ([]SitemapEntry, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]SitemapEntry, 0, len(categories)+1)
+	entries = append(entries, SitemapEntry{Path: "/"})
+	for _, category := range categories {
+		entries = append(entries, SitemapEntry{Path: category.URL(), UpdatedAt: category.UpdatedAt})
+	}
+	return entries, nil
+}
+
+// Products lists the product pages on sitemap page (1-based)
+func (s *SitemapService) Products(page int) ([]SitemapEntry, error) {
+	if page < 1 {
+		return nil, ErrSitemapPageNotFound
+	}
+
+	products, err := s.productRepo.FindSitemapPage(SitemapPageSize, (page-1)*SitemapPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 && page > 1 {
+		return nil, ErrSitemapPageNotFound
+	}
+
+	entries := make([]SitemapEntry, 0, len(products))
+	for _, product := range products {
+		entries = append(entries, SitemapEntry{Path: product.URL(), UpdatedAt: product.UpdatedAt})
+	}
+	return entries, nil
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rizkysr90/aslam-flower/internal/models"
+	"github.com/rizkysr90/aslam-flower/internal/repositories"
+	"github.com/rizkysr90/aslam-flower/internal/utils"
+)
+
+// VisitorLifetime is how long an anonymous visitor's wishlist and recently viewed
+// products are kept after their last activity
+const VisitorLifetime = 180 * 24 * time.Hour
+
+// ErrWishlistProductNotFound is returned for products that do not exist (any more)
+var ErrWishlistProductNotFound = errors.New("product not found")
+
+// WishlistService handles the shopper's wishlist and recently viewed products.
+// Signed-in resellers keep them on their account; everyone else keeps them on an
+// anonymous visitor, identified by a cookie token, until they sign in.
+type WishlistService struct {
+	visitorRepo     *repositories.VisitorRepository
+	wishlistRepo    *repositories.WishlistRepository
+	productService  *ProductService
+	db              *sqlx.DB
+	settingsService *SettingsService
+}
+
+// NewWishlistService creates a new wishlist service
+func NewWishlistService(visitorRepo *repositories.VisitorRepository, wishlistRepo *repositories.WishlistRepository, productService *ProductService, db *sqlx.DB, settingsService *SettingsService) *WishlistService {
+	return &WishlistService{
+		visitorRepo:     visitorRepo,
+		wishlistRepo:    wishlistRepo,
+		productService:  productService,
+		db:              db,
+		settingsService: settingsService,
+	}
+}
+
+// Products retrieves the priced products on the wishlist, newest first
+func (s *WishlistService) Products(ctx context.Context, token string) ([]models.Product, error) {
+	shopper, err := s.shopper(ctx, token)
+	if err != nil || shopper.IsZero() {
+		return []models.Product{}, err
+	}
+
+	ids, err := s.wishlistRepo.FindProductIDs(shopper)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.products(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].Wishlisted = true
+	}
+	return products, nil
+}
+
+// IDs returns the set of product IDs on the wishlist, to mark catalog cards
+func (s *WishlistService) IDs(ctx context.Context, token string) (map[int]bool, error) {
+	shopper, err := s.shopper(ctx, token)
+	if err != nil || shopper.IsZero() {
+		return map[int]bool{}, err
+	}
+
+	ids, err := s.wishlistRepo.FindProductIDs(shopper)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, nil
+}
+
+// Toggle adds a product to the wishlist, or removes it when it is already there.
+// It returns the visitor token to keep in the cookie and whether the product is
+// now on the wishlist.
+func (s *WishlistService) Toggle(ctx context.Context, token string, productID int) (string, bool, error) {
+	shopper, token, err := s.activeShopper(ctx, token)
+	if err != nil {
+		return token, false, err
+	}
+
+	removed, err := s.wishlistRepo.Remove(shopper, productID)
+	if err != nil || removed {
+		return token, false, err
+	}
+
+	if err := s.wishlistRepo.Add(shopper, productID); err != nil {
+		return token, false, wishlistProductError(err)
+	}
+	return token, true, nil
+}
+
+// RecordView puts a product at the front of the recently viewed list, keeping the
+// last models.MaxRecentlyViewed. It returns the visitor token to keep in the cookie.
+func (s *WishlistService) RecordView(ctx context.Context, token string, productID int) (string, error) {
+	shopper, token, err := s.activeShopper(ctx, token)
+	if err != nil {
+		return token, err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return token, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.wishlistRepo.RecordView(tx, shopper, productID); err != nil {
+		return token, wishlistProductError(err)
+	}
+	if err := s.wishlistRepo.TrimViews(tx, shopper, models.MaxRecentlyViewed); err != nil {
+		return token, err
+	}
+	if err := tx.Commit(); err != nil {
+		return token, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return token, nil
+}
+
+// RecentlyViewed retrieves the priced products viewed most recently, leaving out
+// excludeID (the product being viewed)
+func (s *WishlistService) RecentlyViewed(ctx context.Context, token string, excludeID int) ([]models.Product, error) {
+	shopper, err := s.shopper(ctx, token)
+	if err != nil || shopper.IsZero() {
+		return []models.Product{}, err
+	}
+
+	ids, err := s.wishlistRepo.FindViewedProductIDs(shopper, models.MaxRecentlyViewed)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := ids[:0]
+	for _, id := range ids {
+		if id != excludeID {
+			kept = append(kept, id)
+		}
+	}
+
+	return s.products(ctx, kept)
+}
+
+// Merge moves the lists of the visitor with token to a customer who just signed in
+func (s *WishlistService) Merge(ctx context.Context, token string, customerID int) error {
+	visitor, err := s.findVisitor(token)
+	if err != nil || visitor == nil {
+		return err
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.wishlistRepo.Merge(tx, visitor.ID, customerID); err != nil {
+		return err
+	}
+	if err := s.wishlistRepo.TrimViews(tx, models.Shopper{CustomerID: customerID}, models.MaxRecentlyViewed); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ShareMessage lists the wishlist products with their code, price and link, for
+// the shopper to send to a friend or to the store. baseURL prefixes the links.
+func (s *WishlistService) ShareMessage(products []models.Product, baseURL string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Wishlist saya di %s:\n", s.settingsService.Get().Name)
+
+	for i := range products {
+		product := &products[i]
+		fmt.Fprintf(&b, "\n%d. %s\n", i+1, product.Title)
+		fmt.Fprintf(&b, "   Kode: %s\n", product.Code)
+		if low, high := product.MinFinalPrice(), product.MaxFinalPrice(); low != high {
+			fmt.Fprintf(&b, "   %s - %s\n", utils.FormatRupiah(low), utils.FormatRupiah(high))
+		} else {
+			fmt.Fprintf(&b, "   %s\n", utils.FormatRupiah(low))
+		}
+		fmt.Fprintf(&b, "   %s%s\n", baseURL, product.URL())
+	}
+
+	return b.String()
+}
+
+// ShareURL returns the wa.me link that lets the shopper pick a chat to send the
+// wishlist to
+func (s *WishlistService) ShareURL(products []models.Product, baseURL string) string {
+	return whatsAppURL("", s.ShareMessage(products, baseURL))
+}
+
+// shopper returns who owns the lists for this request: the signed-in customer, or
+// the visitor with token. It is zero for a visitor without lists.
+func (s *WishlistService) shopper(ctx context.Context, token string) (models.Shopper, error) {
+	if customer := customerFrom(ctx); customer != nil {
+		return models.Shopper{CustomerID: customer.ID}, nil
+	}
+
+	visitor, err := s.findVisitor(token)
+	if err != nil || visitor == nil {
+		return models.Shopper{}, err
+	}
+	return models.Shopper{VisitorID: visitor.ID}, nil
+}
+
+// activeShopper is shopper for a change to the lists: it creates the visitor on
+// their first change and keeps an existing one from expiring. It returns the
+// visitor token to keep in the cookie.
+func (s *WishlistService) activeShopper(ctx context.Context, token string) (models.Shopper, string, error) {
+	if customer := customerFrom(ctx); customer != nil {
+		return models.Shopper{CustomerID: customer.ID}, token, nil
+	}
+
+	visitor, err := s.findVisitor(token)
+	if err != nil {
+		return models.Shopper{}, token, err
+	}
+	if visitor == nil {
+		if visitor, err = s.createVisitor(); err != nil {
+			return models.Shopper{}, token, err
+		}
+	} else if err := s.visitorRepo.Touch(visitor.ID); err != nil {
+		return models.Shopper{}, token, err
+	}
+
+	return models.Shopper{VisitorID: visitor.ID}, visitor.Token, nil
+}
+
+// products retrieves priced products in the order of ids, skipping deleted ones
+func (s *WishlistService) products(ctx context.Context, ids []int) ([]models.Product, error) {
+	if len(ids) == 0 {
+		return []models.Product{}, nil
+	}
+
+	byID, err := s.productService.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]models.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			products = append(products, *product)
+		}
+	}
+	return products, nil
+}
+
+// findVisitor retrieves the visitor for a token, or nil when there is none
+func (s *WishlistService) findVisitor(token string) (*models.Visitor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	visitor, err := s.visitorRepo.FindByToken(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return visitor, err
+}
+
+// createVisitor stores a new visitor with a random token and drops expired ones
+func (s *WishlistService) createVisitor() (*models.Visitor, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate visitor token: %w", err)
+	}
+
+	visitor := &models.Visitor{Token: hex.EncodeToString(raw)}
+	if err := s.visitorRepo.Create(visitor); err != nil {
+		return nil, err
+	}
+
+	// Best effort: expired visitors are cleaned up as new ones arrive
+	_ = s.visitorRepo.DeleteStale(VisitorLifetime)
+
+	return visitor, nil
+}
+
+// wishlistProductError maps a foreign key violation on product_id to ErrWishlistProductNotFound
+func wishlistProductError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+		return ErrWishlistProductNotFound
+	}
+	return err
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in PDF points (1/72 inch)
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+// PDFFont selects the text style. Only the standard Helvetica faces are used, so
+// no font has to be embedded.
+type PDFFont struct {
+	Size float64
+	Bold bool
+	Gray float64 // 0 = black, 1 = white
+}
+
+// PDF is a minimal PDF 1.4 writer for simple text documents such as invoices and
+// quotations: Helvetica text, lines and filled boxes on A4 pages. Coordinates are
+// measured in points from the top-left corner of the page.
+type PDF struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+// NewPDF creates an empty document; call AddPage before drawing
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+// AddPage starts a new page and makes it the drawing target
+func (p *PDF) AddPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+}
+
+// Text draws s with its baseline at y, starting at x
+func (p *PDF) Text(x, y float64, s string, font PDFFont) {
+	name := "F1"
+	if font.Bold {
+		name = "F2"
+	}
+	fmt.Fprintf(p.page, "%.3f g BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET 0 g\n",
+		font.Gray, name, font.Size, x, PDFPageHeight-y, pdfString(s))
+}
+
+// TextRight draws s so that it ends at x
+func (p *PDF) TextRight(x, y float64, s string, font PDFFont) {
+	p.Text(x-font.Width(s), y, s, font)
+}
+
+// Line draws a straight line of the given width
+func (p *PDF) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(p.page, "%.2f w %.2f %.2f m %.2f %.2f l S\n",
+		width, x1, PDFPageHeight-y1, x2, PDFPageHeight-y2)
+}
+
+// FillRect fills a box whose top-left corner is (x, y) with a shade of gray
+func (p *PDF) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(p.page, "%.3f g %.2f %.2f %.2f %.2f re f 0 g\n",
+		gray, x, PDFPageHeight-y-h, w, h)
+}
+
+// Bytes assembles the pages into a complete PDF file
+func (p *PDF) Bytes() []byte {
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4: catalog, page tree and the two fonts; then a page and its
+	// content stream for every page
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PDFPageWidth, PDFPageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// Width returns the rendered width of s in points
+func (f PDFFont) Width(s string) float64 {
+	widths := &helveticaWidths
+	if f.Bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range winAnsi(s) {
+		if b >= 32 && b < 127 {
+			total += widths[b-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * f.Size / 1000
+}
+
+// Fit shortens s with an ellipsis so that it is at most maxWidth wide
+func (f PDFFont) Fit(s string, maxWidth float64) string {
+	if f.Width(s) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := strings.TrimSpace(string(runes)) + "..."; f.Width(candidate) <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Wrap breaks s into lines at most maxWidth wide, splitting between words.
+// Words wider than a line are shortened with Fit.
+func (f PDFFont) Wrap(s string, maxWidth float64) []string {
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if f.Width(candidate) <= maxWidth {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		current = f.Fit(word, maxWidth)
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// winAnsi converts s to the WinAnsi encoding used by the standard fonts.
+// Characters outside it become "?".
+func winAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 32 && r < 127, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case r == '\n', r == '\t':
+			out = append(out, ' ')
+		case r == '–':
+			out = append(out, 0x96)
+		case r == '—':
+			out = append(out, 0x97)
+		case r == '•':
+			out = append(out, 0x95)
+		case r == '‘':
+			out = append(out, 0x91)
+		case r == '’':
+			out = append(out, 0x92)
+		case r == '“':
+			out = append(out, 0x93)
+		case r == '”':
+			out = append(out, 0x94)
+		case r == '€':
+			out = append(out, 0x80)
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// pdfString encodes s as the body of a PDF literal string
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, c := range winAnsi(s) {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 127:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Advance widths of the printable ASCII characters (32-126) in 1/1000 em, from
+// the Adobe font metrics of the standard fonts
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
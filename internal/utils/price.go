@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatRupiah formats an amount as Rupiah without decimals
+// Example: 1500000 → "Rp 1.500.000"
+func FormatRupiah(amount float64) string {
+	return "Rp " + FormatNumber(int64(amount))
+}
+
+// FormatNumber formats a number with dot as thousands separator (Indonesian Rupiah style: 9.000, 1.500.000)
+func FormatNumber(n int64) string {
+	if n < 0 {
+		return "-" + FormatNumber(-n)
+	}
+	s := fmt.Sprintf("%d", n)
+	if len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}